@@ -0,0 +1,620 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+// ==========================================
+// API REST SOBRE net/http
+// ==========================================
+
+// ServidorAPI expone una Biblioteca como API REST con cuerpos JSON
+type ServidorAPI struct {
+	biblioteca *Biblioteca
+	mux        *http.ServeMux
+}
+
+// NuevoServidorAPI es el constructor del servidor HTTP
+func NuevoServidorAPI(biblioteca *Biblioteca) *ServidorAPI {
+	s := &ServidorAPI{
+		biblioteca: biblioteca,
+		mux:        http.NewServeMux(),
+	}
+
+	s.mux.HandleFunc("GET /libros", s.listarLibros)
+	s.mux.HandleFunc("POST /libros", s.crearLibro)
+	s.mux.HandleFunc("POST /libros/importacion", s.importarLibros)
+	s.mux.HandleFunc("GET /libros/exportacion", s.exportarCatalogo)
+	s.mux.HandleFunc("GET /libros/{id}/exportacion", s.exportarLibro)
+	s.mux.HandleFunc("GET /libros/{id}", s.obtenerLibro)
+	s.mux.HandleFunc("PUT /libros/{id}", s.actualizarLibro)
+	s.mux.HandleFunc("POST /libros/{id}/ejemplares", s.crearEjemplar)
+	s.mux.HandleFunc("GET /usuarios", s.listarUsuarios)
+	s.mux.HandleFunc("POST /usuarios", s.crearUsuario)
+	s.mux.HandleFunc("GET /usuarios/{id}", s.obtenerUsuario)
+	s.mux.HandleFunc("GET /usuarios/{id}/historial", s.historialUsuario)
+	s.mux.HandleFunc("PUT /usuarios/{id}/categoria", s.cambiarCategoria)
+	s.mux.HandleFunc("PUT /usuarios/{id}/idioma", s.cambiarIdioma)
+	s.mux.HandleFunc("GET /libros/{id}/historial", s.historialLibro)
+	s.mux.HandleFunc("GET /prestamos", s.listarPrestamos)
+	s.mux.HandleFunc("POST /prestamos", s.crearPrestamo)
+	s.mux.HandleFunc("GET /prestamos/vencidos", s.listarPrestamosVencidos)
+	s.mux.HandleFunc("POST /prestamos/{id}/renovacion", s.renovarPrestamo)
+	s.mux.HandleFunc("POST /prestamos/{id}/pago", s.pagarMulta)
+	s.mux.HandleFunc("POST /devoluciones", s.crearDevolucion)
+	s.mux.HandleFunc("GET /libros/{id}/reservas", s.listarReservas)
+	s.mux.HandleFunc("POST /reservas", s.crearReserva)
+	s.mux.HandleFunc("DELETE /reservas/{id}", s.cancelarReserva)
+	s.mux.HandleFunc("GET /calendario", s.obtenerCalendario)
+	s.mux.HandleFunc("GET /estadisticas", s.obtenerEstadisticas)
+	s.mux.HandleFunc("GET /consistencia", s.verificarConsistencia)
+	s.mux.HandleFunc("POST /consistencia/reparacion", s.repararConsistencia)
+	return s
+}
+
+// ServeHTTP implementa http.Handler
+func (s *ServidorAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// solicitudLibro es el cuerpo de POST /libros
+type solicitudLibro struct {
+	Titulo  string
+	Autor   string
+	ISBN    string
+	Paginas int
+}
+
+// solicitudUsuario es el cuerpo de POST /usuarios
+type solicitudUsuario struct {
+	Nombre    string
+	Email     string
+	Telefono  string
+	Categoria CategoriaUsuario
+}
+
+// solicitudCategoria es el cuerpo de PUT /usuarios/{id}/categoria
+type solicitudCategoria struct {
+	Categoria CategoriaUsuario
+}
+
+// solicitudIdioma es el cuerpo de PUT /usuarios/{id}/idioma
+type solicitudIdioma struct {
+	Idioma Idioma
+}
+
+// solicitudEjemplar es el cuerpo de POST /libros/{id}/ejemplares
+type solicitudEjemplar struct {
+	CodigoBarras string
+}
+
+// solicitudPrestamo es el cuerpo de POST /prestamos
+// Con EjemplarID se presta esa copia, si no cualquiera disponible del libro
+type solicitudPrestamo struct {
+	LibroID    LibroID
+	EjemplarID EjemplarID
+	UsuarioID  UsuarioID
+}
+
+// solicitudReserva es el cuerpo de POST /reservas
+type solicitudReserva struct {
+	LibroID   LibroID
+	UsuarioID UsuarioID
+}
+
+// solicitudDevolucion es el cuerpo de POST /devoluciones
+// Con EjemplarID se devuelve esa copia, si no el único préstamo activo del libro
+type solicitudDevolucion struct {
+	LibroID    LibroID
+	EjemplarID EjemplarID
+}
+
+// respuestaError es el cuerpo de toda respuesta con error
+// Codigo identifica el tipo de error (libro_no_encontrado, isbn_duplicado...)
+type respuestaError struct {
+	Error  string
+	Codigo string `json:",omitempty"`
+}
+
+// listarLibros retorna el catálogo, o los resultados de búsqueda si viene ?q=
+// Con ?filtro= aplica una consulta estructurada (autor:"Márquez" paginas>300)
+func (s *ServidorAPI) listarLibros(w http.ResponseWriter, r *http.Request) {
+	if filtro := r.URL.Query().Get("filtro"); filtro != "" {
+		libros, err := s.biblioteca.ConsultarLibros(filtro)
+		if err != nil {
+			responderError(w, r, err)
+			return
+		}
+		responderJSON(w, http.StatusOK, libros)
+		return
+	}
+	if consulta := r.URL.Query().Get("q"); consulta != "" {
+		responderJSON(w, http.StatusOK, s.biblioteca.BuscarLibros(consulta))
+		return
+	}
+	responderJSON(w, http.StatusOK, s.biblioteca.ListarLibros())
+}
+
+func (s *ServidorAPI) crearLibro(w http.ResponseWriter, r *http.Request) {
+	var sol solicitudLibro
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	libro, err := s.biblioteca.AgregarLibro(sol.Titulo, sol.Autor, sol.ISBN, sol.Paginas)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusCreated, libro)
+}
+
+// importarLibros recibe el archivo en el cuerpo; ?formato= elige csv (por
+// defecto), marc, marcxml o dc, y ?prueba=true solo valida
+// Responde 200 con el reporte aunque algunas filas fallen
+func (s *ServidorAPI) importarLibros(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opciones, err := nuevasOpcionesImportacion(q.Get("separador"), q["columna"], q.Get("prueba") == "true")
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	var reporte *ReporteImportacion
+	if formato := q.Get("formato"); formato == "" || formato == "csv" {
+		reporte, err = s.biblioteca.ImportarCSV(r.Body, opciones)
+	} else {
+		reporte, err = s.biblioteca.ImportarRegistros(r.Body, FormatoRegistro(formato), opciones.Prueba)
+	}
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	reporte.Traducir(idiomaDe(r))
+	responderJSON(w, http.StatusOK, reporte)
+}
+
+// exportarCatalogo responde todo el catálogo en ?formato=marc|marcxml|dc
+// Se arma completo antes de responder, así un error todavía puede
+// informarse con su código HTTP en lugar de cortar el cuerpo a la mitad
+func (s *ServidorAPI) exportarCatalogo(w http.ResponseWriter, r *http.Request) {
+	formato := FormatoRegistro(r.URL.Query().Get("formato"))
+	var cuerpo bytes.Buffer
+	if err := s.biblioteca.ExportarCatalogo(&cuerpo, formato); err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderRegistros(w, formato, cuerpo.Bytes())
+}
+
+func (s *ServidorAPI) exportarLibro(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[LibroID](w, r)
+	if !ok {
+		return
+	}
+	formato := FormatoRegistro(r.URL.Query().Get("formato"))
+	var cuerpo bytes.Buffer
+	if err := s.biblioteca.ExportarLibro(&cuerpo, id, formato); err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderRegistros(w, formato, cuerpo.Bytes())
+}
+
+// responderRegistros envía registros ya exportados con el tipo de su formato
+// Si falla la escritura el cliente ya se fue: solo queda registrarlo
+func responderRegistros(w http.ResponseWriter, formato FormatoRegistro, cuerpo []byte) {
+	w.Header().Set("Content-Type", formato.TipoContenido())
+	if _, err := w.Write(cuerpo); err != nil {
+		log.Print(errArchivo(err, "error.escribir_registros"))
+	}
+}
+
+func (s *ServidorAPI) obtenerLibro(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[LibroID](w, r)
+	if !ok {
+		return
+	}
+	libro := s.biblioteca.BuscarLibro(id)
+	if libro == nil {
+		responderError(w, r, errLibroNoEncontrado(id))
+		return
+	}
+	responderJSON(w, http.StatusOK, libro)
+}
+
+func (s *ServidorAPI) actualizarLibro(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[LibroID](w, r)
+	if !ok {
+		return
+	}
+	var sol solicitudLibro
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	libro, err := s.biblioteca.ActualizarLibro(id, sol.Titulo, sol.Autor, sol.Paginas)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, libro)
+}
+
+func (s *ServidorAPI) crearEjemplar(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[LibroID](w, r)
+	if !ok {
+		return
+	}
+	var sol solicitudEjemplar
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	ejemplar, err := s.biblioteca.AgregarEjemplar(id, sol.CodigoBarras)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusCreated, ejemplar)
+}
+
+func (s *ServidorAPI) listarUsuarios(w http.ResponseWriter, r *http.Request) {
+	if filtro := r.URL.Query().Get("filtro"); filtro != "" {
+		usuarios, err := s.biblioteca.ConsultarUsuarios(filtro)
+		if err != nil {
+			responderError(w, r, err)
+			return
+		}
+		responderJSON(w, http.StatusOK, usuarios)
+		return
+	}
+	responderJSON(w, http.StatusOK, s.biblioteca.ListarUsuarios())
+}
+
+func (s *ServidorAPI) crearUsuario(w http.ResponseWriter, r *http.Request) {
+	var sol solicitudUsuario
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	usuario, err := s.biblioteca.RegistrarUsuario(sol.Nombre, sol.Email, sol.Telefono, sol.Categoria)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusCreated, usuario)
+}
+
+func (s *ServidorAPI) obtenerUsuario(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[UsuarioID](w, r)
+	if !ok {
+		return
+	}
+	usuario := s.biblioteca.BuscarUsuario(id)
+	if usuario == nil {
+		responderError(w, r, errUsuarioNoEncontrado(id))
+		return
+	}
+	responderJSON(w, http.StatusOK, usuario)
+}
+
+func (s *ServidorAPI) cambiarCategoria(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[UsuarioID](w, r)
+	if !ok {
+		return
+	}
+	var sol solicitudCategoria
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	usuario, err := s.biblioteca.CambiarCategoria(id, sol.Categoria)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, usuario)
+}
+
+func (s *ServidorAPI) cambiarIdioma(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[UsuarioID](w, r)
+	if !ok {
+		return
+	}
+	var sol solicitudIdioma
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	usuario, err := s.biblioteca.CambiarIdioma(id, sol.Idioma)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, usuario)
+}
+
+// historialUsuario acepta ?desde=AAAA-MM-DD&hasta=AAAA-MM-DD&pagina=N&por_pagina=N
+func (s *ServidorAPI) historialUsuario(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[UsuarioID](w, r)
+	if !ok {
+		return
+	}
+	filtro, ok := leerFiltroHistorial(w, r)
+	if !ok {
+		return
+	}
+	historial, err := s.biblioteca.HistorialUsuario(id, filtro)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, historial)
+}
+
+// historialLibro acepta los mismos parámetros que historialUsuario
+func (s *ServidorAPI) historialLibro(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[LibroID](w, r)
+	if !ok {
+		return
+	}
+	filtro, ok := leerFiltroHistorial(w, r)
+	if !ok {
+		return
+	}
+	historial, err := s.biblioteca.HistorialLibro(id, filtro)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, historial)
+}
+
+func (s *ServidorAPI) listarPrestamos(w http.ResponseWriter, r *http.Request) {
+	if filtro := r.URL.Query().Get("filtro"); filtro != "" {
+		prestamos, err := s.biblioteca.ConsultarPrestamos(filtro, s.biblioteca.Ahora())
+		if err != nil {
+			responderError(w, r, err)
+			return
+		}
+		responderJSON(w, http.StatusOK, prestamos)
+		return
+	}
+	responderJSON(w, http.StatusOK, s.biblioteca.ListarPrestamos())
+}
+
+func (s *ServidorAPI) listarPrestamosVencidos(w http.ResponseWriter, r *http.Request) {
+	responderJSON(w, http.StatusOK, s.biblioteca.PrestamosVencidos(s.biblioteca.Ahora()))
+}
+
+func (s *ServidorAPI) crearPrestamo(w http.ResponseWriter, r *http.Request) {
+	var sol solicitudPrestamo
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	var prestamo *Prestamo
+	var err error
+	if sol.EjemplarID != 0 {
+		prestamo, err = s.biblioteca.PrestarEjemplar(sol.EjemplarID, sol.UsuarioID)
+	} else {
+		prestamo, err = s.biblioteca.PrestarLibro(sol.LibroID, sol.UsuarioID)
+	}
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusCreated, prestamo)
+}
+
+func (s *ServidorAPI) renovarPrestamo(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[PrestamoID](w, r)
+	if !ok {
+		return
+	}
+	prestamo, err := s.biblioteca.RenovarPrestamo(id)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, prestamo)
+}
+
+func (s *ServidorAPI) pagarMulta(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[PrestamoID](w, r)
+	if !ok {
+		return
+	}
+	prestamo, err := s.biblioteca.PagarMulta(id)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, prestamo)
+}
+
+func (s *ServidorAPI) crearDevolucion(w http.ResponseWriter, r *http.Request) {
+	var sol solicitudDevolucion
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	var prestamo *Prestamo
+	var err error
+	if sol.EjemplarID != 0 {
+		prestamo, err = s.biblioteca.DevolverEjemplar(sol.EjemplarID)
+	} else {
+		prestamo, err = s.biblioteca.DevolverLibro(sol.LibroID)
+	}
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusOK, prestamo)
+}
+
+func (s *ServidorAPI) listarReservas(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[LibroID](w, r)
+	if !ok {
+		return
+	}
+	if s.biblioteca.BuscarLibro(id) == nil {
+		responderError(w, r, errLibroNoEncontrado(id))
+		return
+	}
+	responderJSON(w, http.StatusOK, s.biblioteca.ColaReservas(id))
+}
+
+func (s *ServidorAPI) crearReserva(w http.ResponseWriter, r *http.Request) {
+	var sol solicitudReserva
+	if !leerJSON(w, r, &sol) {
+		return
+	}
+	reserva, err := s.biblioteca.ReservarLibro(sol.LibroID, sol.UsuarioID)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	responderJSON(w, http.StatusCreated, reserva)
+}
+
+func (s *ServidorAPI) cancelarReserva(w http.ResponseWriter, r *http.Request) {
+	id, ok := leerID[ReservaID](w, r)
+	if !ok {
+		return
+	}
+	if err := s.biblioteca.CancelarReserva(id); err != nil {
+		responderError(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *ServidorAPI) obtenerCalendario(w http.ResponseWriter, r *http.Request) {
+	responderJSON(w, http.StatusOK, s.biblioteca.VerCalendario())
+}
+
+func (s *ServidorAPI) obtenerEstadisticas(w http.ResponseWriter, r *http.Request) {
+	estadisticas := s.biblioteca.ObtenerEstadisticas()
+	if r.URL.Query().Get("formato") == "texto" {
+		idioma := idiomaDe(r)
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Language", string(idioma))
+		fmt.Fprint(w, estadisticas.TextoEn(idioma))
+		return
+	}
+	responderJSON(w, http.StatusOK, estadisticas)
+}
+
+func (s *ServidorAPI) verificarConsistencia(w http.ResponseWriter, r *http.Request) {
+	reporte, err := s.biblioteca.VerificarConsistencia(false)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	reporte.Traducir(idiomaDe(r))
+	responderJSON(w, http.StatusOK, reporte)
+}
+
+func (s *ServidorAPI) repararConsistencia(w http.ResponseWriter, r *http.Request) {
+	reporte, err := s.biblioteca.VerificarConsistencia(true)
+	if err != nil {
+		responderError(w, r, err)
+		return
+	}
+	reporte.Traducir(idiomaDe(r))
+	responderJSON(w, http.StatusOK, reporte)
+}
+
+// leerJSON decodifica el cuerpo de la petición y responde 400 si no es válido
+func leerJSON(w http.ResponseWriter, r *http.Request, destino any) bool {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(destino); err != nil {
+		responderError(w, r, &ErrorBiblioteca{Tipo: ErrDatosInvalidos, mensaje: txt("error.cuerpo_json"), Causa: err})
+		return false
+	}
+	return true
+}
+
+// leerID obtiene el {id} de la ruta y responde 400 si no es un número
+func leerID[T ~int](w http.ResponseWriter, r *http.Request) (T, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		responderError(w, r, errDatosInvalidos("error.id_invalido", r.PathValue("id")))
+		return 0, false
+	}
+	return T(id), true
+}
+
+// leerFiltroHistorial obtiene el rango de fechas y la página de la query y
+// responde 400 si no son válidos
+func leerFiltroHistorial(w http.ResponseWriter, r *http.Request) (FiltroHistorial, bool) {
+	q := r.URL.Query()
+	numeros := make(map[string]int)
+	for _, nombre := range []string{"pagina", "por_pagina"} {
+		if q.Get(nombre) == "" {
+			continue
+		}
+		n, err := strconv.Atoi(q.Get(nombre))
+		if err != nil {
+			responderError(w, r, errDatosInvalidos("error.valor_invalido", nombre, q.Get(nombre)))
+			return FiltroHistorial{}, false
+		}
+		numeros[nombre] = n
+	}
+
+	filtro, err := nuevoFiltroHistorial(q.Get("desde"), q.Get("hasta"), numeros["pagina"], numeros["por_pagina"])
+	if err != nil {
+		responderError(w, r, err)
+		return FiltroHistorial{}, false
+	}
+	return filtro, true
+}
+
+func responderJSON(w http.ResponseWriter, estado int, cuerpo any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(estado)
+	json.NewEncoder(w).Encode(cuerpo)
+}
+
+// responderError elige el código HTTP según la clase del error y da el
+// mensaje en el idioma de la petición
+func responderError(w http.ResponseWriter, r *http.Request, err error) {
+	idioma := idiomaDe(r)
+	w.Header().Set("Content-Language", string(idioma))
+	responderJSON(w, estadoHTTP(err), nuevaRespuestaError(err, idioma))
+}
+
+// idiomaDe elige el idioma de la respuesta: ?idioma= si es uno disponible,
+// si no el preferido en Accept-Language
+func idiomaDe(r *http.Request) Idioma {
+	if idioma, err := ParsearIdioma(r.URL.Query().Get("idioma")); err == nil {
+		return idioma
+	}
+	return IdiomaDeAcceptLanguage(r.Header.Get("Accept-Language"))
+}
+
+// estadoHTTP traduce la clase de un error de la biblioteca a un código HTTP
+// Lo que no es de la biblioteca (o falló el journal) es un error interno
+func estadoHTTP(err error) int {
+	switch {
+	case errors.Is(err, ErrNoEncontrado):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflicto):
+		return http.StatusConflict
+	case errors.Is(err, ErrDatosInvalidos):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// nuevaRespuestaError arma el cuerpo de error con el código estable, si lo hay
+func nuevaRespuestaError(err error, idioma Idioma) respuestaError {
+	respuesta := respuestaError{Error: MensajeDeError(err, idioma)}
+	var errorBiblioteca *ErrorBiblioteca
+	if errors.As(err, &errorBiblioteca) {
+		respuesta.Codigo = errorBiblioteca.Codigo()
+	} else if errors.Is(err, ErrDatosInvalidos) {
+		respuesta.Codigo = ErrDatosInvalidos.Error()
+	}
+	return respuesta
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// pedir hace una petición al servidor y retorna la respuesta grabada
+func pedir(api *ServidorAPI, metodo, ruta, cuerpo string, encabezados ...string) *httptest.ResponseRecorder {
+	peticion := httptest.NewRequest(metodo, ruta, strings.NewReader(cuerpo))
+	for i := 0; i+1 < len(encabezados); i += 2 {
+		peticion.Header.Set(encabezados[i], encabezados[i+1])
+	}
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, peticion)
+	return rec
+}
+
+func TestAPIFlujoDePrestamo(t *testing.T) {
+	api := NuevoServidorAPI(NuevaBiblioteca("Central", ""))
+
+	rec := pedir(api, http.MethodPost, "/libros", `{"Titulo":"Rayuela","Autor":"Cortázar","Paginas":600}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /libros: estado %d: %s", rec.Code, rec.Body)
+	}
+	var libro Libro
+	if err := json.Unmarshal(rec.Body.Bytes(), &libro); err != nil || libro.ID == 0 || libro.Titulo != "Rayuela" {
+		t.Fatalf("Cuerpo inesperado %s: %v", rec.Body, err)
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type %q, se esperaba JSON", ct)
+	}
+
+	rec = pedir(api, http.MethodPost, "/usuarios", `{"Nombre":"Ana","Email":"ana@test"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /usuarios: estado %d: %s", rec.Code, rec.Body)
+	}
+	var usuario Usuario
+	json.Unmarshal(rec.Body.Bytes(), &usuario)
+
+	rec = pedir(api, http.MethodPost, "/prestamos", `{"LibroID":1,"UsuarioID":1}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST /prestamos: estado %d: %s", rec.Code, rec.Body)
+	}
+	var prestamo Prestamo
+	if err := json.Unmarshal(rec.Body.Bytes(), &prestamo); err != nil || prestamo.LibroID != libro.ID || prestamo.UsuarioID != usuario.ID {
+		t.Fatalf("Préstamo inesperado %s: %v", rec.Body, err)
+	}
+
+	rec = pedir(api, http.MethodGet, "/prestamos", "")
+	var prestamos []Prestamo
+	if err := json.Unmarshal(rec.Body.Bytes(), &prestamos); err != nil || len(prestamos) != 1 {
+		t.Errorf("GET /prestamos: %s", rec.Body)
+	}
+
+	rec = pedir(api, http.MethodPost, "/devoluciones", `{"LibroID":1}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("POST /devoluciones: estado %d: %s", rec.Code, rec.Body)
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &prestamo); err != nil || !prestamo.Devuelto {
+		t.Errorf("La devolución debería marcar el préstamo: %s", rec.Body)
+	}
+}
+
+func TestAPIEstadosPorClaseDeError(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	libro, _ := b.AgregarLibro("Rayuela", "Cortázar", "9788437604572", 600)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+	api := NuevoServidorAPI(b)
+
+	casos := []struct {
+		metodo, ruta, cuerpo string
+		estado               int
+		codigo               string
+	}{
+		{http.MethodGet, "/libros/99", "", http.StatusNotFound, "libro_no_encontrado"},
+		{http.MethodGet, "/usuarios/99", "", http.StatusNotFound, "usuario_no_encontrado"},
+		{http.MethodDelete, "/reservas/99", "", http.StatusNotFound, "reserva_no_encontrada"},
+		{http.MethodPost, "/prestamos", `{"LibroID":1,"UsuarioID":2}`, http.StatusConflict, "libro_ya_prestado"},
+		{http.MethodPost, "/libros", `{"Titulo":"Otra","Autor":"X","ISBN":"9788437604572","Paginas":10}`, http.StatusConflict, "isbn_duplicado"},
+		{http.MethodPost, "/usuarios", `{"Nombre":"Ana","Email":"ana@test"}`, http.StatusConflict, "email_duplicado"},
+		{http.MethodPost, "/libros", `{"Titulo":"","Autor":"X"}`, http.StatusBadRequest, "datos_invalidos"},
+		{http.MethodPost, "/libros", `{"Titulo":`, http.StatusBadRequest, "datos_invalidos"},
+		{http.MethodPost, "/libros", `{"Nombre":"campo desconocido"}`, http.StatusBadRequest, "datos_invalidos"},
+		{http.MethodGet, "/libros/abc", "", http.StatusBadRequest, "datos_invalidos"},
+		{http.MethodGet, "/libros?filtro=paginas>>3", "", http.StatusBadRequest, "datos_invalidos"},
+	}
+	for _, c := range casos {
+		rec := pedir(api, c.metodo, c.ruta, c.cuerpo)
+		if rec.Code != c.estado {
+			t.Errorf("%s %s: estado %d, se esperaba %d: %s", c.metodo, c.ruta, rec.Code, c.estado, rec.Body)
+			continue
+		}
+		var respuesta respuestaError
+		if err := json.Unmarshal(rec.Body.Bytes(), &respuesta); err != nil || respuesta.Error == "" || respuesta.Codigo != c.codigo {
+			t.Errorf("%s %s: cuerpo de error inesperado %s (se esperaba el código %s)", c.metodo, c.ruta, rec.Body, c.codigo)
+		}
+	}
+
+	// una reserva de quien no tiene el libro sí se acepta
+	if rec := pedir(api, http.MethodPost, "/reservas", `{"LibroID":1,"UsuarioID":2}`); rec.Code != http.StatusCreated {
+		t.Errorf("POST /reservas de %d: estado %d: %s", beto.ID, rec.Code, rec.Body)
+	}
+}
+
+func TestAPIRutasYMetodos(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+	api := NuevoServidorAPI(b)
+
+	casos := []struct {
+		metodo, ruta string
+		estado       int
+	}{
+		{http.MethodGet, "/libros", http.StatusOK},
+		{http.MethodGet, "/libros/1", http.StatusOK},
+		{http.MethodGet, "/libros?q=rayuela", http.StatusOK},
+		{http.MethodGet, "/usuarios", http.StatusOK},
+		{http.MethodGet, "/prestamos/vencidos", http.StatusOK},
+		{http.MethodGet, "/libros/1/reservas", http.StatusOK},
+		{http.MethodGet, "/calendario", http.StatusOK},
+		{http.MethodGet, "/estadisticas", http.StatusOK},
+		{http.MethodGet, "/consistencia", http.StatusOK},
+		{http.MethodGet, "/inexistente", http.StatusNotFound},
+		{http.MethodDelete, "/libros/1", http.StatusMethodNotAllowed},
+		{http.MethodPut, "/prestamos", http.StatusMethodNotAllowed},
+	}
+	for _, c := range casos {
+		if rec := pedir(api, c.metodo, c.ruta, ""); rec.Code != c.estado {
+			t.Errorf("%s %s: estado %d, se esperaba %d", c.metodo, c.ruta, rec.Code, c.estado)
+		}
+	}
+
+	rec := pedir(api, http.MethodGet, "/libros?q=rayuela", "")
+	var resultados []ResultadoBusqueda
+	if err := json.Unmarshal(rec.Body.Bytes(), &resultados); err != nil || len(resultados) != 1 {
+		t.Errorf("La búsqueda debería encontrar Rayuela: %s", rec.Body)
+	}
+}
+
+func TestAPIRespondeEnElIdiomaPedido(t *testing.T) {
+	api := NuevoServidorAPI(NuevaBiblioteca("Central", ""))
+
+	casos := []struct {
+		encabezado string
+		consulta   string
+		idioma     string
+		texto      string
+	}{
+		{"", "", "es", "No existe un libro"},
+		{"en-US,en;q=0.9", "", "en", "There is no book"},
+		{"fr-FR, pt-BR;q=0.8, en;q=0.5", "", "pt", "Não existe um livro"},
+		{"en", "?idioma=es", "es", "No existe un libro"},
+		{"de", "", "es", "No existe un libro"},
+	}
+	for _, c := range casos {
+		var encabezados []string
+		if c.encabezado != "" {
+			encabezados = []string{"Accept-Language", c.encabezado}
+		}
+		rec := pedir(api, http.MethodGet, "/libros/7"+c.consulta, "", encabezados...)
+		if rec.Code != http.StatusNotFound {
+			t.Fatalf("%q: estado %d", c.encabezado, rec.Code)
+		}
+		if idioma := rec.Header().Get("Content-Language"); idioma != c.idioma {
+			t.Errorf("%q%s: Content-Language %q, se esperaba %q", c.encabezado, c.consulta, idioma, c.idioma)
+		}
+		var respuesta respuestaError
+		json.Unmarshal(rec.Body.Bytes(), &respuesta)
+		if !strings.Contains(respuesta.Error, c.texto) {
+			t.Errorf("%q%s: mensaje %q, se esperaba %q", c.encabezado, c.consulta, respuesta.Error, c.texto)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// bloquearArchivo intenta tomar un bloqueo exclusivo (flock) sobre el archivo
+// sin esperar. Retorna false si lo tiene otro proceso; se suelta al cerrarlo
+func bloquearArchivo(archivo *os.File) (bool, error) {
+	err := syscall.Flock(int(archivo.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if errors.Is(err, syscall.EWOULDBLOCK) {
+		return false, nil
+	}
+	return err == nil, err
+}
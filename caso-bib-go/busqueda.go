@@ -0,0 +1,269 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// ==========================================
+// BÚSQUEDA DE TEXTO CON ÍNDICE INVERTIDO
+// ==========================================
+
+// Pesos de cada campo al calcular la relevancia
+const (
+	pesoTitulo = 3.0
+	pesoAutor  = 2.0
+	pesoISBN   = 5.0
+)
+
+// ResultadoBusqueda es un libro encontrado con su puntaje de relevancia
+type ResultadoBusqueda struct {
+	Libro   Libro
+	Puntaje float64
+}
+
+// indiceTexto es un índice invertido: término → libro → peso acumulado
+// Guarda también los términos de cada libro para poder reindexarlo
+type indiceTexto struct {
+	postings map[string]map[LibroID]float64
+	terminos map[LibroID][]string
+}
+
+func nuevoIndiceTexto() *indiceTexto {
+	return &indiceTexto{
+		postings: make(map[string]map[LibroID]float64),
+		terminos: make(map[LibroID][]string),
+	}
+}
+
+// indexar agrega (o reemplaza) las entradas de un libro en el índice
+func (ix *indiceTexto) indexar(libro Libro) {
+	ix.desindexar(libro.ID)
+
+	pesos := make(map[string]float64)
+	for _, t := range tokenizar(libro.Titulo) {
+		pesos[t] += pesoTitulo
+	}
+	for _, t := range tokenizar(libro.Autor) {
+		pesos[t] += pesoAutor
+	}
+	for _, t := range terminosISBN(libro.ISBN) {
+		pesos[t] += pesoISBN
+	}
+
+	terminos := make([]string, 0, len(pesos))
+	for termino, peso := range pesos {
+		if ix.postings[termino] == nil {
+			ix.postings[termino] = make(map[LibroID]float64)
+		}
+		ix.postings[termino][libro.ID] = peso
+		terminos = append(terminos, termino)
+	}
+	ix.terminos[libro.ID] = terminos
+}
+
+// desindexar quita todas las entradas de un libro
+func (ix *indiceTexto) desindexar(libroID LibroID) {
+	for _, termino := range ix.terminos[libroID] {
+		delete(ix.postings[termino], libroID)
+		if len(ix.postings[termino]) == 0 {
+			delete(ix.postings, termino)
+		}
+	}
+	delete(ix.terminos, libroID)
+}
+
+// buscar retorna los IDs de libros que contienen todos los términos de la
+// consulta, con su puntaje (peso del campo × rareza del término)
+func (ix *indiceTexto) buscar(consulta string) map[LibroID]float64 {
+	terminos := terminosConsulta(consulta)
+	if len(terminos) == 0 {
+		return nil
+	}
+
+	// Empezar por el término menos frecuente reduce los candidatos
+	sort.Slice(terminos, func(i, j int) bool {
+		return len(ix.postings[terminos[i]]) < len(ix.postings[terminos[j]])
+	})
+
+	total := float64(len(ix.terminos))
+	var puntajes map[LibroID]float64
+	for _, termino := range terminos {
+		posting := ix.postings[termino]
+		if len(posting) == 0 {
+			return nil
+		}
+		idf := math.Log(1 + total/float64(len(posting)))
+
+		if puntajes == nil {
+			puntajes = make(map[LibroID]float64, len(posting))
+			for id, peso := range posting {
+				puntajes[id] = peso * idf
+			}
+			continue
+		}
+		for id := range puntajes {
+			peso, ok := posting[id]
+			if !ok {
+				delete(puntajes, id)
+				continue
+			}
+			puntajes[id] += peso * idf
+		}
+	}
+	return puntajes
+}
+
+// BuscarLibros busca en título, autor e ISBN sin distinguir mayúsculas ni
+// acentos ("garcia marquez" encuentra "García Márquez")
+// Los resultados vienen ordenados del más al menos relevante
+func (b *Biblioteca) BuscarLibros(consulta string) []ResultadoBusqueda {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	puntajes := b.indice.buscar(consulta)
+	resultados := make([]ResultadoBusqueda, 0, len(puntajes))
+	for id, puntaje := range puntajes {
+		if libro := b.buscarLibro(id); libro != nil {
+			resultados = append(resultados, ResultadoBusqueda{Libro: libro.copiar(), Puntaje: puntaje})
+		}
+	}
+	sort.Slice(resultados, func(i, j int) bool {
+		if resultados[i].Puntaje != resultados[j].Puntaje {
+			return resultados[i].Puntaje > resultados[j].Puntaje
+		}
+		return resultados[i].Libro.ID < resultados[j].Libro.ID
+	})
+	return resultados
+}
+
+// ActualizarLibro cambia título, autor y páginas de un libro y lo reindexa
+// Usa receptor de PUNTERO porque modifica el libro y el índice
+func (b *Biblioteca) ActualizarLibro(id LibroID, titulo, autor string, paginas int) (*Libro, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	libro := b.buscarLibro(id)
+	if libro == nil {
+		return nil, errLibroNoEncontrado(id)
+	}
+	// Los cambios se aplican a una copia y pasan al libro solo si el evento
+	// quedó registrado
+	actualizado := libro.copiar()
+	if err := actualizado.ActualizarInfo(titulo, autor, paginas); err != nil {
+		return nil, err
+	}
+	if err := b.escribirEvento(Evento{Tipo: EventoLibroActualizado, Libro: &actualizado}); err != nil {
+		return nil, errJournal(err, "error.cambio_no_registrado")
+	}
+	*libro = actualizado
+	b.indice.indexar(*libro)
+
+	copia := libro.copiar()
+	return &copia, nil
+}
+
+// reconstruirIndices vuelve a generar los índices a partir de los slices
+// Se usa después de cargar un snapshot
+func (b *Biblioteca) reconstruirIndices() {
+	b.indice = nuevoIndiceTexto()
+	b.indices = nuevosIndices()
+	for i, libro := range b.Libros {
+		b.indice.indexar(libro)
+		b.indexarLibro(i)
+	}
+	for i := range b.Usuarios {
+		b.indexarUsuario(i)
+	}
+	for i := range b.Prestamos {
+		b.indexarPrestamo(i)
+	}
+	for i := range b.Reservas {
+		b.indexarReserva(i)
+	}
+}
+
+// normalizarTexto pasa a minúsculas y quita acentos y diéresis
+func normalizarTexto(texto string) string {
+	var sb strings.Builder
+	for _, r := range strings.ToLower(texto) {
+		if base, ok := sinAcento[r]; ok {
+			r = base
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// tokenizar separa un texto normalizado en palabras
+func tokenizar(texto string) []string {
+	return strings.FieldsFunc(normalizarTexto(texto), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// terminosISBN indexa el ISBN en su forma canónica y, si existe, en ISBN-10
+// Los datos anteriores a la validación pueden traer un ISBN-10 o con guiones:
+// se canonizan igual que la consulta. Uno inválido se indexa tal como está
+func terminosISBN(isbn string) []string {
+	if isbn == "" {
+		return nil
+	}
+	canonico, err := CanonizarISBN(isbn)
+	if err != nil {
+		return []string{strings.ToLower(NormalizarISBN(isbn))}
+	}
+	terminos := []string{canonico}
+	if isbn10, err := ISBN13a10(canonico); err == nil {
+		terminos = append(terminos, strings.ToLower(isbn10))
+	}
+	return terminos
+}
+
+// terminosConsulta convierte la consulta en términos del índice
+// Una consulta que es un ISBN se busca como un solo término, normalizado
+// igual que en terminosISBN
+func terminosConsulta(consulta string) []string {
+	if canonico, err := CanonizarISBN(strings.TrimSpace(consulta)); err == nil {
+		return []string{canonico}
+	}
+	if normal := NormalizarISBN(strings.TrimSpace(consulta)); pareceISBN(normal) {
+		return []string{strings.ToLower(normal)}
+	}
+
+	vistos := make(map[string]bool)
+	terminos := make([]string, 0)
+	for _, t := range tokenizar(consulta) {
+		if !vistos[t] {
+			vistos[t] = true
+			terminos = append(terminos, t)
+		}
+	}
+	return terminos
+}
+
+// pareceISBN indica si un texto sin guiones tiene la forma de un ISBN,
+// aunque su dígito de control no sea válido
+func pareceISBN(normal string) bool {
+	if len(normal) != 10 && len(normal) != 13 {
+		return false
+	}
+	for i, r := range normal {
+		if (r < '0' || r > '9') && !(r == 'X' && i == len(normal)-1) {
+			return false
+		}
+	}
+	return true
+}
+
+// sinAcento asocia cada letra acentuada con su letra base
+var sinAcento = map[rune]rune{
+	'á': 'a', 'à': 'a', 'ä': 'a', 'â': 'a', 'ã': 'a', 'å': 'a',
+	'é': 'e', 'è': 'e', 'ë': 'e', 'ê': 'e',
+	'í': 'i', 'ì': 'i', 'ï': 'i', 'î': 'i',
+	'ó': 'o', 'ò': 'o', 'ö': 'o', 'ô': 'o', 'õ': 'o',
+	'ú': 'u', 'ù': 'u', 'ü': 'u', 'û': 'u',
+	'ñ': 'n', 'ç': 'c',
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// idsEncontrados lista los libros de una búsqueda en el orden del resultado
+func idsEncontrados(resultados []ResultadoBusqueda) []LibroID {
+	ids := make([]LibroID, 0, len(resultados))
+	for _, r := range resultados {
+		ids = append(ids, r.Libro.ID)
+	}
+	return ids
+}
+
+func TestBuscarLibrosIgnoraAcentosYMayusculas(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	cien, _ := b.AgregarLibro("Cien años de soledad", "Gabriel García Márquez", "", 471)
+	b.AgregarLibro("Ficciones", "Borges", "", 224)
+
+	for _, consulta := range []string{"garcia marquez", "GARCÍA", "anos soledad", "Márquez cien"} {
+		if ids := idsEncontrados(b.BuscarLibros(consulta)); len(ids) != 1 || ids[0] != cien.ID {
+			t.Errorf("%q: se encontraron %v, se esperaba solo %d", consulta, ids, cien.ID)
+		}
+	}
+	// todos los términos deben estar en el libro
+	if resultados := b.BuscarLibros("garcia borges"); len(resultados) != 0 {
+		t.Errorf("Ningún libro tiene los dos autores: %v", idsEncontrados(resultados))
+	}
+}
+
+func TestBuscarLibrosOrdenaPorRelevancia(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	porAutor, _ := b.AgregarLibro("Cuentos completos", "Rosa Montero", "", 300)
+	porTitulo, _ := b.AgregarLibro("La rosa púrpura", "Ana Pérez", "", 200)
+	b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+
+	// el título pesa más que el autor
+	ids := idsEncontrados(b.BuscarLibros("rosa"))
+	if len(ids) != 2 || ids[0] != porTitulo.ID || ids[1] != porAutor.ID {
+		t.Errorf("Se esperaba primero el título y después el autor: %v", ids)
+	}
+	// una consulta por ISBN-10 encuentra el libro guardado con su ISBN-13
+	conISBN, _ := b.AgregarLibro("Otra rosa", "Autor", "9780306406157", 100)
+	ids = idsEncontrados(b.BuscarLibros("0-306-40615-2"))
+	if len(ids) != 1 || ids[0] != conISBN.ID {
+		t.Errorf("El ISBN-10 debería encontrar el libro con ese ISBN-13: %v", ids)
+	}
+}
+
+func TestBuscarLibrosDespuesDeActualizar(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	libro, _ := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+	if _, err := b.ActualizarLibro(libro.ID, "62 Modelo para armar", "Julio Cortázar", 280); err != nil {
+		t.Fatal(err)
+	}
+
+	if resultados := b.BuscarLibros("rayuela"); len(resultados) != 0 {
+		t.Errorf("El título anterior no debería encontrarse: %v", idsEncontrados(resultados))
+	}
+	if ids := idsEncontrados(b.BuscarLibros("modelo julio")); len(ids) != 1 || ids[0] != libro.ID {
+		t.Errorf("El título nuevo debería encontrarse: %v", ids)
+	}
+}
+
+func TestBuscarLibrosConISBNHeredado(t *testing.T) {
+	// datos guardados antes de canonizar los ISBN: quedó un ISBN-10 con guiones
+	origen := NuevaBiblioteca("Central", "")
+	origen.AgregarLibro("Ficciones", "Borges", "9780306406157", 224)
+	path := filepath.Join(t.TempDir(), "biblioteca.json")
+	if err := origen.GuardarEn(path); err != nil {
+		t.Fatal(err)
+	}
+	datos, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	datos = []byte(strings.Replace(string(datos), `"9780306406157"`, `"0-306-40615-2"`, 1))
+	if err := os.WriteFile(path, datos, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := CargarDesde(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Libros[0].ISBN != "0-306-40615-2" {
+		t.Fatalf("El libro debería conservar el ISBN heredado: %s", b.Libros[0].ISBN)
+	}
+	for _, consulta := range []string{"9780306406157", "978-0-306-40615-7", "0306406152", "0-306-40615-2"} {
+		if resultados := b.BuscarLibros(consulta); len(resultados) != 1 {
+			t.Errorf("%q debería encontrar el libro con ISBN heredado", consulta)
+		}
+	}
+}
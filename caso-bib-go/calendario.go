@@ -0,0 +1,217 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"time"
+)
+
+// ==========================================
+// CALENDARIO: HORARIOS Y FERIADOS
+// ==========================================
+
+// Horario es la franja en que la biblioteca atiende un día ("09:00"-"20:00")
+// Un horario vacío indica que ese día está cerrada
+type Horario struct {
+	Abre   string
+	Cierra string
+}
+
+// Cerrado indica si el día no tiene atención
+func (h Horario) Cerrado() bool {
+	return h.Abre == "" && h.Cierra == ""
+}
+
+// Calendario define qué días abre la biblioteca
+// Semana se indexa con time.Weekday (0 = domingo); Feriados son fechas
+// AAAA-MM-DD en que está cerrada aunque el día de la semana tenga horario
+type Calendario struct {
+	Semana   [7]Horario
+	Feriados []string
+}
+
+// CalendarioPorDefecto retorna el calendario que usa NuevaBiblioteca:
+// lunes a viernes de 9 a 20, sábados de 10 a 14, domingos cerrado
+func CalendarioPorDefecto() Calendario {
+	semana := Horario{Abre: "09:00", Cierra: "20:00"}
+	return Calendario{
+		Semana: [7]Horario{
+			time.Monday:    semana,
+			time.Tuesday:   semana,
+			time.Wednesday: semana,
+			time.Thursday:  semana,
+			time.Friday:    semana,
+			time.Saturday:  {Abre: "10:00", Cierra: "14:00"},
+		},
+		Feriados: make([]string, 0),
+	}
+}
+
+// CargarCalendario lee un calendario en JSON, por ejemplo:
+//
+//	{"Semana": [{}, {"Abre": "09:00", "Cierra": "20:00"}, ...],
+//	 "Feriados": ["2026-12-25", "2027-01-01"]}
+func CargarCalendario(path string) (*Calendario, error) {
+	datos, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errArchivo(err, "error.archivo_leer", path)
+	}
+	var calendario Calendario
+	if err := json.Unmarshal(datos, &calendario); err != nil {
+		return nil, errFormato(err, "error.calendario_invalido", path)
+	}
+	if err := calendario.Validar(); err != nil {
+		return nil, err
+	}
+	return &calendario, nil
+}
+
+// Validar verifica los horarios y feriados, y que abra al menos un día
+func (c Calendario) Validar() error {
+	abiertos := 0
+	for dia, horario := range c.Semana {
+		if horario.Cerrado() {
+			continue
+		}
+		abre, errAbre := minutosDelDia(horario.Abre)
+		cierra, errCierra := minutosDelDia(horario.Cierra)
+		if errAbre != nil || errCierra != nil {
+			return errDatosInvalidos("error.horario_formato", diaSemana(dia))
+		}
+		if abre >= cierra {
+			return errDatosInvalidos("error.horario_rango",
+				diaSemana(dia), horario.Abre, horario.Cierra)
+		}
+		abiertos++
+	}
+	if abiertos == 0 {
+		return errDatosInvalidos("error.calendario_sin_dias")
+	}
+	for _, feriado := range c.Feriados {
+		if _, err := time.Parse("2006-01-02", feriado); err != nil {
+			return errDatosInvalidos("error.feriado_invalido", feriado)
+		}
+	}
+	return nil
+}
+
+// AbreEl indica si la biblioteca atiende en la fecha indicada
+func (c Calendario) AbreEl(fecha time.Time) bool {
+	if c.Semana[fecha.Weekday()].Cerrado() {
+		return false
+	}
+	dia := fecha.Format("2006-01-02")
+	for _, feriado := range c.Feriados {
+		if feriado == dia {
+			return false
+		}
+	}
+	return true
+}
+
+// Vencimiento suma dias a desde y, si ese día la biblioteca está cerrada,
+// avanza hasta el siguiente día de atención. El plazo vence a la hora de cierre
+func (c Calendario) Vencimiento(desde time.Time, dias int) time.Time {
+	fecha := desde.AddDate(0, 0, dias)
+	// un calendario válido abre al menos un día por semana; el tope solo
+	// protege de un calendario con todos los días del año feriados
+	for i := 0; i < 366 && !c.AbreEl(fecha); i++ {
+		fecha = fecha.AddDate(0, 0, 1)
+	}
+
+	cierra, err := minutosDelDia(c.Semana[fecha.Weekday()].Cierra)
+	if err != nil {
+		return fecha
+	}
+	return time.Date(fecha.Year(), fecha.Month(), fecha.Day(), cierra/60, cierra%60, 0, 0, fecha.Location())
+}
+
+// DiasAtraso cuenta los días de atención transcurridos desde el vencimiento
+// Los días que la biblioteca está cerrada no suman multa, porque no se
+// podía devolver el libro; un día de atención cuenta completo desde que abre
+func (c Calendario) DiasAtraso(vencimiento, ahora time.Time) int {
+	if !ahora.After(vencimiento) {
+		return 0
+	}
+	dias := 0
+	fecha := inicioDelDia(vencimiento).AddDate(0, 0, 1)
+	for !fecha.After(ahora) {
+		if c.AbreEl(fecha) && !ahora.Before(c.apertura(fecha)) {
+			dias++
+		}
+		fecha = fecha.AddDate(0, 0, 1)
+	}
+	return dias
+}
+
+// apertura retorna la hora en que abre la biblioteca el día de fecha
+func (c Calendario) apertura(fecha time.Time) time.Time {
+	abre, err := minutosDelDia(c.Semana[fecha.Weekday()].Abre)
+	if err != nil {
+		return inicioDelDia(fecha)
+	}
+	return time.Date(fecha.Year(), fecha.Month(), fecha.Day(), abre/60, abre%60, 0, 0, fecha.Location())
+}
+
+// UsarCalendario reemplaza el calendario de la biblioteca
+// Los préstamos en curso conservan su fecha de devolución
+// Si el journal falla el calendario queda como estaba
+func (b *Biblioteca) UsarCalendario(calendario Calendario) error {
+	if err := calendario.Validar(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	anterior := b.Calendario
+	b.Calendario = calendario
+	if err := b.escribirEvento(Evento{Tipo: EventoCalendarioCambiado, Calendario: &calendario}); err != nil {
+		b.Calendario = anterior
+		return errJournal(err, "error.calendario_no_registrado")
+	}
+	return nil
+}
+
+// VerCalendario retorna una copia del calendario de la biblioteca
+func (b *Biblioteca) VerCalendario() Calendario {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	calendario := b.Calendario
+	calendario.Feriados = append([]string(nil), calendario.Feriados...)
+	return calendario
+}
+
+// CalcularAtraso retorna los días de atraso y la multa que corresponde hoy
+// a un préstamo, según el calendario y la categoría de su usuario
+func (b *Biblioteca) CalcularAtraso(prestamo Prestamo, ahora time.Time) (int, float64) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	dias := b.diasAtraso(prestamo, ahora)
+	return dias, b.politicaDe(prestamo.UsuarioID).CalcularMulta(dias)
+}
+
+// diasAtraso cuenta los días de atraso de un préstamo con el calendario
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) diasAtraso(prestamo Prestamo, ahora time.Time) int {
+	return b.Calendario.DiasAtraso(prestamo.FechaDevolucion, ahora)
+}
+
+// minutosDelDia convierte "HH:MM" en minutos desde la medianoche
+func minutosDelDia(hora string) (int, error) {
+	t, err := time.Parse("15:04", hora)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func inicioDelDia(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// diaSemana es el nombre de cada time.Weekday, sin traducir
+func diaSemana(dia int) texto {
+	return txt(ClaveMensaje("dia." + strconv.Itoa(dia)))
+}
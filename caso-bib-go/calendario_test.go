@@ -0,0 +1,125 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// marzo arma una hora local; marzo de 2026 empieza el domingo 1
+func marzo(dia, hora, minuto int) time.Time {
+	return time.Date(2026, 3, dia, hora, minuto, 0, 0, time.Local)
+}
+
+func TestCalendarioDiasDeAtencion(t *testing.T) {
+	c := CalendarioPorDefecto()
+	c.Feriados = []string{"2026-03-03"}
+
+	casos := []struct {
+		dia  int
+		abre bool
+	}{
+		{1, false}, // domingo
+		{2, true},  // lunes
+		{3, false}, // feriado
+		{7, true},  // sábado
+	}
+	for _, caso := range casos {
+		if abre := c.AbreEl(marzo(caso.dia, 12, 0)); abre != caso.abre {
+			t.Errorf("El %d de marzo: AbreEl = %v, se esperaba %v", caso.dia, abre, caso.abre)
+		}
+	}
+}
+
+func TestCalendarioVencimientoPasaAlSiguienteDiaAbierto(t *testing.T) {
+	c := CalendarioPorDefecto()
+	c.Feriados = []string{"2026-03-09"}
+
+	casos := []struct {
+		desde    time.Time
+		dias     int
+		esperado time.Time
+	}{
+		{marzo(2, 10, 0), 3, marzo(5, 20, 0)},  // jueves abierto: vence al cierre
+		{marzo(2, 10, 0), 5, marzo(7, 14, 0)},  // sábado cierra a las 14
+		{marzo(2, 10, 0), 6, marzo(10, 20, 0)}, // domingo y lunes feriado: pasa al martes
+	}
+	for _, caso := range casos {
+		if vence := c.Vencimiento(caso.desde, caso.dias); !vence.Equal(caso.esperado) {
+			t.Errorf("Vencimiento(%s, %d) = %s, se esperaba %s", caso.desde, caso.dias, vence, caso.esperado)
+		}
+	}
+}
+
+func TestCalendarioDiasAtrasoCuentaDesdeQueAbre(t *testing.T) {
+	c := CalendarioPorDefecto()
+	c.Feriados = []string{"2026-03-10"}
+	vencimiento := marzo(6, 20, 0) // viernes al cierre
+
+	casos := []struct {
+		ahora time.Time
+		dias  int
+	}{
+		{marzo(6, 19, 0), 0},
+		{marzo(6, 23, 0), 0},  // el viernes ya cerró
+		{marzo(7, 0, 0), 0},   // medianoche del sábado, todavía no abrió
+		{marzo(7, 9, 59), 0},  // el sábado abre a las 10
+		{marzo(7, 10, 0), 1},  // abrió el sábado
+		{marzo(8, 15, 0), 1},  // el domingo no abre
+		{marzo(9, 8, 0), 1},   // el lunes antes de abrir
+		{marzo(9, 9, 0), 2},   // abrió el lunes
+		{marzo(10, 12, 0), 2}, // el martes es feriado
+		{marzo(11, 9, 30), 3},
+	}
+	for _, caso := range casos {
+		if dias := c.DiasAtraso(vencimiento, caso.ahora); dias != caso.dias {
+			t.Errorf("DiasAtraso a las %s = %d, se esperaba %d", caso.ahora, dias, caso.dias)
+		}
+	}
+}
+
+func TestCalendarioValidarRechazaHorariosInvalidos(t *testing.T) {
+	casos := map[string]func(*Calendario){
+		"sin días":        func(c *Calendario) { c.Semana = [7]Horario{} },
+		"formato":         func(c *Calendario) { c.Semana[time.Monday].Abre = "9hs" },
+		"rango invertido": func(c *Calendario) { c.Semana[time.Monday] = Horario{Abre: "20:00", Cierra: "09:00"} },
+		"feriado":         func(c *Calendario) { c.Feriados = []string{"2026-02-30"} },
+	}
+	for nombre, modificar := range casos {
+		c := CalendarioPorDefecto()
+		modificar(&c)
+		if err := c.Validar(); !errors.Is(err, ErrDatosInvalidos) {
+			t.Errorf("%s: se esperaba un error de datos, se obtuvo %v", nombre, err)
+		}
+	}
+}
+
+func TestPrestamoVenceElSiguienteDiaAbierto(t *testing.T) {
+	reloj := NuevoRelojVirtual(marzo(2, 10, 0))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+	calendario := CalendarioPorDefecto()
+	calendario.Feriados = []string{"2026-03-16"}
+	if err := b.UsarCalendario(calendario); err != nil {
+		t.Fatal(err)
+	}
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 224)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+
+	// los 14 días del estudiante terminan el lunes 16, que es feriado
+	prestamo, err := b.PrestarLibro(libro.ID, ana.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if esperado := marzo(17, 20, 0); !prestamo.FechaDevolucion.Equal(esperado) {
+		t.Errorf("El préstamo vence %s, se esperaba %s", prestamo.FechaDevolucion, esperado)
+	}
+
+	// la mañana siguiente, antes de abrir, todavía no hay multa
+	if dias, multa := b.CalcularAtraso(*prestamo, marzo(18, 8, 0)); dias != 0 || multa != 0 {
+		t.Errorf("Antes de abrir no debería haber atraso: %d días, multa %v", dias, multa)
+	}
+	if dias, _ := b.CalcularAtraso(*prestamo, marzo(18, 9, 0)); dias != 1 {
+		t.Errorf("Al abrir debería contar un día de atraso, se obtuvo %d", dias)
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"sort"
+	"strings"
+)
+
+// ==========================================
+// CATEGORÍAS DE SOCIO Y SUS POLÍTICAS
+// ==========================================
+
+// CategoriaUsuario es el tipo de membresía de un usuario
+type CategoriaUsuario string
+
+const (
+	CategoriaEstudiante CategoriaUsuario = "estudiante"
+	CategoriaDocente    CategoriaUsuario = "docente"
+	CategoriaPublico    CategoriaUsuario = "publico" // público general
+)
+
+// PoliticasPorCategoria retorna las reglas que usa NuevaBiblioteca para cada categoría
+func PoliticasPorCategoria() map[CategoriaUsuario]PoliticaPrestamo {
+	return map[CategoriaUsuario]PoliticaPrestamo{
+		CategoriaEstudiante: {
+			DiasPrestamo:      14,
+			MaxPrestamos:      3,
+			MaxRenovaciones:   2,
+			DiasRetiroReserva: 3,
+			MultaDiaria:       0.5,
+			MultaMaxima:       10,
+
+			DeudaMaxima:          5,
+			DiasAtrasoSuspension: 30,
+		},
+		CategoriaDocente: {
+			DiasPrestamo:      30,
+			MaxPrestamos:      10,
+			MaxRenovaciones:   3,
+			DiasRetiroReserva: 5,
+			MultaDiaria:       0.25,
+			MultaMaxima:       10,
+
+			DeudaMaxima:          20,
+			DiasAtrasoSuspension: 60,
+		},
+		CategoriaPublico: {
+			DiasPrestamo:      7,
+			MaxPrestamos:      2,
+			MaxRenovaciones:   1,
+			DiasRetiroReserva: 2,
+			MultaDiaria:       1,
+			MultaMaxima:       15,
+
+			DeudaMaxima:          15,
+			DiasAtrasoSuspension: 21,
+		},
+	}
+}
+
+// ValidarCategoria verifica que la categoría tenga una política definida
+func (b *Biblioteca) ValidarCategoria(categoria CategoriaUsuario) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.validarCategoria(categoria)
+}
+
+// validarCategoria es ValidarCategoria para quien ya tiene tomado b.mu
+func (b *Biblioteca) validarCategoria(categoria CategoriaUsuario) error {
+	if _, ok := b.Categorias[categoria]; ok {
+		return nil
+	}
+	nombres := make([]string, 0, len(b.Categorias))
+	for nombre := range b.Categorias {
+		nombres = append(nombres, string(nombre))
+	}
+	sort.Strings(nombres)
+	return &ErrorBiblioteca{Tipo: ErrDatosInvalidos, Valor: string(categoria),
+		mensaje: txt("error.categoria_desconocida", categoria, strings.Join(nombres, ", "))}
+}
+
+// CambiarCategoria cambia la membresía de un usuario
+// Los préstamos en curso conservan su fecha de devolución
+func (b *Biblioteca) CambiarCategoria(usuarioID UsuarioID, categoria CategoriaUsuario) (*Usuario, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	usuario := b.buscarUsuario(usuarioID)
+	if usuario == nil {
+		return nil, errUsuarioNoEncontrado(usuarioID)
+	}
+	if err := b.validarCategoria(categoria); err != nil {
+		return nil, err
+	}
+	actualizado := *usuario
+	actualizado.Categoria = categoria
+	if err := b.escribirEvento(Evento{Tipo: EventoUsuarioActualizado, Usuario: &actualizado}); err != nil {
+		return nil, errJournal(err, "error.cambio_no_registrado")
+	}
+	*usuario = actualizado
+
+	copia := *usuario
+	return &copia, nil
+}
+
+// UsarPolitica reemplaza las reglas de una categoría, o las de los usuarios
+// sin categoría si categoria es vacía. Una categoría nueva queda disponible
+// para CambiarCategoria. Los préstamos en curso conservan su fecha de devolución
+// Si el journal falla las reglas quedan como estaban
+func (b *Biblioteca) UsarPolitica(categoria CategoriaUsuario, politica PoliticaPrestamo) error {
+	if err := politica.Validar(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	anterior, existia := b.Politica, true
+	if categoria != "" {
+		anterior, existia = b.Categorias[categoria]
+	}
+	b.fijarPolitica(categoria, politica)
+	evento := Evento{Tipo: EventoPoliticaCambiada, Categoria: categoria, Politica: &politica}
+	if err := b.escribirEvento(evento); err != nil {
+		if existia {
+			b.fijarPolitica(categoria, anterior)
+		} else {
+			delete(b.Categorias, categoria)
+		}
+		return errJournal(err, "error.politica_no_registrada")
+	}
+	return nil
+}
+
+// fijarPolitica asigna las reglas de una categoría o, si es vacía, las generales
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) fijarPolitica(categoria CategoriaUsuario, politica PoliticaPrestamo) {
+	if categoria == "" {
+		b.Politica = politica
+		return
+	}
+	if b.Categorias == nil {
+		b.Categorias = make(map[CategoriaUsuario]PoliticaPrestamo)
+	}
+	b.Categorias[categoria] = politica
+}
+
+// politicaDe retorna las reglas que se aplican a un usuario
+// Los usuarios sin categoría (datos anteriores a las categorías) usan b.Politica
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) politicaDe(usuarioID UsuarioID) PoliticaPrestamo {
+	usuario := b.buscarUsuario(usuarioID)
+	if usuario == nil || usuario.Categoria == "" {
+		return b.Politica
+	}
+	if politica, ok := b.Categorias[usuario.Categoria]; ok {
+		return politica
+	}
+	return b.Politica
+}
+
+// prestamosActivosDeUsuario cuenta los préstamos sin devolver de un usuario
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) prestamosActivosDeUsuario(usuarioID UsuarioID) int {
+	activos := 0
+	for _, pos := range b.indices.prestamosUsuario[usuarioID] {
+		if !b.Prestamos[pos].Devuelto {
+			activos++
+		}
+	}
+	return activos
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPlazoDePrestamoSegunCategoria(t *testing.T) {
+	reloj := NuevoRelojVirtual(marzo(2, 10, 0))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+
+	casos := []struct {
+		categoria CategoriaUsuario
+		vence     int // día de marzo; el 32 es el 1 de abril
+	}{
+		{CategoriaEstudiante, 16},
+		{CategoriaDocente, 32},
+		{CategoriaPublico, 9},
+		{"", 9}, // sin categoría se registra como público general
+	}
+	for i, c := range casos {
+		libro, _ := b.AgregarLibro("Libro", "Autor", "", 100)
+		usuario, err := b.RegistrarUsuario("Socio", string(rune('a'+i))+"@test", "", c.categoria)
+		if err != nil {
+			t.Fatal(err)
+		}
+		prestamo, err := b.PrestarLibro(libro.ID, usuario.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !prestamo.FechaDevolucion.Equal(marzo(c.vence, 20, 0)) {
+			t.Errorf("%q: vence %s, se esperaba el %d de marzo", c.categoria, prestamo.FechaDevolucion, c.vence)
+		}
+	}
+}
+
+func TestLimiteDePrestamosSegunCategoria(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	publico, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaPublico)
+	libros := make([]LibroID, 3)
+	for i := range libros {
+		libro, _ := b.AgregarLibro("Libro", "Autor", "", 100)
+		libros[i] = libro.ID
+	}
+
+	// el público general puede tener 2 préstamos a la vez
+	for _, id := range libros[:2] {
+		if _, err := b.PrestarLibro(id, publico.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := b.PrestarLibro(libros[2], publico.ID); !errors.Is(err, ErrLimitePrestamos) {
+		t.Fatalf("El tercer préstamo debería rechazarse, se obtuvo %v", err)
+	}
+
+	// como docente el límite es 10
+	if _, err := b.CambiarCategoria(publico.ID, CategoriaDocente); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PrestarLibro(libros[2], publico.ID); err != nil {
+		t.Errorf("Como docente debería poder llevarlo: %v", err)
+	}
+}
+
+func TestCategoriasDefinidasPorLaBiblioteca(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+
+	if _, err := b.RegistrarUsuario("Ana", "ana@test", "", "jubilado"); !errors.Is(err, ErrDatosInvalidos) {
+		t.Errorf("Una categoría sin política debería rechazarse, se obtuvo %v", err)
+	}
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	if _, err := b.CambiarCategoria(ana.ID, "jubilado"); !errors.Is(err, ErrDatosInvalidos) {
+		t.Errorf("No se puede cambiar a una categoría sin política, se obtuvo %v", err)
+	}
+	if err := b.UsarPolitica("jubilado", PoliticaPrestamo{DiasPrestamo: -1}); !errors.Is(err, ErrDatosInvalidos) {
+		t.Errorf("Una política inválida debería rechazarse, se obtuvo %v", err)
+	}
+
+	if err := b.UsarPolitica("jubilado", PoliticaPrestamo{DiasPrestamo: 21, MaxPrestamos: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if usuario, err := b.CambiarCategoria(ana.ID, "jubilado"); err != nil || usuario.Categoria != "jubilado" {
+		t.Fatalf("La categoría nueva debería poder usarse: %+v, %v", usuario, err)
+	}
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 224)
+	otro, _ := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PrestarLibro(otro.ID, ana.ID); !errors.Is(err, ErrLimitePrestamos) {
+		t.Errorf("La política nueva permite un préstamo, se obtuvo %v", err)
+	}
+}
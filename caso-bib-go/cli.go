@@ -0,0 +1,1461 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// HERRAMIENTA DE LÍNEA DE COMANDOS
+// ==========================================
+
+// Códigos de salida de la herramienta
+const (
+	salidaOK           = 0
+	salidaError        = 1 // la operación sobre la biblioteca falló
+	salidaUsoCLI       = 2 // argumentos inválidos
+	salidaNoEncontrado = 3 // no existe el libro, usuario, préstamo... indicado
+	salidaConflicto    = 4 // la operación choca con el estado actual
+)
+
+// archivoPorDef es el archivo de datos cuando no se indica --datos
+const archivoPorDef = "biblioteca.json"
+
+// esperaBloqueo es cuánto espera un comando que modifica los datos a que
+// termine otro que los está modificando
+var esperaBloqueo = 5 * time.Second
+
+const usoCLI = `Uso: biblioteca <comando> [opciones]
+
+Comandos:
+  iniciar            --nombre N --direccion D
+  libro agregar      --titulo T --autor A [--isbn I] [--paginas P]
+  libro listar       [--disponibles] [--filtro "autor:X paginas>300"]
+  libro buscar       --consulta "texto"
+  libro actualizar   --libro ID --titulo T --autor A --paginas P
+  libro importar     --archivo catalogo.csv [--prueba] [--formato csv|marc|marcxml|dc]
+                     [--separador coma|tab] [--columna "Encabezado=campo"]...
+  libro exportar     --formato (marc|marcxml|dc) [--libro ID] [--archivo salida]
+  libro historial    --libro ID [--desde AAAA-MM-DD] [--hasta AAAA-MM-DD] [--pagina N]
+  ejemplar agregar   --libro ID [--codigo C]
+  usuario registrar  --nombre N --email E [--telefono T] [--categoria C]
+  usuario categoria  --usuario ID --categoria (estudiante|docente|publico)
+  usuario idioma     --usuario ID --idioma (es|en|pt)
+  usuario listar     [--filtro "activo:false email:*@gmail.com"]
+  usuario historial  --usuario ID [--desde AAAA-MM-DD] [--hasta AAAA-MM-DD] [--pagina N]
+  usuario revisar
+  prestamo crear     (--libro ID | --ejemplar ID) --usuario ID
+  prestamo devolver  (--libro ID | --ejemplar ID)
+  prestamo renovar   --prestamo ID
+  prestamo listar    [--filtro "vencido:true"]
+  prestamo vencidos
+  multa pagar        --prestamo ID
+  reserva crear      --libro ID --usuario ID
+  reserva cancelar   --reserva ID
+  reserva listar     --libro ID
+  reserva vencer
+  calendario ver
+  calendario cargar  --archivo calendario.json
+  politica cargar    --archivo politica.json [--categoria C]
+  simular            [--dias N] [--semilla S] [--desde AAAA-MM-DD] [--libros N] [--usuarios N]
+                     [--guardar archivo]
+  estadisticas
+  verificar          [--reparar]
+  servir             [--addr :8080]
+
+Opciones comunes:
+  --datos archivo    archivo de datos (por defecto biblioteca.json)
+  --json             salida en JSON para scripts
+  --idioma es|en|pt  idioma de los mensajes (por defecto el del usuario o es)
+
+Códigos de salida: 0 ok, 1 error, 2 argumentos o datos inválidos,
+3 no existe lo indicado, 4 conflicto con el estado (ya prestado, duplicado...)
+`
+
+// contextoCLI agrupa lo que necesita cada subcomando
+type contextoCLI struct {
+	datos   string
+	json    bool
+	idioma  Idioma // vacío si no se indicó --idioma
+	salida  io.Writer
+	errores io.Writer
+	journal *Journal // el de los datos, si el comando los modifica
+	bloqueo *os.File // ver bloquearDatos
+}
+
+// comandoCLI es la función que implementa un subcomando
+type comandoCLI func(ctx *contextoCLI, args []string) int
+
+// comandosCLI asocia "grupo accion" con su implementación
+var comandosCLI = map[string]comandoCLI{
+	"iniciar":           cmdIniciar,
+	"libro agregar":     cmdLibroAgregar,
+	"libro listar":      cmdLibroListar,
+	"libro buscar":      cmdLibroBuscar,
+	"libro actualizar":  cmdLibroActualizar,
+	"libro importar":    cmdLibroImportar,
+	"libro exportar":    cmdLibroExportar,
+	"ejemplar agregar":  cmdEjemplarAgregar,
+	"usuario registrar": cmdUsuarioRegistrar,
+	"usuario listar":    cmdUsuarioListar,
+	"usuario categoria": cmdUsuarioCategoria,
+	"usuario idioma":    cmdUsuarioIdioma,
+	"usuario historial": cmdUsuarioHistorial,
+	"usuario revisar":   cmdUsuarioRevisar,
+	"libro historial":   cmdLibroHistorial,
+	"prestamo listar":   cmdPrestamoListar,
+	"prestamo crear":    cmdPrestamoCrear,
+	"prestamo devolver": cmdPrestamoDevolver,
+	"prestamo renovar":  cmdPrestamoRenovar,
+	"prestamo vencidos": cmdPrestamoVencidos,
+	"multa pagar":       cmdMultaPagar,
+	"reserva crear":     cmdReservaCrear,
+	"reserva cancelar":  cmdReservaCancelar,
+	"reserva listar":    cmdReservaListar,
+	"reserva vencer":    cmdReservaVencer,
+	"calendario ver":    cmdCalendarioVer,
+	"calendario cargar": cmdCalendarioCargar,
+	"politica cargar":   cmdPoliticaCargar,
+	"simular":           cmdSimular,
+	"estadisticas":      cmdEstadisticas,
+	"verificar":         cmdVerificar,
+	"servir":            cmdServir,
+}
+
+// ejecutarCLI interpreta los argumentos y retorna el código de salida
+func ejecutarCLI(args []string, salida, errores io.Writer) int {
+	ctx := &contextoCLI{datos: archivoPorDef, salida: salida, errores: errores}
+
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "ayuda" {
+		fmt.Fprint(errores, usoCLI)
+		return salidaUsoCLI
+	}
+
+	defer ctx.liberar()
+
+	// Los comandos pueden ser de una palabra ("estadisticas") o dos ("libro agregar")
+	if cmd, ok := comandosCLI[args[0]]; ok {
+		return cmd(ctx, args[1:])
+	}
+	if len(args) >= 2 {
+		if cmd, ok := comandosCLI[args[0]+" "+args[1]]; ok {
+			return cmd(ctx, args[2:])
+		}
+	}
+
+	fmt.Fprintf(errores, "%s\n\n%s", IdiomaPorDefecto.Texto("cli.comando_desconocido", strings.Join(args, " ")), usoCLI)
+	return salidaUsoCLI
+}
+
+// nuevoFlagSet crea las opciones de un subcomando incluyendo --datos, --json e --idioma
+func (ctx *contextoCLI) nuevoFlagSet(nombre string) *flag.FlagSet {
+	fs := flag.NewFlagSet(nombre, flag.ContinueOnError)
+	fs.SetOutput(ctx.errores)
+	fs.StringVar(&ctx.datos, "datos", ctx.datos, "archivo de datos")
+	fs.BoolVar(&ctx.json, "json", false, "salida en JSON")
+	fs.Var(valorIdioma{&ctx.idioma}, "idioma", "idioma de los mensajes (es, en o pt)")
+	return fs
+}
+
+// idiomaPara elige el idioma de un mensaje dirigido al usuario indicado:
+// el de --idioma, si no el preferido del usuario, si no IdiomaPorDefecto
+func (ctx *contextoCLI) idiomaPara(usuario *Usuario) Idioma {
+	if ctx.idioma != "" {
+		return ctx.idioma
+	}
+	if usuario != nil {
+		return usuario.IdiomaPreferido()
+	}
+	return IdiomaPorDefecto
+}
+
+// valorIdioma es un flag.Value que acepta solo idiomas con catálogo
+type valorIdioma struct{ idioma *Idioma }
+
+func (v valorIdioma) String() string {
+	if v.idioma == nil {
+		return ""
+	}
+	return string(*v.idioma)
+}
+
+func (v valorIdioma) Set(texto string) error {
+	idioma, err := ParsearIdioma(texto)
+	if err != nil {
+		return err
+	}
+	*v.idioma = idioma
+	return nil
+}
+
+// valorID es un flag.Value para IDs tipados (--libro, --usuario, ...)
+type valorID[T ~int] struct{ id *T }
+
+func (v valorID[T]) String() string {
+	if v.id == nil {
+		return "0"
+	}
+	return strconv.Itoa(int(*v.id))
+}
+
+func (v valorID[T]) Set(texto string) error {
+	id, err := strconv.Atoi(texto)
+	if err != nil {
+		return errDatosInvalidos("error.id_invalido", texto)
+	}
+	*v.id = T(id)
+	return nil
+}
+
+// flagID define una opción que recibe un ID del tipo indicado
+func flagID[T ~int](fs *flag.FlagSet, nombre, uso string) *T {
+	id := new(T)
+	fs.Var(valorID[T]{id}, nombre, uso)
+	return id
+}
+
+// listaFlag es un flag.Value que acumula cada aparición de la opción
+type listaFlag []string
+
+func (l *listaFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listaFlag) Set(texto string) error {
+	*l = append(*l, texto)
+	return nil
+}
+
+// parsear procesa las opciones y retorna false si son inválidas
+func (ctx *contextoCLI) parsear(fs *flag.FlagSet, args []string) bool {
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintln(ctx.errores, ctx.idiomaPara(nil).Texto("cli.argumentos_inesperados", strings.Join(fs.Args(), " ")))
+		return false
+	}
+	return true
+}
+
+// rutaJournal es el journal que acompaña a un archivo de datos:
+// biblioteca.json se registra en biblioteca.journal
+func rutaJournal(datos string) string {
+	return strings.TrimSuffix(datos, filepath.Ext(datos)) + ".journal"
+}
+
+// rutaBloqueo es el archivo que bloquean los procesos que modifican los datos
+func rutaBloqueo(datos string) string {
+	return strings.TrimSuffix(datos, filepath.Ext(datos)) + ".lock"
+}
+
+// bloquearDatos toma el bloqueo de los datos, esperando hasta espera a que
+// lo suelte otro proceso. Así dos comandos no cargan el mismo archivo y el
+// segundo en guardar pisa los cambios del primero
+// Lo suelta ejecutarCLI al terminar el comando
+func (ctx *contextoCLI) bloquearDatos(espera time.Duration) error {
+	archivo, err := os.OpenFile(rutaBloqueo(ctx.datos), os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return err
+	}
+	limite := time.Now().Add(espera)
+	for {
+		bloqueado, err := bloquearArchivo(archivo)
+		if err != nil {
+			archivo.Close()
+			return err
+		}
+		if bloqueado {
+			ctx.bloqueo = archivo
+			return nil
+		}
+		if time.Now().After(limite) {
+			archivo.Close()
+			return &ErrorBiblioteca{Tipo: ErrDatosEnUso, Valor: ctx.datos, mensaje: txt("error.datos_en_uso", ctx.datos)}
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// cargar abre el archivo de datos o crea una biblioteca vacía si no existe
+// Aplica los eventos del journal que el archivo todavía no incluye, por
+// ejemplo si un comando anterior terminó antes de guardarlo
+func (ctx *contextoCLI) cargar() (*Biblioteca, error) {
+	b, err := ctx.cargarDatos()
+	if err != nil {
+		return nil, err
+	}
+	if err := b.RecuperarDesdeJournal(rutaJournal(ctx.datos)); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// cargarParaModificar es cargar para los comandos que modifican: conecta el
+// journal, que aplica los eventos pendientes con lo que leyó al abrirse, así
+// el archivo se lee una sola vez
+func (ctx *contextoCLI) cargarParaModificar() (*Biblioteca, error) {
+	b, err := ctx.cargarDatos()
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.conectarJournal(b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// cargarDatos lee solo el archivo de datos, sin el journal
+// Los avisos de la biblioteca se muestran en la salida de errores
+func (ctx *contextoCLI) cargarDatos() (*Biblioteca, error) {
+	b, err := CargarDesde(ctx.datos)
+	if errors.Is(err, os.ErrNotExist) {
+		b, err = NuevaBiblioteca("Biblioteca", ""), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.AlAvisar(func(aviso error) {
+		fmt.Fprintf(ctx.errores, "⚠️  %s\n", MensajeDeError(aviso, ctx.idiomaPara(nil)))
+	})
+	return b, nil
+}
+
+// conectarJournal abre el journal de los datos y registra en él cada cambio
+// de la biblioteca; ejecutarCLI lo cierra al terminar el comando
+func (ctx *contextoCLI) conectarJournal(b *Biblioteca) error {
+	j, err := AbrirJournal(rutaJournal(ctx.datos))
+	if err != nil {
+		return err
+	}
+	if err := b.UsarJournal(j); err != nil {
+		j.Cerrar()
+		return err
+	}
+	ctx.journal = j
+	return nil
+}
+
+// liberar cierra el journal de conectarJournal y suelta el bloqueo de
+// bloquearDatos, si el comando los tomó
+func (ctx *contextoCLI) liberar() {
+	if ctx.journal != nil {
+		ctx.journal.Cerrar()
+		ctx.journal = nil
+	}
+	if ctx.bloqueo != nil {
+		ctx.bloqueo.Close()
+		ctx.bloqueo = nil
+	}
+}
+
+// verificarNuevo falla si ya existen el archivo de datos indicado o su journal
+func verificarNuevo(datos string) error {
+	for _, path := range []string{datos, rutaJournal(datos)} {
+		if _, err := os.Stat(path); err == nil {
+			return &ErrorBiblioteca{Tipo: ErrArchivoExistente, Valor: path, mensaje: txt("error.archivo_existente", path)}
+		}
+	}
+	return nil
+}
+
+// fallar informa un error de la biblioteca y retorna el código correspondiente
+func (ctx *contextoCLI) fallar(err error) int {
+	if ctx.json {
+		ctx.imprimirJSON(nuevaRespuestaError(err, ctx.idiomaPara(nil)))
+	} else {
+		fmt.Fprintf(ctx.errores, "❌ %s\n", MensajeDeError(err, ctx.idiomaPara(nil)))
+	}
+	return codigoSalida(err)
+}
+
+// codigoSalida traduce la clase de un error de la biblioteca a un código de salida
+func codigoSalida(err error) int {
+	switch {
+	case errors.Is(err, ErrNoEncontrado):
+		return salidaNoEncontrado
+	case errors.Is(err, ErrConflicto):
+		return salidaConflicto
+	case errors.Is(err, ErrDatosInvalidos):
+		return salidaUsoCLI
+	}
+	return salidaError
+}
+
+// imprimirJSON escribe un valor como JSON en la salida estándar
+func (ctx *contextoCLI) imprimirJSON(valor any) {
+	enc := json.NewEncoder(ctx.salida)
+	enc.SetIndent("", "  ")
+	enc.Encode(valor)
+}
+
+// mutar carga la biblioteca, aplica la operación y guarda el resultado
+// La operación queda en el journal aunque el guardado falle; si se guarda,
+// el journal se recorta hasta lo guardado
+func (ctx *contextoCLI) mutar(operacion func(b *Biblioteca) error) int {
+	if err := ctx.bloquearDatos(esperaBloqueo); err != nil {
+		return ctx.fallar(err)
+	}
+	b, err := ctx.cargarParaModificar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	if err := operacion(b); err != nil {
+		return ctx.fallar(err)
+	}
+	if err := b.Consolidar(ctx.datos); err != nil {
+		return ctx.fallar(err)
+	}
+	return salidaOK
+}
+
+func cmdIniciar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("iniciar")
+	nombre := fs.String("nombre", "", "nombre de la biblioteca")
+	direccion := fs.String("direccion", "", "dirección de la biblioteca")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	if *nombre == "" {
+		fmt.Fprintln(ctx.errores, ctx.idiomaPara(nil).Texto("cli.falta_opcion", "--nombre"))
+		return salidaUsoCLI
+	}
+
+	if err := ctx.bloquearDatos(esperaBloqueo); err != nil {
+		return ctx.fallar(err)
+	}
+	if err := verificarNuevo(ctx.datos); err != nil {
+		return ctx.fallar(err)
+	}
+	b := NuevaBiblioteca(*nombre, *direccion)
+	if err := ctx.conectarJournal(b); err != nil {
+		return ctx.fallar(err)
+	}
+	if err := b.Consolidar(ctx.datos); err != nil {
+		return ctx.fallar(err)
+	}
+	if ctx.json {
+		ctx.imprimirJSON(struct{ Nombre, Direccion string }{b.Nombre, b.Direccion})
+	} else {
+		fmt.Fprintln(ctx.salida, ctx.idiomaPara(nil).Texto("biblioteca.creada", b.Nombre))
+	}
+	return salidaOK
+}
+
+func cmdLibroAgregar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("libro agregar")
+	titulo := fs.String("titulo", "", "título del libro")
+	autor := fs.String("autor", "", "autor del libro")
+	isbn := fs.String("isbn", "", "ISBN del libro")
+	paginas := fs.Int("paginas", 0, "cantidad de páginas")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		libro, err := b.AgregarLibro(*titulo, *autor, *isbn, *paginas)
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(libro)
+		} else {
+			idioma := ctx.idiomaPara(nil)
+			fmt.Fprintln(ctx.salida, idioma.Texto("libro.agregado", libro.ObtenerInfoEn(idioma)))
+		}
+		return nil
+	})
+}
+
+func cmdLibroListar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("libro listar")
+	disponibles := fs.Bool("disponibles", false, "solo libros disponibles")
+	filtro := fs.String("filtro", "", "consulta estructurada, ej. autor:\"Márquez\" paginas>300")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	catalogo, err := b.ConsultarLibros(*filtro)
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	libros := make([]Libro, 0)
+	for _, libro := range catalogo {
+		if !*disponibles || libro.Disponibles() > 0 {
+			libros = append(libros, libro)
+		}
+	}
+
+	if ctx.json {
+		ctx.imprimirJSON(libros)
+		return salidaOK
+	}
+	idioma := ctx.idiomaPara(nil)
+	if len(libros) == 0 {
+		fmt.Fprintln(ctx.salida, idioma.Texto("libro.ninguno"))
+	}
+	for _, libro := range libros {
+		fmt.Fprintf(ctx.salida, " %s\n", libro.ObtenerInfoEn(idioma))
+		if libro.ISBN != "" {
+			fmt.Fprintf(ctx.salida, "     ISBN %s\n", FormatearISBN(libro.ISBN))
+		}
+	}
+	return salidaOK
+}
+
+func cmdLibroBuscar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("libro buscar")
+	consulta := fs.String("consulta", "", "texto a buscar en título, autor o ISBN")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	resultados := b.BuscarLibros(*consulta)
+
+	if ctx.json {
+		ctx.imprimirJSON(resultados)
+		return salidaOK
+	}
+	idioma := ctx.idiomaPara(nil)
+	if len(resultados) == 0 {
+		fmt.Fprintln(ctx.salida, idioma.Texto("libro.sin_resultados"))
+	}
+	for _, r := range resultados {
+		fmt.Fprintf(ctx.salida, " %s (%s)\n", r.Libro.ObtenerInfoEn(idioma), idioma.Decimal(r.Puntaje, 2))
+	}
+	return salidaOK
+}
+
+func cmdLibroActualizar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("libro actualizar")
+	libroID := flagID[LibroID](fs, "libro", "ID del libro")
+	titulo := fs.String("titulo", "", "título del libro")
+	autor := fs.String("autor", "", "autor del libro")
+	paginas := fs.Int("paginas", 0, "cantidad de páginas")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		libro, err := b.ActualizarLibro(*libroID, *titulo, *autor, *paginas)
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(libro)
+		} else {
+			idioma := ctx.idiomaPara(nil)
+			fmt.Fprintln(ctx.salida, idioma.Texto("libro.actualizado", libro.ObtenerInfoEn(idioma)))
+		}
+		return nil
+	})
+}
+
+func cmdLibroImportar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("libro importar")
+	archivo := fs.String("archivo", "", "catálogo en CSV o TSV con encabezado, MARC21 o Dublin Core")
+	prueba := fs.Bool("prueba", false, "validar sin modificar el catálogo")
+	formato := fs.String("formato", "csv", "csv, marc (ISO 2709), marcxml o dc (Dublin Core)")
+	separador := fs.String("separador", "", "coma o tab (por defecto se detecta)")
+	var columnas listaFlag
+	fs.Var(&columnas, "columna", "Encabezado=campo para columnas con otro nombre (repetible)")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	idioma := ctx.idiomaPara(nil)
+	if *archivo == "" {
+		fmt.Fprintln(ctx.errores, idioma.Texto("cli.falta_opcion", "--archivo"))
+		return salidaUsoCLI
+	}
+	opciones, err := nuevasOpcionesImportacion(*separador, columnas, *prueba)
+	if err == nil && *formato != "csv" {
+		err = ValidarFormato(FormatoRegistro(*formato))
+		if err == nil && (*separador != "" || len(columnas) > 0) {
+			err = errDatosInvalidos("error.opciones_solo_csv")
+		}
+	}
+	if err != nil {
+		fmt.Fprintln(ctx.errores, MensajeDeError(err, idioma))
+		return salidaUsoCLI
+	}
+
+	var reporte *ReporteImportacion
+	importar := func(b *Biblioteca) error {
+		f, err := os.Open(*archivo)
+		if err != nil {
+			return errArchivo(err, "error.archivo_leer", *archivo)
+		}
+		defer f.Close()
+		if *formato == "csv" {
+			reporte, err = b.ImportarCSV(f, opciones)
+		} else {
+			reporte, err = b.ImportarRegistros(f, FormatoRegistro(*formato), opciones.Prueba)
+		}
+		return err
+	}
+
+	// en modo prueba no se guarda nada
+	if opciones.Prueba {
+		b, err := ctx.cargar()
+		if err != nil {
+			return ctx.fallar(err)
+		}
+		if err := importar(b); err != nil {
+			return ctx.fallar(err)
+		}
+	} else if codigo := ctx.mutar(importar); codigo != salidaOK {
+		return codigo
+	}
+
+	reporte.Traducir(idioma)
+	if ctx.json {
+		ctx.imprimirJSON(reporte)
+	} else {
+		// los mensajes de cada fila nombran la fila del CSV o el registro
+		unidad := "importacion.fila"
+		if *formato != "csv" {
+			unidad = "importacion.registro"
+		}
+		for _, fila := range reporte.Resultados {
+			switch {
+			case fila.Error != "":
+				fmt.Fprintln(ctx.salida, idioma.Texto(ClaveMensaje(unidad+".error"), fila.Fila, fila.Error))
+			case reporte.Prueba:
+				fmt.Fprintln(ctx.salida, idioma.Texto(ClaveMensaje(unidad+".valida"), fila.Fila, fila.Titulo))
+			default:
+				fmt.Fprintln(ctx.salida, idioma.Texto(ClaveMensaje(unidad+".importada"), fila.Fila, fila.LibroID, fila.Titulo))
+			}
+		}
+		if len(reporte.ColumnasIgnoradas) > 0 {
+			fmt.Fprintln(ctx.salida, idioma.Texto("importacion.columnas_ignoradas", strings.Join(reporte.ColumnasIgnoradas, ", ")))
+		}
+		resumen := ClaveMensaje("importacion.resumen")
+		if reporte.Prueba {
+			resumen = "importacion.resumen_prueba"
+		}
+		fmt.Fprintln(ctx.salida, idioma.Plural(resumen, reporte.Filas, reporte.Importados, reporte.Filas, reporte.Fallidos))
+	}
+	if reporte.Fallidos > 0 {
+		return salidaError
+	}
+	return salidaOK
+}
+
+func cmdLibroExportar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("libro exportar")
+	formato := fs.String("formato", "", "marc (ISO 2709), marcxml o dc (Dublin Core)")
+	libroID := flagID[LibroID](fs, "libro", "ID del libro (por defecto todo el catálogo)")
+	archivo := fs.String("archivo", "", "archivo de salida (por defecto la salida estándar)")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	if err := ValidarFormato(FormatoRegistro(*formato)); err != nil {
+		fmt.Fprintln(ctx.errores, MensajeDeError(err, ctx.idiomaPara(nil)))
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+
+	salida := ctx.salida
+	if *archivo != "" {
+		f, err := os.Create(*archivo)
+		if err != nil {
+			return ctx.fallar(errArchivo(err, "error.archivo_crear", *archivo))
+		}
+		defer f.Close()
+		salida = f
+	}
+
+	if *libroID != 0 {
+		err = b.ExportarLibro(salida, *libroID, FormatoRegistro(*formato))
+	} else {
+		err = b.ExportarCatalogo(salida, FormatoRegistro(*formato))
+	}
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	if *archivo != "" && !ctx.json {
+		fmt.Fprintln(ctx.salida, ctx.idiomaPara(nil).Texto("libro.exportado", *archivo))
+	}
+	return salidaOK
+}
+
+func cmdEjemplarAgregar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("ejemplar agregar")
+	libroID := flagID[LibroID](fs, "libro", "ID del libro")
+	codigo := fs.String("codigo", "", "código de barras (se genera si falta)")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		ejemplar, err := b.AgregarEjemplar(*libroID, *codigo)
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(ejemplar)
+		} else {
+			idioma := ctx.idiomaPara(nil)
+			fmt.Fprintln(ctx.salida, idioma.Texto("ejemplar.agregado",
+				ejemplar.ID, ejemplar.CodigoBarras, b.BuscarLibro(*libroID).DisponibilidadEn(idioma)))
+		}
+		return nil
+	})
+}
+
+func cmdUsuarioRegistrar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("usuario registrar")
+	nombre := fs.String("nombre", "", "nombre del usuario")
+	email := fs.String("email", "", "email del usuario")
+	telefono := fs.String("telefono", "", "teléfono del usuario")
+	categoria := fs.String("categoria", string(CategoriaPublico), "estudiante, docente o publico")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		usuario, err := b.RegistrarUsuario(*nombre, *email, *telefono, CategoriaUsuario(*categoria))
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(usuario)
+		} else {
+			idioma := ctx.idiomaPara(usuario)
+			fmt.Fprintln(ctx.salida, idioma.Texto("usuario.registrado", usuario.ID, usuario.ObtenerResumenEn(idioma)))
+		}
+		return nil
+	})
+}
+
+func cmdUsuarioCategoria(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("usuario categoria")
+	usuarioID := flagID[UsuarioID](fs, "usuario", "ID del usuario")
+	categoria := fs.String("categoria", "", "estudiante, docente o publico")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		usuario, err := b.CambiarCategoria(*usuarioID, CategoriaUsuario(*categoria))
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(usuario)
+		} else {
+			idioma := ctx.idiomaPara(usuario)
+			fmt.Fprintln(ctx.salida, idioma.Texto("usuario.actualizado", usuario.ID, usuario.ObtenerResumenEn(idioma)))
+		}
+		return nil
+	})
+}
+
+func cmdUsuarioIdioma(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("usuario idioma")
+	usuarioID := flagID[UsuarioID](fs, "usuario", "ID del usuario")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	if ctx.idioma == "" {
+		fmt.Fprintln(ctx.errores, IdiomaPorDefecto.Texto("cli.falta_opcion", "--idioma"))
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		usuario, err := b.CambiarIdioma(*usuarioID, ctx.idioma)
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(usuario)
+		} else {
+			fmt.Fprintln(ctx.salida, ctx.idioma.Texto("usuario.actualizado", usuario.ID, usuario.ObtenerResumenEn(ctx.idioma)))
+		}
+		return nil
+	})
+}
+
+func cmdUsuarioRevisar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("usuario revisar")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		cambiados, err := b.RevisarSuspensiones(b.Ahora())
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(cambiados)
+			return nil
+		}
+		idioma := ctx.idiomaPara(nil)
+		for _, usuario := range cambiados {
+			if err := usuario.PuedePrestar(); err != nil {
+				fmt.Fprintf(ctx.salida, " 🚫 [%d] %s\n", usuario.ID, MensajeDeError(err, idioma))
+			} else {
+				fmt.Fprintf(ctx.salida, " ✅ [%d] %s\n", usuario.ID, usuario.ObtenerResumenEn(idioma))
+			}
+		}
+		fmt.Fprintln(ctx.salida, idioma.Plural("usuario.revisados", len(cambiados), len(cambiados)))
+		return nil
+	})
+}
+
+func cmdUsuarioListar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("usuario listar")
+	filtro := fs.String("filtro", "", "consulta estructurada, ej. activo:false email:*@gmail.com")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	usuarios, err := b.ConsultarUsuarios(*filtro)
+	if err != nil {
+		return ctx.fallar(err)
+	}
+
+	if ctx.json {
+		ctx.imprimirJSON(usuarios)
+		return salidaOK
+	}
+	idioma := ctx.idiomaPara(nil)
+	if len(usuarios) == 0 {
+		fmt.Fprintln(ctx.salida, idioma.Texto("usuario.ninguno"))
+	}
+	for _, usuario := range usuarios {
+		fmt.Fprintf(ctx.salida, " %s\n", usuario.ObtenerResumenEn(idioma))
+	}
+	return salidaOK
+}
+
+func cmdUsuarioHistorial(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("usuario historial")
+	usuarioID := flagID[UsuarioID](fs, "usuario", "ID del usuario")
+	leerFiltro := flagsHistorial(fs)
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	filtro, err := leerFiltro()
+	if err != nil {
+		fmt.Fprintln(ctx.errores, MensajeDeError(err, ctx.idiomaPara(nil)))
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	historial, err := b.HistorialUsuario(*usuarioID, filtro)
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	return ctx.imprimirHistorial(historial)
+}
+
+func cmdLibroHistorial(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("libro historial")
+	libroID := flagID[LibroID](fs, "libro", "ID del libro")
+	leerFiltro := flagsHistorial(fs)
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	filtro, err := leerFiltro()
+	if err != nil {
+		fmt.Fprintln(ctx.errores, MensajeDeError(err, ctx.idiomaPara(nil)))
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	historial, err := b.HistorialLibro(*libroID, filtro)
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	return ctx.imprimirHistorial(historial)
+}
+
+// flagsHistorial define las opciones de rango y página de un historial
+// La función retornada arma el filtro después de parsear
+func flagsHistorial(fs *flag.FlagSet) func() (FiltroHistorial, error) {
+	desde := fs.String("desde", "", "préstamos desde esta fecha (AAAA-MM-DD)")
+	hasta := fs.String("hasta", "", "préstamos hasta esta fecha inclusive (AAAA-MM-DD)")
+	pagina := fs.Int("pagina", 1, "número de página")
+	porPagina := fs.Int("por-pagina", porPaginaPorDef, "préstamos por página")
+	return func() (FiltroHistorial, error) {
+		return nuevoFiltroHistorial(*desde, *hasta, *pagina, *porPagina)
+	}
+}
+
+func (ctx *contextoCLI) imprimirHistorial(historial *PaginaHistorial) int {
+	if ctx.json {
+		ctx.imprimirJSON(historial)
+		return salidaOK
+	}
+	idioma := ctx.idiomaPara(nil)
+	if historial.Total == 0 {
+		fmt.Fprintln(ctx.salida, idioma.Texto("historial.vacio"))
+		return salidaOK
+	}
+	for _, e := range historial.Entradas {
+		p := e.Prestamo
+		estado := txt("prestamo.estado.activo")
+		if p.Devuelto {
+			estado = txt("prestamo.estado.devuelto")
+			if !p.FechaDevuelto.IsZero() {
+				estado = txt("prestamo.estado.devuelto_el", idioma.Fecha(p.FechaDevuelto))
+			}
+		}
+		fmt.Fprint(ctx.salida, idioma.Plural("historial.prestamo", e.DiasPrestado,
+			p.ID, p.LibroID, p.UsuarioID, idioma.Fecha(p.FechaPrestamo), estado, e.DiasPrestado))
+		if e.DiasAtraso > 0 {
+			fmt.Fprint(ctx.salida, idioma.Plural("historial.atraso", e.DiasAtraso, e.DiasAtraso, monto(e.Multa)))
+		}
+		fmt.Fprintln(ctx.salida)
+	}
+	fmt.Fprintln(ctx.salida, idioma.Plural("historial.pagina", historial.Total,
+		historial.Pagina, historial.TotalPaginas(), historial.Total))
+	return salidaOK
+}
+
+func cmdPrestamoCrear(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("prestamo crear")
+	libroID := flagID[LibroID](fs, "libro", "ID del libro")
+	ejemplarID := flagID[EjemplarID](fs, "ejemplar", "ID del ejemplar")
+	usuarioID := flagID[UsuarioID](fs, "usuario", "ID del usuario")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		var prestamo *Prestamo
+		var err error
+		if *ejemplarID != 0 {
+			prestamo, err = b.PrestarEjemplar(*ejemplarID, *usuarioID)
+		} else {
+			prestamo, err = b.PrestarLibro(*libroID, *usuarioID)
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(prestamo)
+		} else {
+			idioma := ctx.idiomaPara(b.BuscarUsuario(prestamo.UsuarioID))
+			fmt.Fprintln(ctx.salida, idioma.Texto("prestamo.creado", prestamo.ID, idioma.Fecha(prestamo.FechaDevolucion)))
+		}
+		return nil
+	})
+}
+
+func cmdPrestamoDevolver(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("prestamo devolver")
+	libroID := flagID[LibroID](fs, "libro", "ID del libro")
+	ejemplarID := flagID[EjemplarID](fs, "ejemplar", "ID del ejemplar")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		var prestamo *Prestamo
+		var err error
+		if *ejemplarID != 0 {
+			prestamo, err = b.DevolverEjemplar(*ejemplarID)
+		} else {
+			prestamo, err = b.DevolverLibro(*libroID)
+		}
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(prestamo)
+		} else {
+			idioma := ctx.idiomaPara(b.BuscarUsuario(prestamo.UsuarioID))
+			fmt.Fprintln(ctx.salida, idioma.Texto("prestamo.devuelto", prestamo.ID))
+			if prestamo.Multa > 0 {
+				fmt.Fprintln(ctx.salida, idioma.Texto("prestamo.multa", idioma.Decimal(prestamo.Multa, 2)))
+			}
+		}
+		return nil
+	})
+}
+
+func cmdPrestamoRenovar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("prestamo renovar")
+	prestamoID := flagID[PrestamoID](fs, "prestamo", "ID del préstamo")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		prestamo, err := b.RenovarPrestamo(*prestamoID)
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(prestamo)
+		} else {
+			idioma := ctx.idiomaPara(b.BuscarUsuario(prestamo.UsuarioID))
+			fmt.Fprintln(ctx.salida, idioma.Plural("prestamo.renovado", prestamo.Renovaciones,
+				prestamo.ID, idioma.Fecha(prestamo.FechaDevolucion), prestamo.Renovaciones))
+		}
+		return nil
+	})
+}
+
+func cmdMultaPagar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("multa pagar")
+	prestamoID := flagID[PrestamoID](fs, "prestamo", "ID del préstamo")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		prestamo, err := b.PagarMulta(*prestamoID)
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(prestamo)
+		} else {
+			idioma := ctx.idiomaPara(b.BuscarUsuario(prestamo.UsuarioID))
+			fmt.Fprintln(ctx.salida, idioma.Texto("prestamo.multa_pagada", prestamo.ID, idioma.Decimal(prestamo.Multa, 2)))
+		}
+		return nil
+	})
+}
+
+func cmdPrestamoListar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("prestamo listar")
+	filtro := fs.String("filtro", "", "consulta estructurada, ej. vencido:true renovaciones>=2")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	prestamos, err := b.ConsultarPrestamos(*filtro, b.Ahora())
+	if err != nil {
+		return ctx.fallar(err)
+	}
+
+	if ctx.json {
+		ctx.imprimirJSON(prestamos)
+		return salidaOK
+	}
+	idioma := ctx.idiomaPara(nil)
+	if len(prestamos) == 0 {
+		fmt.Fprintln(ctx.salida, idioma.Texto("prestamo.ninguno"))
+	}
+	for _, p := range prestamos {
+		estado := txt("prestamo.estado.activo")
+		if p.Devuelto {
+			estado = txt("prestamo.estado.devuelto")
+		}
+		fmt.Fprintln(ctx.salida, idioma.Texto("prestamo.linea",
+			p.ID, p.LibroID, p.UsuarioID, estado, idioma.Fecha(p.FechaDevolucion)))
+	}
+	return salidaOK
+}
+
+func cmdPrestamoVencidos(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("prestamo vencidos")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	ahora := b.Ahora()
+	vencidos := b.PrestamosVencidos(ahora)
+
+	if ctx.json {
+		ctx.imprimirJSON(vencidos)
+		return salidaOK
+	}
+	idioma := ctx.idiomaPara(nil)
+	if len(vencidos) == 0 {
+		fmt.Fprintln(ctx.salida, idioma.Texto("prestamo.ninguno_vencido"))
+	}
+	for _, p := range vencidos {
+		dias, multa := b.CalcularAtraso(p, ahora)
+		fmt.Fprintln(ctx.salida, idioma.Plural("prestamo.vencido", dias,
+			p.ID, p.LibroID, p.UsuarioID, dias, monto(multa)))
+	}
+	return salidaOK
+}
+
+func cmdCalendarioVer(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("calendario ver")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	calendario := b.VerCalendario()
+
+	if ctx.json {
+		ctx.imprimirJSON(calendario)
+		return salidaOK
+	}
+	// la semana se muestra de lunes a domingo
+	idioma := ctx.idiomaPara(nil)
+	for i := 1; i <= 7; i++ {
+		dia := time.Weekday(i % 7)
+		horario := calendario.Semana[dia]
+		nombre := diaSemana(int(dia)).textoEn(idioma)
+		if horario.Cerrado() {
+			fmt.Fprintf(ctx.salida, " %-10s %s\n", nombre, idioma.Texto("calendario.cerrado"))
+		} else {
+			fmt.Fprintf(ctx.salida, " %-10s %s - %s\n", nombre, horario.Abre, horario.Cierra)
+		}
+	}
+	for _, feriado := range calendario.Feriados {
+		fmt.Fprintf(ctx.salida, " %-10s %s\n", idioma.Texto("calendario.feriado"), feriado)
+	}
+	return salidaOK
+}
+
+func cmdCalendarioCargar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("calendario cargar")
+	archivo := fs.String("archivo", "", "calendario en JSON (Semana y Feriados)")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	if *archivo == "" {
+		fmt.Fprintln(ctx.errores, ctx.idiomaPara(nil).Texto("cli.falta_opcion", "--archivo"))
+		return salidaUsoCLI
+	}
+
+	calendario, err := CargarCalendario(*archivo)
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	return ctx.mutar(func(b *Biblioteca) error {
+		if err := b.UsarCalendario(*calendario); err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(calendario)
+		} else {
+			fmt.Fprintln(ctx.salida, ctx.idiomaPara(nil).Plural("calendario.actualizado", len(calendario.Feriados), len(calendario.Feriados)))
+		}
+		return nil
+	})
+}
+
+func cmdPoliticaCargar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("politica cargar")
+	archivo := fs.String("archivo", "", "política en JSON (DiasPrestamo, MultaDiaria, ...)")
+	categoria := fs.String("categoria", "", "categoría a cambiar (vacía = usuarios sin categoría)")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	if *archivo == "" {
+		fmt.Fprintln(ctx.errores, ctx.idiomaPara(nil).Texto("cli.falta_opcion", "--archivo"))
+		return salidaUsoCLI
+	}
+
+	datos, err := os.ReadFile(*archivo)
+	if err != nil {
+		return ctx.fallar(errArchivo(err, "error.archivo_leer", *archivo))
+	}
+	var politica PoliticaPrestamo
+	if err := json.Unmarshal(datos, &politica); err != nil {
+		return ctx.fallar(datosInvalidos(err))
+	}
+	return ctx.mutar(func(b *Biblioteca) error {
+		if err := b.UsarPolitica(CategoriaUsuario(*categoria), politica); err != nil {
+			return err
+		}
+		idioma := ctx.idiomaPara(nil)
+		switch {
+		case ctx.json:
+			ctx.imprimirJSON(politica)
+		case *categoria == "":
+			fmt.Fprintln(ctx.salida, idioma.Texto("politica.actualizada_general"))
+		default:
+			fmt.Fprintln(ctx.salida, idioma.Texto("politica.actualizada", *categoria))
+		}
+		return nil
+	})
+}
+
+func cmdSimular(ctx *contextoCLI, args []string) int {
+	config := ConfigSimulacionPorDefecto()
+	fs := ctx.nuevoFlagSet("simular")
+	fs.IntVar(&config.Dias, "dias", config.Dias, "días a simular")
+	fs.Uint64Var(&config.Semilla, "semilla", config.Semilla, "semilla del generador aleatorio")
+	fs.IntVar(&config.Libros, "libros", config.Libros, "títulos del catálogo sintético")
+	fs.IntVar(&config.Usuarios, "usuarios", config.Usuarios, "usuarios sintéticos")
+	desde := fs.String("desde", config.Inicio.Format("2006-01-02"), "primer día simulado (AAAA-MM-DD)")
+	guardar := fs.String("guardar", "", "archivo donde guardar la biblioteca resultante")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+	inicio, err := time.ParseInLocation("2006-01-02", *desde, time.Local)
+	if err != nil {
+		fmt.Fprintln(ctx.errores, ctx.idiomaPara(nil).Texto("error.fecha_desde", *desde))
+		return salidaUsoCLI
+	}
+	config.Inicio = inicio
+
+	if *guardar != "" {
+		if err := verificarNuevo(*guardar); err != nil {
+			return ctx.fallar(err)
+		}
+	}
+	b, reporte, err := Simular(config)
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	if *guardar != "" {
+		if err := b.GuardarEn(*guardar); err != nil {
+			return ctx.fallar(err)
+		}
+	}
+
+	if ctx.json {
+		ctx.imprimirJSON(reporte)
+	} else {
+		fmt.Fprint(ctx.salida, reporte.TextoEn(ctx.idiomaPara(nil)))
+	}
+	return salidaOK
+}
+
+func cmdReservaCrear(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("reserva crear")
+	libroID := flagID[LibroID](fs, "libro", "ID del libro")
+	usuarioID := flagID[UsuarioID](fs, "usuario", "ID del usuario")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		reserva, err := b.ReservarLibro(*libroID, *usuarioID)
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(reserva)
+		} else {
+			idioma := ctx.idiomaPara(b.BuscarUsuario(reserva.UsuarioID))
+			fmt.Fprintln(ctx.salida, idioma.Texto("reserva.creada", reserva.ID, len(b.ColaReservas(*libroID))))
+		}
+		return nil
+	})
+}
+
+func cmdReservaCancelar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("reserva cancelar")
+	reservaID := flagID[ReservaID](fs, "reserva", "ID de la reserva")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		if err := b.CancelarReserva(*reservaID); err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(struct{ ID ReservaID }{*reservaID})
+		} else {
+			fmt.Fprintln(ctx.salida, ctx.idiomaPara(nil).Texto("reserva.cancelada", *reservaID))
+		}
+		return nil
+	})
+}
+
+func cmdReservaListar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("reserva listar")
+	libroID := flagID[LibroID](fs, "libro", "ID del libro")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	cola := b.ColaReservas(*libroID)
+
+	if ctx.json {
+		ctx.imprimirJSON(cola)
+		return salidaOK
+	}
+	idioma := ctx.idiomaPara(nil)
+	if len(cola) == 0 {
+		fmt.Fprintln(ctx.salida, idioma.Texto("reserva.ninguna"))
+	}
+	for i, r := range cola {
+		linea := idioma.Texto("reserva.linea", i+1, r.ID, r.UsuarioID, r.Estado)
+		if r.Estado == ReservaLista {
+			linea += idioma.Texto("reserva.retirar_antes", idioma.Fecha(r.FechaLimiteRetiro))
+		}
+		fmt.Fprintln(ctx.salida, linea)
+	}
+	return salidaOK
+}
+
+func cmdReservaVencer(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("reserva vencer")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	return ctx.mutar(func(b *Biblioteca) error {
+		vencidas, err := b.ProcesarReservasVencidas(b.Ahora())
+		if err != nil {
+			return err
+		}
+		if ctx.json {
+			ctx.imprimirJSON(vencidas)
+		} else {
+			fmt.Fprintln(ctx.salida, ctx.idiomaPara(nil).Plural("reserva.vencidas", len(vencidas), len(vencidas)))
+		}
+		return nil
+	})
+}
+
+func cmdEstadisticas(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("estadisticas")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	b, err := ctx.cargar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+	estadisticas := b.ObtenerEstadisticas()
+	if ctx.json {
+		ctx.imprimirJSON(estadisticas)
+	} else {
+		fmt.Fprint(ctx.salida, estadisticas.TextoEn(ctx.idiomaPara(nil)))
+	}
+	return salidaOK
+}
+
+func cmdVerificar(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("verificar")
+	reparar := fs.Bool("reparar", false, "corregir las inconsistencias que se puedan corregir")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	var reporte *ReporteConsistencia
+	verificar := func(b *Biblioteca) error {
+		var err error
+		reporte, err = b.VerificarConsistencia(*reparar)
+		return err
+	}
+	if *reparar {
+		if codigo := ctx.mutar(verificar); codigo != salidaOK {
+			return codigo
+		}
+	} else {
+		b, err := ctx.cargar()
+		if err != nil {
+			return ctx.fallar(err)
+		}
+		if err := verificar(b); err != nil {
+			return ctx.fallar(err)
+		}
+	}
+
+	idioma := ctx.idiomaPara(nil)
+	reporte.Traducir(idioma)
+	if ctx.json {
+		ctx.imprimirJSON(reporte)
+	} else {
+		for _, inc := range reporte.Inconsistencias {
+			marca := "⚠️ "
+			if inc.Reparada {
+				marca = "🔧"
+			}
+			fmt.Fprintf(ctx.salida, " %s [%s] %s\n", marca, inc.Tipo, inc.Detalle)
+		}
+		if reporte.Consistente() {
+			fmt.Fprintln(ctx.salida, idioma.Texto("verificar.consistente"))
+		} else {
+			fmt.Fprintln(ctx.salida, idioma.Plural("verificar.resumen", len(reporte.Inconsistencias),
+				len(reporte.Inconsistencias), reporte.Reparadas))
+		}
+	}
+	// quedan problemas sin resolver
+	if len(reporte.Inconsistencias) > reporte.Reparadas {
+		return salidaError
+	}
+	return salidaOK
+}
+
+func cmdServir(ctx *contextoCLI, args []string) int {
+	fs := ctx.nuevoFlagSet("servir")
+	addr := fs.String("addr", ":8080", "dirección donde escuchar")
+	if !ctx.parsear(fs, args) {
+		return salidaUsoCLI
+	}
+
+	// El servidor conserva los datos en memoria: mientras corre, los
+	// comandos que los modifican esperan y fallan en lugar de pisarlos
+	if err := ctx.bloquearDatos(0); err != nil {
+		return ctx.fallar(err)
+	}
+	b, err := ctx.cargarParaModificar()
+	if err != nil {
+		return ctx.fallar(err)
+	}
+
+	// Guardar el archivo de datos después de cada petición que modifica;
+	// Consolidar serializa los guardados de peticiones concurrentes
+	api := NuevoServidorAPI(b)
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.ServeHTTP(w, r)
+		if r.Method != http.MethodGet {
+			if err := b.Consolidar(ctx.datos); err != nil {
+				fmt.Fprintf(ctx.errores, "❌ %s\n", MensajeDeError(err, ctx.idiomaPara(nil)))
+			}
+		}
+	})
+
+	fmt.Fprintln(ctx.salida, ctx.idiomaPara(nil).Texto("servir.iniciado", b.Nombre, *addr))
+	if err := http.ListenAndServe(*addr, handler); err != nil {
+		return ctx.fallar(err)
+	}
+	return salidaOK
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+)
+
+// cliDePrueba ejecuta comandos sobre un archivo de datos temporal y retorna
+// el código de salida y lo escrito en la salida estándar
+func cliDePrueba(t *testing.T) func(args ...string) (int, []byte) {
+	t.Helper()
+	datos := filepath.Join(t.TempDir(), "biblioteca.json")
+	return func(args ...string) (int, []byte) {
+		t.Helper()
+		var salida, errores bytes.Buffer
+		codigo := ejecutarCLI(append(args, "--datos", datos), &salida, &errores)
+		return codigo, append(salida.Bytes(), errores.Bytes()...)
+	}
+}
+
+func TestCLIFlujoDePrestamoEnJSON(t *testing.T) {
+	ejecutar := cliDePrueba(t)
+
+	if codigo, salida := ejecutar("iniciar", "--nombre", "Central"); codigo != salidaOK {
+		t.Fatalf("iniciar: código %d: %s", codigo, salida)
+	}
+	codigo, salida := ejecutar("libro", "agregar", "--titulo", "Rayuela", "--autor", "Cortázar", "--paginas", "600", "--json")
+	var libro Libro
+	if err := json.Unmarshal(salida, &libro); codigo != salidaOK || err != nil || libro.Titulo != "Rayuela" {
+		t.Fatalf("libro agregar: código %d, %v: %s", codigo, err, salida)
+	}
+	codigo, salida = ejecutar("usuario", "registrar", "--nombre", "Ana", "--email", "ana@test", "--json")
+	var usuario Usuario
+	if err := json.Unmarshal(salida, &usuario); codigo != salidaOK || err != nil || usuario.Nombre != "Ana" {
+		t.Fatalf("usuario registrar: código %d, %v: %s", codigo, err, salida)
+	}
+
+	// los datos se guardan entre una ejecución y la siguiente
+	codigo, salida = ejecutar("prestamo", "crear", "--libro", "1", "--usuario", "1", "--json")
+	var prestamo Prestamo
+	if err := json.Unmarshal(salida, &prestamo); codigo != salidaOK || err != nil || prestamo.LibroID != libro.ID || prestamo.UsuarioID != usuario.ID {
+		t.Fatalf("prestamo crear: código %d, %v: %s", codigo, err, salida)
+	}
+	codigo, salida = ejecutar("estadisticas", "--json")
+	var est Estadisticas
+	if err := json.Unmarshal(salida, &est); codigo != salidaOK || err != nil || est.TotalLibros != 1 || est.PrestamosActivos != 1 {
+		t.Errorf("estadisticas: código %d, %v: %s", codigo, err, salida)
+	}
+	codigo, salida = ejecutar("prestamo", "devolver", "--libro", "1", "--json")
+	if err := json.Unmarshal(salida, &prestamo); codigo != salidaOK || err != nil || !prestamo.Devuelto {
+		t.Errorf("prestamo devolver: código %d, %v: %s", codigo, err, salida)
+	}
+}
+
+func TestCLICodigosDeSalida(t *testing.T) {
+	ejecutar := cliDePrueba(t)
+	ejecutar("iniciar", "--nombre", "Central")
+	ejecutar("libro", "agregar", "--titulo", "Rayuela", "--autor", "Cortázar", "--paginas", "600")
+	ejecutar("usuario", "registrar", "--nombre", "Ana", "--email", "ana@test")
+	ejecutar("usuario", "registrar", "--nombre", "Beto", "--email", "beto@test")
+	if codigo, salida := ejecutar("prestamo", "crear", "--libro", "1", "--usuario", "1"); codigo != salidaOK {
+		t.Fatalf("prestamo crear: código %d: %s", codigo, salida)
+	}
+
+	casos := []struct {
+		args   []string
+		codigo int
+	}{
+		{[]string{"prestamo", "crear", "--libro", "1", "--usuario", "2"}, salidaConflicto},
+		{[]string{"usuario", "registrar", "--nombre", "Otra", "--email", "ana@test"}, salidaConflicto},
+		{[]string{"prestamo", "devolver", "--libro", "99"}, salidaNoEncontrado},
+		{[]string{"prestamo", "crear", "--libro", "1", "--usuario", "99"}, salidaNoEncontrado},
+		{[]string{"libro", "agregar", "--titulo", "", "--autor", "X"}, salidaUsoCLI},
+		{[]string{"libro", "agregar", "--paginas", "muchas"}, salidaUsoCLI},
+		{[]string{"libro", "prestar"}, salidaUsoCLI},
+	}
+	for _, c := range casos {
+		if codigo, salida := ejecutar(c.args...); codigo != c.codigo {
+			t.Errorf("%v: código %d, se esperaba %d: %s", c.args, codigo, c.codigo, salida)
+		}
+	}
+
+	// con --json el error también sale en JSON, con su código
+	codigo, salida := ejecutar("prestamo", "devolver", "--libro", "99", "--json")
+	var respuesta respuestaError
+	if err := json.Unmarshal(salida, &respuesta); codigo != salidaNoEncontrado || err != nil || respuesta.Codigo != "libro_no_encontrado" {
+		t.Errorf("Error en JSON inesperado: código %d, %v: %s", codigo, err, salida)
+	}
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestPrestamosConcurrentes presta y devuelve desde muchas goroutines y
+// verifica que ningún libro quede prestado dos veces a la vez
+// Correr con go test -race
+func TestPrestamosConcurrentes(t *testing.T) {
+	const (
+		libros      = 5
+		goroutines  = 50
+		iteraciones = 100
+	)
+
+	b := NuevaBiblioteca("Concurrente", "")
+	ids := make([]LibroID, libros)
+	for i := range ids {
+		libro, err := b.AgregarLibro(fmt.Sprintf("Libro %d", i), "Autor", "", 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids[i] = libro.ID
+	}
+	usuarios := make([]UsuarioID, goroutines)
+	for i := range usuarios {
+		usuario, err := b.RegistrarUsuario(fmt.Sprintf("Usuario %d", i), fmt.Sprintf("u%d@test", i), "", CategoriaDocente)
+		if err != nil {
+			t.Fatal(err)
+		}
+		usuarios[i] = usuario.ID
+	}
+
+	// prestados cuenta, por libro, cuántos préstamos activos ve el test
+	var prestados [libros]atomic.Int32
+	var prestamosOK, devolucionesOK atomic.Int32
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < iteraciones; i++ {
+				n := (g + i) % libros
+				if _, err := b.PrestarLibro(ids[n], usuarios[g]); err == nil {
+					prestamosOK.Add(1)
+					if activos := prestados[n].Add(1); activos > 1 {
+						t.Errorf("El libro %d tiene %d préstamos activos", ids[n], activos)
+					}
+					// se descuenta antes de devolver: después del Devolver otra
+					// goroutine ya puede prestarlo
+					prestados[n].Add(-1)
+					if _, err := b.DevolverLibro(ids[n]); err != nil {
+						t.Errorf("No se pudo devolver el libro %d: %v", ids[n], err)
+						return
+					}
+					devolucionesOK.Add(1)
+				}
+				// lecturas en paralelo con las escrituras
+				b.BuscarLibro(ids[n])
+				b.ListarPrestamos()
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	if prestamosOK.Load() == 0 {
+		t.Fatal("No se realizó ningún préstamo")
+	}
+	if prestamosOK.Load() != devolucionesOK.Load() {
+		t.Errorf("%d préstamos y %d devoluciones", prestamosOK.Load(), devolucionesOK.Load())
+	}
+
+	// el estado final también debe ser coherente
+	activosPorEjemplar := make(map[EjemplarID]int)
+	for _, prestamo := range b.ListarPrestamos() {
+		if !prestamo.Devuelto {
+			activosPorEjemplar[prestamo.EjemplarID]++
+		}
+	}
+	for ejemplar, activos := range activosPorEjemplar {
+		t.Errorf("El ejemplar %d quedó con %d préstamos activos", ejemplar, activos)
+	}
+	for _, libro := range b.ListarLibros() {
+		if libro.Disponibles() != len(libro.Ejemplares) {
+			t.Errorf("El libro %d quedó con %s", libro.ID, libro.Disponibilidad())
+		}
+	}
+}
+
+// TestGuardadosConcurrentes guarda desde muchas goroutines mientras se
+// agregan libros: el archivo debe terminar con el estado más reciente
+func TestGuardadosConcurrentes(t *testing.T) {
+	const goroutines = 20
+	b := NuevaBiblioteca("Concurrente", "")
+	path := filepath.Join(t.TempDir(), "biblioteca.json")
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := b.AgregarLibro(fmt.Sprintf("Libro %d", i), "Autor", "", 100); err != nil {
+				t.Error(err)
+			}
+			if err := b.GuardarEn(path); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	guardada, err := CargarDesde(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(guardada.Libros) != goroutines {
+		t.Errorf("El archivo tiene %d libros, se esperaban %d", len(guardada.Libros), goroutines)
+	}
+}
+
+// TestComandosConcurrentes corre comandos de la CLI a la vez sobre el
+// mismo archivo, como dos procesos: ninguno debe perder lo que agregó otro
+func TestComandosConcurrentes(t *testing.T) {
+	const goroutines = 8
+	datos := filepath.Join(t.TempDir(), "biblioteca.json")
+	if codigo := ejecutarCLI([]string{"iniciar", "--nombre", "Central", "--datos", datos}, io.Discard, io.Discard); codigo != salidaOK {
+		t.Fatalf("iniciar terminó con %d", codigo)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			args := []string{"libro", "agregar", "--titulo", "Libro " + strconv.Itoa(i), "--autor", "Autor",
+				"--paginas", "100", "--datos", datos}
+			if codigo := ejecutarCLI(args, io.Discard, io.Discard); codigo != salidaOK {
+				t.Errorf("libro agregar terminó con %d", codigo)
+			}
+		}()
+	}
+	wg.Wait()
+
+	b, err := CargarDesde(datos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Libros) != goroutines {
+		t.Errorf("El archivo tiene %d libros, se esperaban %d", len(b.Libros), goroutines)
+	}
+}
+
+func TestBloqueoDeDatosOcupado(t *testing.T) {
+	datos := filepath.Join(t.TempDir(), "biblioteca.json")
+	servidor := &contextoCLI{datos: datos}
+	if err := servidor.bloquearDatos(0); err != nil {
+		t.Fatal(err)
+	}
+	defer servidor.liberar()
+
+	otro := &contextoCLI{datos: datos}
+	if err := otro.bloquearDatos(0); !errors.Is(err, ErrDatosEnUso) {
+		t.Fatalf("Se esperaba ErrDatosEnUso, se obtuvo %v", err)
+	}
+	servidor.liberar()
+	if err := otro.bloquearDatos(0); err != nil {
+		t.Errorf("El bloqueo liberado debería poder tomarse: %v", err)
+	}
+	otro.liberar()
+}
@@ -0,0 +1,396 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+// ==========================================
+// CONSISTENCIA: INVARIANTES ENTRE LIBROS, PRÉSTAMOS Y RESERVAS
+// ==========================================
+
+// TipoInconsistencia identifica qué invariante no se cumple
+type TipoInconsistencia string
+
+const (
+	InconsistenciaPrestamoHuerfano    TipoInconsistencia = "prestamo_huerfano"     // préstamo con libro, usuario o ejemplar inexistente
+	InconsistenciaPrestamoDuplicado   TipoInconsistencia = "prestamo_duplicado"    // más de un préstamo activo para un ejemplar
+	InconsistenciaReservaHuerfana     TipoInconsistencia = "reserva_huerfana"      // reserva activa con libro o usuario inexistente
+	InconsistenciaReservaSinApartado  TipoInconsistencia = "reserva_sin_apartado"  // reserva lista cuyo ejemplar no está apartado
+	InconsistenciaEjemplarSinPrestamo TipoInconsistencia = "ejemplar_sin_prestamo" // ejemplar marcado prestado sin préstamo activo
+	InconsistenciaPrestamoSinMarca    TipoInconsistencia = "prestamo_sin_marca"    // préstamo activo con el ejemplar sin marcar
+	InconsistenciaApartadoSinReserva  TipoInconsistencia = "apartado_sin_reserva"  // ejemplar apartado sin reserva lista
+	InconsistenciaSecuencia           TipoInconsistencia = "secuencia_atrasada"    // la secuencia repetiría un ID existente
+	InconsistenciaIndice              TipoInconsistencia = "indice_desactualizado" // el índice de préstamos activos no coincide
+)
+
+// Inconsistencia describe una violación encontrada
+// Los IDs que no aplican quedan en cero
+// Detalle está en IdiomaPorDefecto; ReporteConsistencia.Traducir lo cambia
+type Inconsistencia struct {
+	Tipo       TipoInconsistencia
+	Detalle    string
+	LibroID    LibroID
+	EjemplarID EjemplarID
+	PrestamoID PrestamoID
+	ReservaID  ReservaID
+	Reparada   bool
+	detalle    texto
+}
+
+// ReporteConsistencia es el resultado de VerificarConsistencia
+type ReporteConsistencia struct {
+	Reparar         bool
+	Inconsistencias []Inconsistencia
+	Reparadas       int
+}
+
+// Consistente indica si no se encontró ninguna inconsistencia
+func (r ReporteConsistencia) Consistente() bool {
+	return len(r.Inconsistencias) == 0
+}
+
+// Traducir pasa el detalle de cada inconsistencia al idioma indicado
+func (r *ReporteConsistencia) Traducir(idioma Idioma) {
+	for i := range r.Inconsistencias {
+		r.Inconsistencias[i].Detalle = r.Inconsistencias[i].detalle.textoEn(idioma)
+	}
+}
+
+// verificador acumula las inconsistencias y las entidades corregidas
+type verificador struct {
+	b         *Biblioteca
+	reparar   bool
+	reporte   *ReporteConsistencia
+	libros    map[LibroID]bool
+	prestamos map[PrestamoID]bool
+	reservas  map[ReservaID]bool
+}
+
+// VerificarConsistencia revisa que el estado de los ejemplares coincida con
+// los préstamos y reservas, y que no haya referencias a entidades inexistentes
+// Con reparar corrige lo que se puede corregir sin perder información,
+// tomando los préstamos como fuente de verdad, y registra cada entidad
+// corregida en el journal. Los préstamos ya devueltos con referencias rotas
+// solo se informan: son historia y no afectan la disponibilidad
+func (b *Biblioteca) VerificarConsistencia(reparar bool) (*ReporteConsistencia, error) {
+	if reparar {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+	} else {
+		b.mu.RLock()
+		defer b.mu.RUnlock()
+	}
+
+	v := &verificador{
+		b:         b,
+		reparar:   reparar,
+		reporte:   &ReporteConsistencia{Reparar: reparar, Inconsistencias: make([]Inconsistencia, 0)},
+		libros:    make(map[LibroID]bool),
+		prestamos: make(map[PrestamoID]bool),
+		reservas:  make(map[ReservaID]bool),
+	}
+
+	// el orden importa: los índices se comparan antes de cualquier reparación,
+	// cerrar un préstamo huérfano libera su ejemplar en el paso de ejemplares
+	// y cancelar una reserva libera su apartado
+	v.verificarIndices()
+	v.verificarPrestamos()
+	v.verificarReservas()
+	liberados := v.verificarEjemplares()
+	v.verificarSecuencias()
+
+	if !reparar || v.reporte.Reparadas == 0 {
+		return v.reporte, nil
+	}
+	b.reconstruirIndices()
+	if err := v.registrarReparaciones(); err != nil {
+		return v.reporte, err
+	}
+
+	// los ejemplares liberados atienden primero a quien espera el título
+	for _, ejemplarID := range liberados {
+		libro, ejemplar := b.buscarEjemplar(ejemplarID)
+		if err := b.apartarParaSiguiente(libro, ejemplar, b.ahora()); err != nil {
+			return v.reporte, err
+		}
+	}
+	return v.reporte, nil
+}
+
+// agregar anota una inconsistencia; reparar indica si se corrigió
+func (v *verificador) agregar(inconsistencia Inconsistencia, reparada bool) {
+	inconsistencia.Reparada = reparada
+	inconsistencia.Detalle = inconsistencia.detalle.textoEn(IdiomaPorDefecto)
+	if reparada {
+		v.reporte.Reparadas++
+	}
+	v.reporte.Inconsistencias = append(v.reporte.Inconsistencias, inconsistencia)
+}
+
+// verificarPrestamos busca préstamos huérfanos y ejemplares con más de un
+// préstamo activo. La reparación cierra el préstamo sobrante
+func (v *verificador) verificarPrestamos() {
+	b := v.b
+	activoDe := make(map[EjemplarID]*Prestamo)
+	for i := range b.Prestamos {
+		prestamo := &b.Prestamos[i]
+		inc := Inconsistencia{LibroID: prestamo.LibroID, EjemplarID: prestamo.EjemplarID, PrestamoID: prestamo.ID}
+
+		if faltan := v.referenciasRotas(prestamo); len(faltan) > 0 {
+			inc.Tipo = InconsistenciaPrestamoHuerfano
+			inc.detalle = txt("consistencia.prestamo_huerfano", prestamo.ID, faltan)
+			if prestamo.Devuelto {
+				inc.detalle = txt("consistencia.prestamo_huerfano_devuelto", prestamo.ID, faltan)
+			}
+			v.agregar(inc, !prestamo.Devuelto && v.cerrarPrestamo(prestamo, b.ahora()))
+			continue
+		}
+		if prestamo.Devuelto {
+			continue
+		}
+
+		anterior, duplicado := activoDe[prestamo.EjemplarID]
+		if !duplicado {
+			activoDe[prestamo.EjemplarID] = prestamo
+			continue
+		}
+		// se conserva el préstamo más reciente: el ejemplar tuvo que volver
+		// antes de prestarse de nuevo
+		if prestamo.FechaPrestamo.Before(anterior.FechaPrestamo) {
+			anterior, prestamo = prestamo, anterior
+		}
+		activoDe[prestamo.EjemplarID] = prestamo
+		inc.PrestamoID = anterior.ID
+		inc.Tipo = InconsistenciaPrestamoDuplicado
+		inc.detalle = txt("consistencia.prestamo_duplicado", prestamo.EjemplarID, anterior.ID, prestamo.ID)
+		v.agregar(inc, v.cerrarPrestamo(anterior, prestamo.FechaPrestamo))
+	}
+}
+
+// referenciasRotas lista las entidades inexistentes a las que apunta un préstamo
+func (v *verificador) referenciasRotas(prestamo *Prestamo) listaTextos {
+	b := v.b
+	var faltan listaTextos
+	if b.buscarLibro(prestamo.LibroID) == nil {
+		faltan = append(faltan, txt("consistencia.libro_inexistente", prestamo.LibroID))
+	}
+	if b.buscarUsuario(prestamo.UsuarioID) == nil {
+		faltan = append(faltan, txt("consistencia.usuario_inexistente", prestamo.UsuarioID))
+	}
+	if libro, _ := b.buscarEjemplar(prestamo.EjemplarID); libro == nil {
+		faltan = append(faltan, txt("consistencia.ejemplar_inexistente", prestamo.EjemplarID))
+	} else if libro.ID != prestamo.LibroID {
+		faltan = append(faltan, txt("consistencia.ejemplar_ajeno", prestamo.EjemplarID, libro.ID))
+	}
+	return faltan
+}
+
+// cerrarPrestamo marca un préstamo como devuelto en la fecha indicada, sin
+// multa. Solo modifica el estado si se está reparando
+func (v *verificador) cerrarPrestamo(prestamo *Prestamo, fecha time.Time) bool {
+	if !v.reparar {
+		return false
+	}
+	prestamo.Devuelto = true
+	prestamo.FechaDevuelto = fecha
+	v.prestamos[prestamo.ID] = true
+	return true
+}
+
+// verificarReservas busca reservas activas con referencias rotas y reservas
+// listas cuyo ejemplar no está apartado para el usuario
+func (v *verificador) verificarReservas() {
+	b := v.b
+	for i := range b.Reservas {
+		reserva := &b.Reservas[i]
+		if !reserva.EstaActiva() {
+			continue
+		}
+		inc := Inconsistencia{LibroID: reserva.LibroID, EjemplarID: reserva.EjemplarID, ReservaID: reserva.ID}
+
+		if b.buscarLibro(reserva.LibroID) == nil || b.buscarUsuario(reserva.UsuarioID) == nil {
+			inc.Tipo = InconsistenciaReservaHuerfana
+			inc.detalle = txt("consistencia.reserva_huerfana", reserva.ID, reserva.LibroID, reserva.UsuarioID)
+			if v.reparar {
+				reserva.Estado = ReservaCancelada
+				v.reservas[reserva.ID] = true
+			}
+			v.agregar(inc, v.reparar)
+			continue
+		}
+		if reserva.Estado != ReservaLista {
+			continue
+		}
+
+		libro, ejemplar := b.buscarEjemplar(reserva.EjemplarID)
+		if ejemplar != nil && libro.ID == reserva.LibroID && ejemplar.ReservadoPara == reserva.UsuarioID && !ejemplar.Prestado {
+			continue
+		}
+		// vuelve a la cola; si hay un ejemplar libre se le apartará
+		inc.Tipo = InconsistenciaReservaSinApartado
+		inc.detalle = txt("consistencia.reserva_sin_apartado", reserva.ID, reserva.EjemplarID, reserva.UsuarioID)
+		if v.reparar {
+			reserva.Estado = ReservaPendiente
+			reserva.EjemplarID = 0
+			reserva.FechaLimiteRetiro = time.Time{}
+			v.reservas[reserva.ID] = true
+		}
+		v.agregar(inc, v.reparar)
+	}
+}
+
+// verificarEjemplares compara la marca Prestado y el apartado de cada
+// ejemplar con los préstamos activos y las reservas listas
+// Retorna los ejemplares que la reparación dejó libres
+func (v *verificador) verificarEjemplares() []EjemplarID {
+	b := v.b
+	activo := make(map[EjemplarID]PrestamoID)
+	for _, prestamo := range b.Prestamos {
+		if !prestamo.Devuelto {
+			activo[prestamo.EjemplarID] = prestamo.ID
+		}
+	}
+	apartadoPara := make(map[EjemplarID]UsuarioID)
+	for _, reserva := range b.Reservas {
+		if reserva.Estado == ReservaLista {
+			apartadoPara[reserva.EjemplarID] = reserva.UsuarioID
+		}
+	}
+
+	var liberados []EjemplarID
+	for i := range b.Libros {
+		libro := &b.Libros[i]
+		for j := range libro.Ejemplares {
+			ejemplar := &libro.Ejemplares[j]
+			inc := Inconsistencia{LibroID: libro.ID, EjemplarID: ejemplar.ID}
+			prestamoID, prestado := activo[ejemplar.ID]
+
+			switch {
+			case ejemplar.Prestado && !prestado:
+				inc.Tipo = InconsistenciaEjemplarSinPrestamo
+				inc.detalle = txt("consistencia.ejemplar_sin_prestamo", ejemplar.CodigoBarras, libro.Titulo)
+				if v.reparar {
+					ejemplar.Prestado = false
+					liberados = append(liberados, ejemplar.ID)
+					v.libros[libro.ID] = true
+				}
+				v.agregar(inc, v.reparar)
+			case !ejemplar.Prestado && prestado:
+				inc.Tipo = InconsistenciaPrestamoSinMarca
+				inc.PrestamoID = prestamoID
+				inc.detalle = txt("consistencia.prestamo_sin_marca", ejemplar.CodigoBarras, libro.Titulo, prestamoID)
+				if v.reparar {
+					ejemplar.Prestado = true
+					v.libros[libro.ID] = true
+				}
+				v.agregar(inc, v.reparar)
+			}
+
+			if ejemplar.ReservadoPara != 0 && apartadoPara[ejemplar.ID] != ejemplar.ReservadoPara {
+				inc.Tipo = InconsistenciaApartadoSinReserva
+				inc.PrestamoID = 0
+				inc.detalle = txt("consistencia.apartado_sin_reserva", ejemplar.CodigoBarras, libro.Titulo, ejemplar.ReservadoPara)
+				if v.reparar {
+					ejemplar.ReservadoPara = 0
+					if !ejemplar.Prestado {
+						liberados = append(liberados, ejemplar.ID)
+					}
+					v.libros[libro.ID] = true
+				}
+				v.agregar(inc, v.reparar)
+			}
+		}
+	}
+	return liberados
+}
+
+// verificarSecuencias comprueba que ninguna secuencia repita un ID existente
+func (v *verificador) verificarSecuencias() {
+	// se calculan sobre una biblioteca temporal para no modificar el estado
+	// cuando solo se tiene el lock de lectura
+	b := v.b
+	temporal := &Biblioteca{
+		Libros:     b.Libros,
+		Usuarios:   b.Usuarios,
+		Prestamos:  b.Prestamos,
+		Reservas:   b.Reservas,
+		secuencias: b.secuencias,
+	}
+	temporal.ajustarSecuencias()
+	antes, ajustadas := b.secuencias, temporal.secuencias
+	if ajustadas != antes {
+		if v.reparar {
+			b.secuencias = ajustadas
+		}
+		v.agregar(Inconsistencia{
+			Tipo:    InconsistenciaSecuencia,
+			detalle: txt("consistencia.secuencias", fmt.Sprintf("%+v", antes), fmt.Sprintf("%+v", ajustadas)),
+		}, v.reparar)
+	}
+}
+
+// verificarIndices compara el índice de préstamos activos con los préstamos
+// Los ejemplares con préstamos duplicados se informan aparte y no cuentan
+// La reparación reconstruye todos los índices
+func (v *verificador) verificarIndices() {
+	b := v.b
+	activos := make(map[EjemplarID][]PrestamoID)
+	for _, prestamo := range b.Prestamos {
+		if !prestamo.Devuelto {
+			activos[prestamo.EjemplarID] = append(activos[prestamo.EjemplarID], prestamo.ID)
+		}
+	}
+	desactualizado := false
+	for ejemplarID, prestamoID := range b.indices.prestamoActivo {
+		if len(activos[ejemplarID]) == 0 {
+			desactualizado = true
+		}
+		if len(activos[ejemplarID]) == 1 && activos[ejemplarID][0] != prestamoID {
+			desactualizado = true
+		}
+	}
+	for ejemplarID := range activos {
+		if _, ok := b.indices.prestamoActivo[ejemplarID]; !ok {
+			desactualizado = true
+		}
+	}
+	if desactualizado {
+		v.agregar(Inconsistencia{
+			Tipo:    InconsistenciaIndice,
+			detalle: txt("consistencia.indice"),
+		}, v.reparar)
+	}
+}
+
+// registrarReparaciones escribe en el journal cada entidad corregida
+func (v *verificador) registrarReparaciones() error {
+	b := v.b
+	for _, prestamo := range b.Prestamos {
+		if v.prestamos[prestamo.ID] {
+			if err := b.registrarEvento(Evento{Tipo: EventoEstadoReparado, Prestamo: &prestamo}); err != nil {
+				return err
+			}
+		}
+	}
+	for _, reserva := range b.Reservas {
+		if v.reservas[reserva.ID] {
+			if err := b.registrarEvento(Evento{Tipo: EventoEstadoReparado, Reserva: &reserva}); err != nil {
+				return err
+			}
+		}
+	}
+	for i := range b.Libros {
+		if v.libros[b.Libros[i].ID] {
+			if err := b.registrarEvento(Evento{Tipo: EventoEstadoReparado, Libro: &b.Libros[i]}); err != nil {
+				return err
+			}
+		}
+	}
+	// las secuencias viajan en cada evento; si solo se corrigieron ellas
+	// hace falta un evento propio
+	if len(v.prestamos)+len(v.reservas)+len(v.libros) == 0 {
+		return b.registrarEvento(Evento{Tipo: EventoEstadoReparado})
+	}
+	return nil
+}
@@ -0,0 +1,652 @@
+package main
+
+import (
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ==========================================
+// LENGUAJE DE CONSULTAS PARA BIBLIOTECARIOS
+// ==========================================
+//
+// Ejemplos:
+//   autor:"Márquez" paginas>300 estado:disponible
+//   activo:false email:*@gmail.com
+//   vencido:true OR (renovaciones>=2 -devuelto:true)
+//
+// Las condiciones separadas por espacios se combinan con AND; también se
+// admiten OR, NOT (o el prefijo -) y paréntesis. Una palabra sin campo
+// busca en todos los campos de texto.
+
+// ErrorConsulta indica dónde falló el análisis de una consulta
+// Pos es la columna (desde 1) del carácter problemático
+type ErrorConsulta struct {
+	Pos     int
+	mensaje texto
+}
+
+func (e *ErrorConsulta) Error() string {
+	return e.textoEn(IdiomaPorDefecto)
+}
+
+func (e *ErrorConsulta) textoEn(idioma Idioma) string {
+	return idioma.Texto("consulta.error", e.Pos, e.mensaje)
+}
+
+// Is hace que una consulta mal formada cuente como ErrDatosInvalidos
+func (e *ErrorConsulta) Is(objetivo error) bool {
+	return objetivo == ErrDatosInvalidos
+}
+
+// ExprConsulta es un nodo del árbol de una consulta
+type ExprConsulta interface {
+	Posicion() int
+}
+
+// ExprY se cumple si se cumplen ambas expresiones
+type ExprY struct {
+	Izq, Der ExprConsulta
+}
+
+// ExprO se cumple si se cumple alguna de las expresiones
+type ExprO struct {
+	Izq, Der ExprConsulta
+}
+
+// ExprNo niega una expresión
+type ExprNo struct {
+	Expr ExprConsulta
+	Pos  int
+}
+
+// ExprCondicion compara un campo con un valor (campo OP valor)
+type ExprCondicion struct {
+	Campo    string
+	Operador string
+	Valor    string
+	Pos      int // posición del campo
+	PosValor int
+}
+
+// ExprTexto es una palabra o frase suelta que se busca en los campos de texto
+type ExprTexto struct {
+	Valor string
+	Pos   int
+}
+
+func (e ExprY) Posicion() int         { return e.Izq.Posicion() }
+func (e ExprO) Posicion() int         { return e.Izq.Posicion() }
+func (e ExprNo) Posicion() int        { return e.Pos }
+func (e ExprCondicion) Posicion() int { return e.Pos }
+func (e ExprTexto) Posicion() int     { return e.Pos }
+
+// ParsearConsulta convierte el texto de una consulta en su árbol
+// Una consulta vacía retorna nil (coincide con todo)
+func ParsearConsulta(texto string) (ExprConsulta, error) {
+	tokens, err := lexConsulta(texto)
+	if err != nil {
+		return nil, err
+	}
+	p := &parserConsulta{tokens: tokens}
+	if p.actual().tipo == tokFin {
+		return nil, nil
+	}
+
+	expr, err := p.disyuncion()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.actual(); t.tipo != tokFin {
+		return nil, &ErrorConsulta{Pos: t.pos, mensaje: txt("consulta.no_se_esperaba", t)}
+	}
+	return expr, nil
+}
+
+// ==========================================
+// ANÁLISIS LÉXICO
+// ==========================================
+
+type tipoToken int
+
+const (
+	tokFin tipoToken = iota
+	tokPalabra
+	tokCadena
+	tokOperador
+	tokAbre
+	tokCierra
+	tokMenos
+)
+
+type tokenConsulta struct {
+	tipo  tipoToken
+	texto string
+	pos   int
+}
+
+// textoEn muestra el token en los mensajes de error
+func (t tokenConsulta) textoEn(idioma Idioma) string {
+	if t.tipo == tokFin {
+		return idioma.Texto("consulta.fin")
+	}
+	return t.texto
+}
+
+// esSeparador indica los caracteres que terminan una palabra
+func esSeparador(r rune) bool {
+	return unicode.IsSpace(r) || strings.ContainsRune(`():<>=!"`, r)
+}
+
+func lexConsulta(texto string) ([]tokenConsulta, error) {
+	runas := []rune(texto)
+	tokens := make([]tokenConsulta, 0)
+
+	for i := 0; i < len(runas); {
+		r := runas[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, tokenConsulta{tokAbre, "(", pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, tokenConsulta{tokCierra, ")", pos})
+			i++
+		case r == '"':
+			fin := i + 1
+			for fin < len(runas) && runas[fin] != '"' {
+				fin++
+			}
+			if fin == len(runas) {
+				return nil, &ErrorConsulta{Pos: pos, mensaje: txt("consulta.comillas_sin_cerrar")}
+			}
+			tokens = append(tokens, tokenConsulta{tokCadena, string(runas[i+1 : fin]), pos})
+			i = fin + 1
+		case r == ':' || r == '=':
+			tokens = append(tokens, tokenConsulta{tokOperador, string(r), pos})
+			i++
+		case r == '<' || r == '>' || r == '!':
+			op := string(r)
+			if i+1 < len(runas) && runas[i+1] == '=' {
+				op += "="
+			}
+			if op == "!" {
+				return nil, &ErrorConsulta{Pos: pos, mensaje: txt("consulta.se_esperaba_distinto")}
+			}
+			tokens = append(tokens, tokenConsulta{tokOperador, op, pos})
+			i += len(op)
+		case r == '-' && i+1 < len(runas) && !unicode.IsSpace(runas[i+1]) &&
+			(i == 0 || unicode.IsSpace(runas[i-1]) || runas[i-1] == '('):
+			tokens = append(tokens, tokenConsulta{tokMenos, "-", pos})
+			i++
+		default:
+			fin := i
+			for fin < len(runas) && !esSeparador(runas[fin]) {
+				fin++
+			}
+			tokens = append(tokens, tokenConsulta{tokPalabra, string(runas[i:fin]), pos})
+			i = fin
+		}
+	}
+	return append(tokens, tokenConsulta{tokFin, "fin de la consulta", len(runas) + 1}), nil
+}
+
+// ==========================================
+// ANÁLISIS SINTÁCTICO
+// ==========================================
+//
+//   disyuncion := conjuncion ("OR" conjuncion)*
+//   conjuncion := unario (["AND"] unario)*
+//   unario     := ("-" | "NOT") unario | primario
+//   primario   := "(" disyuncion ")" | PALABRA OP valor | PALABRA | CADENA
+
+type parserConsulta struct {
+	tokens []tokenConsulta
+	i      int
+}
+
+func (p *parserConsulta) actual() tokenConsulta {
+	return p.tokens[p.i]
+}
+
+func (p *parserConsulta) avanzar() tokenConsulta {
+	t := p.tokens[p.i]
+	if p.i < len(p.tokens)-1 {
+		p.i++
+	}
+	return t
+}
+
+func (p *parserConsulta) esPalabraClave(clave string) bool {
+	t := p.actual()
+	return t.tipo == tokPalabra && t.texto == clave
+}
+
+func (p *parserConsulta) disyuncion() (ExprConsulta, error) {
+	izq, err := p.conjuncion()
+	if err != nil {
+		return nil, err
+	}
+	for p.esPalabraClave("OR") {
+		p.avanzar()
+		der, err := p.conjuncion()
+		if err != nil {
+			return nil, err
+		}
+		izq = ExprO{Izq: izq, Der: der}
+	}
+	return izq, nil
+}
+
+func (p *parserConsulta) conjuncion() (ExprConsulta, error) {
+	izq, err := p.unario()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		if p.esPalabraClave("AND") {
+			p.avanzar()
+		} else if t := p.actual(); t.tipo == tokFin || t.tipo == tokCierra || p.esPalabraClave("OR") {
+			return izq, nil
+		}
+		der, err := p.unario()
+		if err != nil {
+			return nil, err
+		}
+		izq = ExprY{Izq: izq, Der: der}
+	}
+}
+
+func (p *parserConsulta) unario() (ExprConsulta, error) {
+	if t := p.actual(); t.tipo == tokMenos || p.esPalabraClave("NOT") {
+		p.avanzar()
+		expr, err := p.unario()
+		if err != nil {
+			return nil, err
+		}
+		return ExprNo{Expr: expr, Pos: t.pos}, nil
+	}
+	return p.primario()
+}
+
+func (p *parserConsulta) primario() (ExprConsulta, error) {
+	t := p.actual()
+	switch t.tipo {
+	case tokAbre:
+		p.avanzar()
+		expr, err := p.disyuncion()
+		if err != nil {
+			return nil, err
+		}
+		if cierre := p.actual(); cierre.tipo != tokCierra {
+			return nil, &ErrorConsulta{Pos: cierre.pos,
+				mensaje: txt("consulta.falta_parentesis", t.pos)}
+		}
+		p.avanzar()
+		return expr, nil
+	case tokCadena:
+		p.avanzar()
+		return ExprTexto{Valor: t.texto, Pos: t.pos}, nil
+	case tokPalabra:
+		p.avanzar()
+		if op := p.actual(); op.tipo == tokOperador {
+			p.avanzar()
+			valor := p.actual()
+			if valor.tipo != tokPalabra && valor.tipo != tokCadena {
+				return nil, &ErrorConsulta{Pos: valor.pos,
+					mensaje: txt("consulta.falta_valor", t.texto, op.texto)}
+			}
+			p.avanzar()
+			return ExprCondicion{
+				Campo:    strings.ToLower(t.texto),
+				Operador: op.texto,
+				Valor:    valor.texto,
+				Pos:      t.pos,
+				PosValor: valor.pos,
+			}, nil
+		}
+		return ExprTexto{Valor: t.texto, Pos: t.pos}, nil
+	case tokOperador:
+		return nil, &ErrorConsulta{Pos: t.pos, mensaje: txt("consulta.falta_campo", t.texto)}
+	default:
+		return nil, &ErrorConsulta{Pos: t.pos, mensaje: txt("consulta.no_se_esperaba", t)}
+	}
+}
+
+// ==========================================
+// EVALUACIÓN
+// ==========================================
+
+// tipoCampo indica cómo se compara un campo
+type tipoCampo int
+
+const (
+	campoTexto tipoCampo = iota
+	campoNumero
+	campoBooleano
+	campoFecha
+)
+
+// campoConsulta describe un campo consultable de una entidad
+type campoConsulta[T any] struct {
+	tipo  tipoCampo
+	valor func(T) any
+}
+
+// esquemaConsulta son los campos consultables de una entidad
+type esquemaConsulta[T any] map[string]campoConsulta[T]
+
+// filtroConsulta es una consulta ya validada contra un esquema
+type filtroConsulta[T any] func(T) bool
+
+// compilarConsulta valida la consulta contra el esquema y retorna el filtro
+// Los campos desconocidos y los valores inválidos se informan con su posición
+func compilarConsulta[T any](expr ExprConsulta, esquema esquemaConsulta[T]) (filtroConsulta[T], error) {
+	switch e := expr.(type) {
+	case nil:
+		return func(T) bool { return true }, nil
+	case ExprY:
+		izq, err := compilarConsulta(e.Izq, esquema)
+		if err != nil {
+			return nil, err
+		}
+		der, err := compilarConsulta(e.Der, esquema)
+		if err != nil {
+			return nil, err
+		}
+		return func(x T) bool { return izq(x) && der(x) }, nil
+	case ExprO:
+		izq, err := compilarConsulta(e.Izq, esquema)
+		if err != nil {
+			return nil, err
+		}
+		der, err := compilarConsulta(e.Der, esquema)
+		if err != nil {
+			return nil, err
+		}
+		return func(x T) bool { return izq(x) || der(x) }, nil
+	case ExprNo:
+		interior, err := compilarConsulta(e.Expr, esquema)
+		if err != nil {
+			return nil, err
+		}
+		return func(x T) bool { return !interior(x) }, nil
+	case ExprTexto:
+		buscado := normalizarTexto(e.Valor)
+		return func(x T) bool {
+			for _, campo := range esquema {
+				if campo.tipo != campoTexto {
+					continue
+				}
+				if strings.Contains(normalizarTexto(campo.valor(x).(string)), buscado) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case ExprCondicion:
+		campo, ok := esquema[e.Campo]
+		if !ok {
+			return nil, &ErrorConsulta{Pos: e.Pos,
+				mensaje: txt("consulta.campo_desconocido", e.Campo, esquema.nombres())}
+		}
+		return compilarCondicion(e, campo)
+	}
+	return nil, &ErrorConsulta{Pos: expr.Posicion(), mensaje: txt("consulta.expresion_no_soportada")}
+}
+
+// compilarCondicion convierte el valor de la condición según el tipo del campo
+func compilarCondicion[T any](e ExprCondicion, campo campoConsulta[T]) (filtroConsulta[T], error) {
+	errValor := func(esperado ClaveMensaje) error {
+		return &ErrorConsulta{Pos: e.PosValor,
+			mensaje: txt("consulta.valor_invalido", e.Campo, txt(esperado), e.Valor)}
+	}
+	errOperador := func() error {
+		return &ErrorConsulta{Pos: e.PosValor - len([]rune(e.Operador)),
+			mensaje: txt("consulta.operador_invalido", e.Operador, e.Campo)}
+	}
+
+	switch campo.tipo {
+	case campoTexto:
+		buscado := normalizarTexto(e.Valor)
+		comodin := strings.ContainsAny(buscado, "*?")
+		switch e.Operador {
+		case ":":
+			return func(x T) bool {
+				valor := normalizarTexto(campo.valor(x).(string))
+				if comodin {
+					ok, _ := path.Match(buscado, valor)
+					return ok
+				}
+				return strings.Contains(valor, buscado)
+			}, nil
+		case "=":
+			return func(x T) bool { return normalizarTexto(campo.valor(x).(string)) == buscado }, nil
+		case "!=":
+			return func(x T) bool { return normalizarTexto(campo.valor(x).(string)) != buscado }, nil
+		}
+		return nil, errOperador()
+
+	case campoNumero:
+		buscado, err := strconv.ParseFloat(e.Valor, 64)
+		if err != nil {
+			return nil, errValor("consulta.tipo_numero")
+		}
+		comparar, ok := comparadores[e.Operador]
+		if !ok {
+			return nil, errOperador()
+		}
+		return func(x T) bool {
+			return comparar(compararNumeros(numeroDe(campo.valor(x)), buscado))
+		}, nil
+
+	case campoBooleano:
+		var buscado bool
+		switch strings.ToLower(e.Valor) {
+		case "true", "si", "sí":
+			buscado = true
+		case "false", "no":
+			buscado = false
+		default:
+			return nil, errValor("consulta.tipo_booleano")
+		}
+		switch e.Operador {
+		case ":", "=":
+			return func(x T) bool { return campo.valor(x).(bool) == buscado }, nil
+		case "!=":
+			return func(x T) bool { return campo.valor(x).(bool) != buscado }, nil
+		}
+		return nil, errOperador()
+
+	case campoFecha:
+		buscado, err := time.Parse("2006-01-02", e.Valor)
+		if err != nil {
+			return nil, errValor("consulta.tipo_fecha")
+		}
+		comparar, ok := comparadores[e.Operador]
+		if !ok {
+			return nil, errOperador()
+		}
+		return func(x T) bool {
+			fecha := campo.valor(x).(time.Time)
+			dia := time.Date(fecha.Year(), fecha.Month(), fecha.Day(), 0, 0, 0, 0, time.UTC)
+			return comparar(dia.Compare(buscado))
+		}, nil
+	}
+	return nil, errOperador()
+}
+
+// comparadores interpreta el resultado de una comparación (-1, 0, 1)
+var comparadores = map[string]func(int) bool{
+	":":  func(c int) bool { return c == 0 },
+	"=":  func(c int) bool { return c == 0 },
+	"!=": func(c int) bool { return c != 0 },
+	">":  func(c int) bool { return c > 0 },
+	"<":  func(c int) bool { return c < 0 },
+	">=": func(c int) bool { return c >= 0 },
+	"<=": func(c int) bool { return c <= 0 },
+}
+
+func numeroDe(valor any) float64 {
+	switch v := valor.(type) {
+	case int:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+func compararNumeros(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+// nombres lista los campos del esquema para los mensajes de error
+func (esquema esquemaConsulta[T]) nombres() string {
+	nombres := make([]string, 0, len(esquema))
+	for nombre := range esquema {
+		nombres = append(nombres, nombre)
+	}
+	sort.Strings(nombres)
+	return strings.Join(nombres, ", ")
+}
+
+// ==========================================
+// ESQUEMAS Y BÚSQUEDAS POR ENTIDAD
+// ==========================================
+
+// esquemaLibro son los campos consultables de un Libro
+var esquemaLibro = esquemaConsulta[Libro]{
+	"id":          {campoNumero, func(l Libro) any { return int(l.ID) }},
+	"titulo":      {campoTexto, func(l Libro) any { return l.Titulo }},
+	"autor":       {campoTexto, func(l Libro) any { return l.Autor }},
+	"isbn":        {campoTexto, func(l Libro) any { return l.ISBN }},
+	"paginas":     {campoNumero, func(l Libro) any { return l.Paginas }},
+	"ejemplares":  {campoNumero, func(l Libro) any { return len(l.Ejemplares) }},
+	"disponibles": {campoNumero, func(l Libro) any { return l.Disponibles() }},
+	"estado":      {campoTexto, func(l Libro) any { return estadoConsultaLibro(l) }},
+}
+
+// estadoConsultaLibro resume la disponibilidad de un libro en una palabra
+func estadoConsultaLibro(l Libro) string {
+	switch {
+	case len(l.Ejemplares) == 0:
+		return "sin_ejemplares"
+	case l.Disponibles() > 0:
+		return "disponible"
+	}
+	return "prestado"
+}
+
+// esquemaUsuario son los campos consultables de un Usuario
+var esquemaUsuario = esquemaConsulta[Usuario]{
+	"id":         {campoNumero, func(u Usuario) any { return int(u.ID) }},
+	"nombre":     {campoTexto, func(u Usuario) any { return u.Nombre }},
+	"email":      {campoTexto, func(u Usuario) any { return u.Email }},
+	"telefono":   {campoTexto, func(u Usuario) any { return u.Telefono }},
+	"activo":     {campoBooleano, func(u Usuario) any { return u.Activo }},
+	"categoria":  {campoTexto, func(u Usuario) any { return string(u.Categoria) }},
+	"suspendido": {campoBooleano, func(u Usuario) any { return u.Suspension != nil }},
+}
+
+// prestamoConsultado es un préstamo junto al momento de la consulta, para
+// los campos que dependen de la fecha actual (vencido, atraso)
+type prestamoConsultado struct {
+	Prestamo
+	ahora  time.Time
+	atraso int // días de atención de atraso según el calendario
+}
+
+// esquemaPrestamo son los campos consultables de un Prestamo
+var esquemaPrestamo = esquemaConsulta[prestamoConsultado]{
+	"id":           {campoNumero, func(p prestamoConsultado) any { return int(p.ID) }},
+	"libro":        {campoNumero, func(p prestamoConsultado) any { return int(p.LibroID) }},
+	"usuario":      {campoNumero, func(p prestamoConsultado) any { return int(p.UsuarioID) }},
+	"ejemplar":     {campoNumero, func(p prestamoConsultado) any { return int(p.EjemplarID) }},
+	"devuelto":     {campoBooleano, func(p prestamoConsultado) any { return p.Devuelto }},
+	"vencido":      {campoBooleano, func(p prestamoConsultado) any { return p.EstaVencido(p.ahora) }},
+	"atraso":       {campoNumero, func(p prestamoConsultado) any { return p.atraso }},
+	"renovaciones": {campoNumero, func(p prestamoConsultado) any { return p.Renovaciones }},
+	"multa":        {campoNumero, func(p prestamoConsultado) any { return p.Multa }},
+	"pagada":       {campoBooleano, func(p prestamoConsultado) any { return p.MultaPagada }},
+	"fecha":        {campoFecha, func(p prestamoConsultado) any { return p.FechaPrestamo }},
+	"vence":        {campoFecha, func(p prestamoConsultado) any { return p.FechaDevolucion }},
+}
+
+// ConsultarLibros retorna los libros que cumplen la consulta
+func (b *Biblioteca) ConsultarLibros(consulta string) ([]Libro, error) {
+	filtro, err := prepararConsulta(consulta, esquemaLibro)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	libros := make([]Libro, 0)
+	for _, libro := range b.Libros {
+		if filtro(libro) {
+			libros = append(libros, libro.copiar())
+		}
+	}
+	return libros, nil
+}
+
+// ConsultarUsuarios retorna los usuarios que cumplen la consulta
+func (b *Biblioteca) ConsultarUsuarios(consulta string) ([]Usuario, error) {
+	filtro, err := prepararConsulta(consulta, esquemaUsuario)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	usuarios := make([]Usuario, 0)
+	for _, usuario := range b.Usuarios {
+		if filtro(usuario) {
+			usuarios = append(usuarios, usuario)
+		}
+	}
+	return usuarios, nil
+}
+
+// ConsultarPrestamos retorna los préstamos que cumplen la consulta
+// ahora se usa para los campos vencido y atraso
+func (b *Biblioteca) ConsultarPrestamos(consulta string, ahora time.Time) ([]Prestamo, error) {
+	filtro, err := prepararConsulta(consulta, esquemaPrestamo)
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	prestamos := make([]Prestamo, 0)
+	for _, prestamo := range b.Prestamos {
+		consultado := prestamoConsultado{Prestamo: prestamo, ahora: ahora, atraso: b.diasAtraso(prestamo, ahora)}
+		if filtro(consultado) {
+			prestamos = append(prestamos, prestamo)
+		}
+	}
+	return prestamos, nil
+}
+
+func prepararConsulta[T any](consulta string, esquema esquemaConsulta[T]) (filtroConsulta[T], error) {
+	expr, err := ParsearConsulta(consulta)
+	if err != nil {
+		return nil, err
+	}
+	return compilarConsulta(expr, esquema)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+// bibliotecaParaConsultas arma un catálogo chico con un préstamo de Ficciones
+func bibliotecaParaConsultas(t *testing.T) (*Biblioteca, *RelojVirtual) {
+	t.Helper()
+	reloj := NuevoRelojVirtual(time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+	for _, l := range []struct {
+		titulo, autor string
+		paginas       int
+	}{
+		{"Cien años de soledad", "García Márquez", 471},
+		{"El amor en los tiempos del cólera", "García Márquez", 368},
+		{"Ficciones", "Borges", 224},
+		{"Rayuela", "Cortázar", 600},
+	} {
+		if _, err := b.AgregarLibro(l.titulo, l.autor, "", l.paginas); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ana, _ := b.RegistrarUsuario("Ana", "ana@gmail.com", "", CategoriaEstudiante)
+	if _, err := b.RegistrarUsuario("Beto", "beto@yahoo.com", "", CategoriaDocente); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PrestarLibro(3, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+	return b, reloj
+}
+
+func titulos(libros []Libro) []string {
+	resultado := make([]string, 0, len(libros))
+	for _, libro := range libros {
+		resultado = append(resultado, libro.Titulo)
+	}
+	return resultado
+}
+
+func TestConsultarLibros(t *testing.T) {
+	b, _ := bibliotecaParaConsultas(t)
+	cien, amor, ficciones, rayuela := "Cien años de soledad", "El amor en los tiempos del cólera", "Ficciones", "Rayuela"
+
+	casos := []struct {
+		consulta string
+		esperado []string
+	}{
+		{"", []string{cien, amor, ficciones, rayuela}},
+		{"rayuela", []string{rayuela}},
+		{`autor:"marquez"`, []string{cien, amor}},
+		{"titulo:colera", []string{amor}},
+		{"autor=borges", []string{ficciones}},
+		{"autor!=borges", []string{cien, amor, rayuela}},
+		// números
+		{"paginas>300", []string{cien, amor, rayuela}},
+		{"paginas>=368 paginas<=471", []string{cien, amor}},
+		{"paginas=224", []string{ficciones}},
+		{"paginas!=224 paginas<400", []string{amor}},
+		// AND liga más fuerte que OR; los paréntesis lo cambian
+		{"borges OR cortazar paginas>500", []string{ficciones, rayuela}},
+		{"(borges OR cortazar) paginas>500", []string{rayuela}},
+		{"borges OR cortazar AND paginas<500", []string{ficciones}},
+		{"-autor:marquez", []string{ficciones, rayuela}},
+		{"NOT autor:marquez paginas>300", []string{rayuela}},
+		{"NOT (autor:marquez OR autor:borges)", []string{rayuela}},
+		// comodines
+		{"titulo:el*", []string{amor}},
+		{"titulo:?icciones", []string{ficciones}},
+		{"titulo:*amor*", []string{amor}},
+		{"titulo:ray", []string{rayuela}},
+		// campos calculados
+		{"estado:prestado", []string{ficciones}},
+		{"disponibles>0 autor:borges", []string{}},
+	}
+	for _, c := range casos {
+		libros, err := b.ConsultarLibros(c.consulta)
+		if err != nil {
+			t.Errorf("%q: error inesperado %v", c.consulta, err)
+			continue
+		}
+		if obtenido := titulos(libros); !slices.Equal(obtenido, c.esperado) {
+			t.Errorf("%q: se obtuvo %v, se esperaba %v", c.consulta, obtenido, c.esperado)
+		}
+	}
+}
+
+func TestConsultarUsuariosYPrestamos(t *testing.T) {
+	b, reloj := bibliotecaParaConsultas(t)
+
+	usuarios, err := b.ConsultarUsuarios("email:*@gmail.com OR categoria:docente")
+	if err != nil || len(usuarios) != 2 {
+		t.Errorf("Se esperaban los dos usuarios: %v %v", usuarios, err)
+	}
+	if usuarios, err = b.ConsultarUsuarios("activo:true -email:*@gmail.com"); err != nil || len(usuarios) != 1 || usuarios[0].Nombre != "Beto" {
+		t.Errorf("Se esperaba solo a Beto: %v %v", usuarios, err)
+	}
+
+	reloj.Avanzar(30 * 24 * time.Hour)
+	casos := []struct {
+		consulta string
+		cantidad int
+	}{
+		{"vencido:true", 1},
+		{"vencido:false", 0},
+		{"atraso>10 devuelto:false", 1},
+		{"fecha=2026-03-02", 1},
+		{"fecha>=2026-03-03", 0},
+		{"fecha<2026-03-02", 0},
+		{"vence>2026-03-02 vence<2026-04-01", 1},
+	}
+	for _, c := range casos {
+		prestamos, err := b.ConsultarPrestamos(c.consulta, b.Ahora())
+		if err != nil || len(prestamos) != c.cantidad {
+			t.Errorf("%q: se obtuvieron %d préstamos (%v), se esperaban %d", c.consulta, len(prestamos), err, c.cantidad)
+		}
+	}
+}
+
+func TestConsultaInvalidaIndicaLaPosicion(t *testing.T) {
+	b, _ := bibliotecaParaConsultas(t)
+
+	casos := []struct {
+		consulta  string
+		consultar func(string) error
+		pos       int
+	}{
+		{`autor:"marquez`, consultaDeLibros(b), 7},
+		{"paginas>abc", consultaDeLibros(b), 9},
+		{"paginas!3", consultaDeLibros(b), 8},
+		{"borges)", consultaDeLibros(b), 7},
+		{"(borges", consultaDeLibros(b), 8},
+		{"color:rojo", consultaDeLibros(b), 1},
+		{"rayuela autor>3", consultaDeLibros(b), 14},
+		{"autor:", consultaDeLibros(b), 7},
+		{"activo:quizas", func(c string) error { _, err := b.ConsultarUsuarios(c); return err }, 8},
+		{"fecha>2026-13-01", func(c string) error { _, err := b.ConsultarPrestamos(c, b.Ahora()); return err }, 7},
+	}
+	for _, c := range casos {
+		err := c.consultar(c.consulta)
+		if !errors.Is(err, ErrDatosInvalidos) {
+			t.Errorf("%q: se esperaba un error de datos, se obtuvo %v", c.consulta, err)
+			continue
+		}
+		var errConsulta *ErrorConsulta
+		if !errors.As(err, &errConsulta) {
+			t.Errorf("%q: se esperaba un ErrorConsulta, se obtuvo %T", c.consulta, err)
+		} else if errConsulta.Pos != c.pos {
+			t.Errorf("%q: error en la posición %d, se esperaba %d (%v)", c.consulta, errConsulta.Pos, c.pos, err)
+		}
+	}
+}
+
+// consultaDeLibros adapta ConsultarLibros para la tabla de consultas inválidas
+func consultaDeLibros(b *Biblioteca) func(string) error {
+	return func(consulta string) error {
+		_, err := b.ConsultarLibros(consulta)
+		return err
+	}
+}
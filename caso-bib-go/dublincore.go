@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ==========================================
+// DUBLIN CORE (oai_dc)
+// ==========================================
+
+// Namespaces de oai_dc
+const (
+	espacioOAIDC = "http://www.openarchives.org/OAI/2.0/oai_dc/"
+	espacioDC    = "http://purl.org/dc/elements/1.1/"
+)
+
+// Prefijos de dc:identifier que usa el catálogo
+const (
+	prefijoISBN    = "urn:isbn:"
+	prefijoLibroID = "libro:" // ID local, para que la conversión sea reversible
+)
+
+// RegistroDC es un registro Dublin Core simple en el formato oai_dc
+// Cada elemento es repetible; un libro usa title, creator, identifier,
+// format (extensión en páginas) y type
+type RegistroDC struct {
+	XMLName         xml.Name `xml:"http://www.openarchives.org/OAI/2.0/oai_dc/ dc"`
+	Titulos         []string `xml:"http://purl.org/dc/elements/1.1/ title"`
+	Creadores       []string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Identificadores []string `xml:"http://purl.org/dc/elements/1.1/ identifier"`
+	Formatos        []string `xml:"http://purl.org/dc/elements/1.1/ format"`
+	Tipos           []string `xml:"http://purl.org/dc/elements/1.1/ type"`
+}
+
+// Al escribir se usan los prefijos habituales (oai_dc:dc, dc:title, ...);
+// encoding/xml solo los produce con nombres literales, así que la salida
+// tiene su propia estructura
+type (
+	coleccionDC struct {
+		XMLName   xml.Name   `xml:"registros"`
+		OAIDC     string     `xml:"xmlns:oai_dc,attr"`
+		DC        string     `xml:"xmlns:dc,attr"`
+		Registros []salidaDC `xml:"oai_dc:dc"`
+	}
+	salidaDC struct {
+		Titulos         []string `xml:"dc:title"`
+		Creadores       []string `xml:"dc:creator"`
+		Identificadores []string `xml:"dc:identifier"`
+		Formatos        []string `xml:"dc:format"`
+		Tipos           []string `xml:"dc:type"`
+	}
+)
+
+// DCDeLibro convierte un libro en un registro Dublin Core
+func DCDeLibro(libro Libro) RegistroDC {
+	registro := RegistroDC{
+		Titulos:         []string{libro.Titulo},
+		Creadores:       []string{libro.Autor},
+		Identificadores: []string{prefijoLibroID + strconv.Itoa(int(libro.ID))},
+		Tipos:           []string{"Text"},
+	}
+	if libro.ISBN != "" {
+		registro.Identificadores = append(registro.Identificadores, prefijoISBN+libro.ISBN)
+	}
+	if libro.Paginas > 0 {
+		registro.Formatos = []string{fmt.Sprintf("%d p.", libro.Paginas)}
+	}
+	return registro
+}
+
+// LibroDeDC extrae los datos de un libro de un registro Dublin Core
+// Toma el primer título y creador; reconoce ISBN como "urn:isbn:..." o
+// "ISBN ..." y las páginas del primer formato que empiece con un número
+func LibroDeDC(registro RegistroDC) Libro {
+	var libro Libro
+	if len(registro.Titulos) > 0 {
+		libro.Titulo = strings.TrimSpace(registro.Titulos[0])
+	}
+	if len(registro.Creadores) > 0 {
+		libro.Autor = strings.TrimSpace(registro.Creadores[0])
+	}
+	for _, identificador := range registro.Identificadores {
+		identificador = strings.TrimSpace(identificador)
+		minusculas := strings.ToLower(identificador)
+		switch {
+		case strings.HasPrefix(minusculas, prefijoISBN) && libro.ISBN == "":
+			libro.ISBN = identificador[len(prefijoISBN):]
+		case strings.HasPrefix(minusculas, "isbn") && libro.ISBN == "":
+			libro.ISBN = strings.TrimSpace(strings.TrimLeft(identificador[len("isbn"):], ": "))
+		case strings.HasPrefix(minusculas, prefijoLibroID):
+			if id, err := strconv.Atoi(identificador[len(prefijoLibroID):]); err == nil {
+				libro.ID = LibroID(id)
+			}
+		}
+	}
+	for _, formato := range registro.Formatos {
+		formato = strings.TrimSpace(formato)
+		if formato != "" && formato[0] >= '0' && formato[0] <= '9' {
+			libro.Paginas = primerNumero(formato)
+			break
+		}
+	}
+	return libro
+}
+
+// EscribirDublinCore escribe los registros dentro de un elemento <registros>
+func EscribirDublinCore(w io.Writer, registros []RegistroDC) error {
+	coleccion := coleccionDC{OAIDC: espacioOAIDC, DC: espacioDC, Registros: make([]salidaDC, 0, len(registros))}
+	for _, registro := range registros {
+		coleccion.Registros = append(coleccion.Registros, salidaDC{
+			Titulos:         registro.Titulos,
+			Creadores:       registro.Creadores,
+			Identificadores: registro.Identificadores,
+			Formatos:        registro.Formatos,
+			Tipos:           registro.Tipos,
+		})
+	}
+	return escribirXML(w, coleccion)
+}
+
+// LeerDublinCore lee todos los <oai_dc:dc> de r, estén agrupados o sueltos
+func LeerDublinCore(r io.Reader) ([]RegistroDC, error) {
+	registros := make([]RegistroDC, 0)
+	err := recorrerXML(r, "dc", func(decoder *xml.Decoder, inicio xml.StartElement) error {
+		var registro RegistroDC
+		if err := decoder.DecodeElement(&registro, &inicio); err != nil {
+			return err
+		}
+		registros = append(registros, registro)
+		return nil
+	})
+	if err != nil {
+		return nil, errFormato(err, "error.dc_invalido")
+	}
+	return registros, nil
+}
@@ -0,0 +1,190 @@
+package main
+
+import "fmt"
+
+// ==========================================
+// EJEMPLARES: COPIAS FÍSICAS DE UN TÍTULO
+// ==========================================
+
+// Ejemplar es una copia física de un libro con su propio código de barras
+type Ejemplar struct {
+	ID           EjemplarID
+	CodigoBarras string
+	Prestado     bool
+	// ReservadoPara es el usuario para quien la copia está apartada (0 = nadie)
+	ReservadoPara UsuarioID
+}
+
+// EstaDisponible indica si el ejemplar se puede prestar a cualquier usuario
+// Usa receptor de VALOR porque solo LEE
+func (e Ejemplar) EstaDisponible() bool {
+	return !e.Prestado && e.ReservadoPara == 0
+}
+
+// copiar retorna una copia del libro que no comparte el slice de ejemplares
+func (l Libro) copiar() Libro {
+	l.Ejemplares = append([]Ejemplar(nil), l.Ejemplares...)
+	return l
+}
+
+// buscarEjemplar retorna un puntero al ejemplar del libro con ese ID
+func (l *Libro) buscarEjemplar(id EjemplarID) *Ejemplar {
+	for i := range l.Ejemplares {
+		if l.Ejemplares[i].ID == id {
+			return &l.Ejemplares[i]
+		}
+	}
+	return nil
+}
+
+// ejemplarDisponible retorna el primer ejemplar libre del libro
+func (l *Libro) ejemplarDisponible() *Ejemplar {
+	for i := range l.Ejemplares {
+		if l.Ejemplares[i].EstaDisponible() {
+			return &l.Ejemplares[i]
+		}
+	}
+	return nil
+}
+
+// ejemplarApartadoPara retorna el ejemplar apartado para el usuario, si hay uno
+func (l *Libro) ejemplarApartadoPara(usuarioID UsuarioID) *Ejemplar {
+	for i := range l.Ejemplares {
+		if l.Ejemplares[i].ReservadoPara == usuarioID && !l.Ejemplares[i].Prestado {
+			return &l.Ejemplares[i]
+		}
+	}
+	return nil
+}
+
+// AgregarEjemplar registra una nueva copia física de un libro existente
+// Si no se indica código de barras se genera uno a partir del ID
+func (b *Biblioteca) AgregarEjemplar(libroID LibroID, codigoBarras string) (*Ejemplar, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	libro := b.buscarLibro(libroID)
+	if libro == nil {
+		return nil, errLibroNoEncontrado(libroID)
+	}
+	if codigoBarras != "" {
+		if _, existente := b.buscarEjemplarPorCodigo(codigoBarras); existente != nil {
+			return nil, &ErrorBiblioteca{Tipo: ErrCodigoDuplicado, LibroID: libroID, EjemplarID: existente.ID, Valor: codigoBarras,
+				mensaje: txt("error.codigo_duplicado", codigoBarras)}
+		}
+	}
+
+	secuenciasAntes := b.secuencias
+	ejemplar := b.nuevoEjemplar(codigoBarras)
+	actualizado := libro.copiar()
+	actualizado.Ejemplares = append(actualizado.Ejemplares, ejemplar)
+	if err := b.escribirEvento(Evento{Tipo: EventoEjemplarAgregado, Libro: &actualizado}); err != nil {
+		b.secuencias = secuenciasAntes
+		return nil, errJournal(err, "error.ejemplar_no_registrado")
+	}
+	*libro = actualizado
+	b.indices.ejemplares[ejemplar.ID] = libro.ID
+	b.indices.codigos[ejemplar.CodigoBarras] = ejemplar.ID
+
+	// Un ejemplar nuevo atiende primero a quien esté esperando el título
+	// El ejemplar ya quedó registrado: si falla el apartado solo se avisa
+	if err := b.apartarParaSiguiente(libro, libro.buscarEjemplar(ejemplar.ID), b.ahora()); err != nil {
+		b.avisar(err)
+	}
+	ejemplar = *libro.buscarEjemplar(ejemplar.ID)
+	return &ejemplar, nil
+}
+
+// BuscarEjemplar busca una copia por su ID y retorna copias del libro y el ejemplar
+func (b *Biblioteca) BuscarEjemplar(id EjemplarID) (*Libro, *Ejemplar) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	libro, ejemplar := b.buscarEjemplar(id)
+	if ejemplar == nil {
+		return nil, nil
+	}
+	copiaLibro := libro.copiar()
+	copiaEjemplar := *ejemplar
+	return &copiaLibro, &copiaEjemplar
+}
+
+// BuscarEjemplarPorCodigo busca una copia por su código de barras
+func (b *Biblioteca) BuscarEjemplarPorCodigo(codigoBarras string) (*Libro, *Ejemplar) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	libro, ejemplar := b.buscarEjemplarPorCodigo(codigoBarras)
+	if ejemplar == nil {
+		return nil, nil
+	}
+	copiaLibro := libro.copiar()
+	copiaEjemplar := *ejemplar
+	return &copiaLibro, &copiaEjemplar
+}
+
+// PrestarEjemplar presta una copia concreta (por ejemplo, al escanear su código)
+func (b *Biblioteca) PrestarEjemplar(ejemplarID EjemplarID, usuarioID UsuarioID) (*Prestamo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	libro, ejemplar := b.buscarEjemplar(ejemplarID)
+	if ejemplar == nil {
+		return nil, errEjemplarNoEncontrado(ejemplarID)
+	}
+	return b.prestar(libro, ejemplar, usuarioID)
+}
+
+// DevolverEjemplar procesa la devolución de una copia concreta
+func (b *Biblioteca) DevolverEjemplar(ejemplarID EjemplarID) (*Prestamo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	libro, ejemplar := b.buscarEjemplar(ejemplarID)
+	if ejemplar == nil {
+		return nil, errEjemplarNoEncontrado(ejemplarID)
+	}
+
+	if prestamo := b.prestamoActivoDe(ejemplarID); prestamo != nil {
+		return b.devolver(libro, prestamo)
+	}
+	return nil, &ErrorBiblioteca{Tipo: ErrLibroNoPrestado, LibroID: libro.ID, EjemplarID: ejemplarID,
+		mensaje: txt("error.ejemplar_sin_prestamo", ejemplar.CodigoBarras)}
+}
+
+// nuevoEjemplar crea un ejemplar con el próximo ID
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) nuevoEjemplar(codigoBarras string) Ejemplar {
+	id := b.siguienteEjemplarID()
+	if codigoBarras == "" {
+		codigoBarras = fmt.Sprintf("EJ-%06d", id)
+	}
+	return Ejemplar{ID: id, CodigoBarras: codigoBarras}
+}
+
+// buscarEjemplar retorna punteros al libro y al ejemplar originales
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) buscarEjemplar(id EjemplarID) (*Libro, *Ejemplar) {
+	libroID, ok := b.indices.ejemplares[id]
+	if !ok {
+		return nil, nil
+	}
+	libro := b.buscarLibro(libroID)
+	if libro == nil {
+		return nil, nil
+	}
+	if ejemplar := libro.buscarEjemplar(id); ejemplar != nil {
+		return libro, ejemplar
+	}
+	return nil, nil
+}
+
+// buscarEjemplarPorCodigo retorna punteros al libro y al ejemplar originales
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) buscarEjemplarPorCodigo(codigoBarras string) (*Libro, *Ejemplar) {
+	id, ok := b.indices.codigos[codigoBarras]
+	if !ok {
+		return nil, nil
+	}
+	return b.buscarEjemplar(id)
+}
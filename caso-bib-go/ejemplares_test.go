@@ -0,0 +1,88 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestEjemplaresSePrestanPorSeparado(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	libro, _ := b.AgregarLibro("El Quijote", "Cervantes", "9788437604947", 1200)
+	segundo, err := b.AgregarEjemplar(libro.ID, "QUI-002")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.AgregarEjemplar(libro.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+
+	if texto := b.BuscarLibro(libro.ID).Disponibilidad(); texto != "3 de 3 disponibles" {
+		t.Errorf("Disponibilidad = %q", texto)
+	}
+
+	// el préstamo registra la copia concreta
+	porCodigo, ejemplar := b.BuscarEjemplarPorCodigo("QUI-002")
+	if ejemplar == nil || porCodigo.ID != libro.ID || ejemplar.ID != segundo.ID {
+		t.Fatalf("El código debería encontrar el segundo ejemplar: %+v", ejemplar)
+	}
+	prestamo, err := b.PrestarEjemplar(segundo.ID, ana.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prestamo.EjemplarID != segundo.ID || prestamo.LibroID != libro.ID {
+		t.Errorf("El préstamo debería ser del segundo ejemplar: %+v", prestamo)
+	}
+	// por título se presta otra copia libre
+	otro, err := b.PrestarLibro(libro.ID, beto.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otro.EjemplarID == segundo.ID {
+		t.Errorf("No se debería prestar dos veces la misma copia")
+	}
+	if texto := b.BuscarLibro(libro.ID).Disponibilidad(); texto != "1 de 3 disponible" {
+		t.Errorf("Disponibilidad = %q", texto)
+	}
+
+	// con dos copias afuera, devolver por título es ambiguo
+	if _, err := b.DevolverLibro(libro.ID); !errors.Is(err, ErrDatosInvalidos) {
+		t.Errorf("Se esperaba un error por varios ejemplares prestados, se obtuvo %v", err)
+	}
+	devuelto, err := b.DevolverEjemplar(segundo.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if devuelto.ID != prestamo.ID {
+		t.Errorf("Se devolvió el préstamo %d, se esperaba %d", devuelto.ID, prestamo.ID)
+	}
+	if _, err := b.DevolverEjemplar(segundo.ID); !errors.Is(err, ErrLibroNoPrestado) {
+		t.Errorf("El ejemplar ya fue devuelto, se obtuvo %v", err)
+	}
+}
+
+func TestAgregarEjemplarRechazos(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	libro, _ := b.AgregarLibro("El Quijote", "Cervantes", "9788437604947", 1200)
+	if _, err := b.AgregarEjemplar(libro.ID, "QUI-002"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := b.AgregarEjemplar(libro.ID, "QUI-002"); !errors.Is(err, ErrCodigoDuplicado) {
+		t.Errorf("Se esperaba ErrCodigoDuplicado, se obtuvo %v", err)
+	}
+	if _, err := b.AgregarEjemplar(99, ""); !errors.Is(err, ErrLibroNoEncontrado) {
+		t.Errorf("Se esperaba ErrLibroNoEncontrado, se obtuvo %v", err)
+	}
+	// otra copia del mismo ISBN es un ejemplar, no un libro nuevo
+	if _, err := b.AgregarLibro("El Quijote", "Cervantes", "978-84-376-0494-7", 1200); !errors.Is(err, ErrISBNDuplicado) {
+		t.Errorf("Se esperaba ErrISBNDuplicado, se obtuvo %v", err)
+	}
+	if _, err := b.PrestarEjemplar(99, 1); !errors.Is(err, ErrEjemplarNoEncontrado) {
+		t.Errorf("Se esperaba ErrEjemplarNoEncontrado, se obtuvo %v", err)
+	}
+	if n := len(b.BuscarLibro(libro.ID).Ejemplares); n != 2 {
+		t.Errorf("Los rechazos no deberían agregar ejemplares: %d", n)
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import "errors"
+
+// ==========================================
+// ERRORES DEL DOMINIO
+// ==========================================
+
+// codigoError es el tipo de los errores Err*: el texto es un código estable
+// que la API devuelve junto al mensaje
+type codigoError string
+
+func (c codigoError) Error() string { return string(c) }
+
+// Clases de error; cada error Err* pertenece a una (ver clasesError)
+// y errors.Is(err, ErrNoEncontrado) vale para cualquiera de ellos
+var (
+	ErrNoEncontrado   error = codigoError("no_encontrado")
+	ErrConflicto      error = codigoError("conflicto") // la operación choca con el estado actual
+	ErrDatosInvalidos error = codigoError("datos_invalidos")
+)
+
+// Errores que retornan las operaciones de la biblioteca, para errors.Is
+var (
+	ErrLibroNoEncontrado    error = codigoError("libro_no_encontrado")
+	ErrUsuarioNoEncontrado  error = codigoError("usuario_no_encontrado")
+	ErrEjemplarNoEncontrado error = codigoError("ejemplar_no_encontrado")
+	ErrPrestamoNoEncontrado error = codigoError("prestamo_no_encontrado")
+	ErrReservaNoEncontrada  error = codigoError("reserva_no_encontrada")
+
+	ErrLibroYaPrestado     error = codigoError("libro_ya_prestado")  // no queda un ejemplar libre
+	ErrLibroNoPrestado     error = codigoError("libro_no_prestado")  // no hay préstamo activo que devolver
+	ErrLibroNoPrestable    error = codigoError("libro_no_prestable") // el libro tiene datos incompletos
+	ErrLibroReservado      error = codigoError("libro_reservado")    // apartado o reservado para otro usuario
+	ErrUsuarioInactivo     error = codigoError("usuario_inactivo")
+	ErrUsuarioSuspendido   error = codigoError("usuario_suspendido") // multas impagas o atraso (ver Usuario.Suspension)
+	ErrLimitePrestamos     error = codigoError("limite_prestamos")
+	ErrISBNDuplicado       error = codigoError("isbn_duplicado")
+	ErrEmailDuplicado      error = codigoError("email_duplicado")
+	ErrCodigoDuplicado     error = codigoError("codigo_duplicado")
+	ErrPrestamoNoRenovable error = codigoError("prestamo_no_renovable")
+	ErrReservaInnecesaria  error = codigoError("reserva_innecesaria") // hay ejemplares libres o ya lo tiene prestado
+	ErrReservaDuplicada    error = codigoError("reserva_duplicada")
+	ErrReservaNoActiva     error = codigoError("reserva_no_activa")
+	ErrMultaNoPendiente    error = codigoError("multa_no_pendiente") // sin multa, sin devolver o ya pagada
+	ErrDatosEnUso          error = codigoError("datos_en_uso")       // otro proceso está modificando el archivo de datos
+	ErrFormatoInvalido     error = codigoError("formato_invalido")   // snapshot, journal, calendario o registros ilegibles
+	ErrNoExportable        error = codigoError("no_exportable")      // el libro no entra en el formato de intercambio
+	ErrArchivoExistente    error = codigoError("archivo_existente")  // no se pisan datos de otra biblioteca
+
+	// la operación no se aplicó, o se aplicó sin quedar en el journal
+	ErrJournal error = codigoError("journal")
+	// no se pudo leer o escribir un archivo (la causa tiene el error del sistema)
+	ErrArchivo error = codigoError("archivo")
+)
+
+// clasesError agrupa los errores para quien solo necesita la categoría
+// (la API para el código HTTP, la CLI para el código de salida)
+var clasesError = map[error]error{
+	ErrLibroNoEncontrado:    ErrNoEncontrado,
+	ErrUsuarioNoEncontrado:  ErrNoEncontrado,
+	ErrEjemplarNoEncontrado: ErrNoEncontrado,
+	ErrPrestamoNoEncontrado: ErrNoEncontrado,
+	ErrReservaNoEncontrada:  ErrNoEncontrado,
+	ErrLibroYaPrestado:      ErrConflicto,
+	ErrLibroNoPrestado:      ErrConflicto,
+	ErrLibroNoPrestable:     ErrConflicto,
+	ErrLibroReservado:       ErrConflicto,
+	ErrUsuarioInactivo:      ErrConflicto,
+	ErrUsuarioSuspendido:    ErrConflicto,
+	ErrLimitePrestamos:      ErrConflicto,
+	ErrISBNDuplicado:        ErrConflicto,
+	ErrEmailDuplicado:       ErrConflicto,
+	ErrCodigoDuplicado:      ErrConflicto,
+	ErrPrestamoNoRenovable:  ErrConflicto,
+	ErrReservaInnecesaria:   ErrConflicto,
+	ErrReservaDuplicada:     ErrConflicto,
+	ErrReservaNoActiva:      ErrConflicto,
+	ErrMultaNoPendiente:     ErrConflicto,
+	ErrDatosEnUso:           ErrConflicto,
+	ErrFormatoInvalido:      ErrDatosInvalidos,
+	ErrNoExportable:         ErrConflicto,
+	ErrArchivoExistente:     ErrConflicto,
+}
+
+// ErrorBiblioteca es el error concreto de las operaciones de la biblioteca
+// Tipo es uno de los Err*; los IDs identifican las entidades involucradas
+// (solo se completan los que aplican) y Valor el dato rechazado, si lo hay
+// Se obtiene con errors.As; errors.Is compara contra Tipo y su clase
+// El mensaje se guarda sin traducir: Error() lo da en IdiomaPorDefecto y
+// MensajeDeError en cualquier otro
+type ErrorBiblioteca struct {
+	Tipo       error
+	LibroID    LibroID
+	UsuarioID  UsuarioID
+	EjemplarID EjemplarID
+	PrestamoID PrestamoID
+	ReservaID  ReservaID
+	Valor      string
+	Causa      error
+	mensaje    texto
+}
+
+func (e *ErrorBiblioteca) Error() string {
+	return e.textoEn(IdiomaPorDefecto)
+}
+
+func (e *ErrorBiblioteca) textoEn(idioma Idioma) string {
+	mensaje := e.mensaje.textoEn(idioma)
+	switch {
+	case e.Causa == nil:
+		return mensaje
+	case mensaje == "":
+		return MensajeDeError(e.Causa, idioma)
+	}
+	return mensaje + ": " + MensajeDeError(e.Causa, idioma)
+}
+
+// Is hace que errors.Is reconozca el tipo y la clase del error
+func (e *ErrorBiblioteca) Is(objetivo error) bool {
+	return objetivo == e.Tipo || (objetivo != nil && objetivo == clasesError[e.Tipo])
+}
+
+// Unwrap expone la causa (por ejemplo el error de E/S del journal)
+func (e *ErrorBiblioteca) Unwrap() error {
+	return e.Causa
+}
+
+// Codigo retorna el código estable del tipo de error
+func (e *ErrorBiblioteca) Codigo() string {
+	if e.Tipo == nil {
+		return ""
+	}
+	return e.Tipo.Error()
+}
+
+// Constructores de los errores más repetidos
+
+func errLibroNoEncontrado(id LibroID) error {
+	return &ErrorBiblioteca{Tipo: ErrLibroNoEncontrado, LibroID: id, mensaje: txt("error.libro_no_encontrado", id)}
+}
+
+func errUsuarioNoEncontrado(id UsuarioID) error {
+	return &ErrorBiblioteca{Tipo: ErrUsuarioNoEncontrado, UsuarioID: id, mensaje: txt("error.usuario_no_encontrado", id)}
+}
+
+func errEjemplarNoEncontrado(id EjemplarID) error {
+	return &ErrorBiblioteca{Tipo: ErrEjemplarNoEncontrado, EjemplarID: id, mensaje: txt("error.ejemplar_no_encontrado", id)}
+}
+
+func errPrestamoNoEncontrado(id PrestamoID) error {
+	return &ErrorBiblioteca{Tipo: ErrPrestamoNoEncontrado, PrestamoID: id, mensaje: txt("error.prestamo_no_encontrado", id)}
+}
+
+func errReservaNoEncontrada(id ReservaID) error {
+	return &ErrorBiblioteca{Tipo: ErrReservaNoEncontrada, ReservaID: id, mensaje: txt("error.reserva_no_encontrada", id)}
+}
+
+// errISBNDuplicado indica que el ISBN ya es del libro existente
+// existente es 0 cuando el repetido está dentro del mismo archivo importado
+func errISBNDuplicado(isbn string, existente LibroID) error {
+	return &ErrorBiblioteca{Tipo: ErrISBNDuplicado, LibroID: existente, Valor: isbn,
+		mensaje: txt("error.isbn_duplicado", FormatearISBN(isbn))}
+}
+
+func errUsuarioInactivo(usuario *Usuario) error {
+	return &ErrorBiblioteca{Tipo: ErrUsuarioInactivo, UsuarioID: usuario.ID, mensaje: txt("error.usuario_inactivo", usuario.Nombre)}
+}
+
+// errUsuarioSuspendido explica la suspensión registrada en el usuario
+func errUsuarioSuspendido(usuario *Usuario) error {
+	suspension := usuario.Suspension
+	mensaje := txt("error.usuario_suspendido_multas", usuario.Nombre, monto(suspension.Deuda))
+	if suspension.Motivo == SuspensionAtraso {
+		mensaje = txt("error.usuario_suspendido_atraso", usuario.Nombre, suspension.PrestamoID, suspension.DiasAtraso)
+	}
+	return &ErrorBiblioteca{Tipo: ErrUsuarioSuspendido, UsuarioID: usuario.ID, PrestamoID: suspension.PrestamoID,
+		Valor: string(suspension.Motivo), mensaje: mensaje}
+}
+
+// errNoRenovable rechaza la renovación de un préstamo
+func errNoRenovable(prestamo *Prestamo, mensaje texto) error {
+	return &ErrorBiblioteca{Tipo: ErrPrestamoNoRenovable, PrestamoID: prestamo.ID, LibroID: prestamo.LibroID,
+		UsuarioID: prestamo.UsuarioID, mensaje: mensaje}
+}
+
+// errDatosInvalidos es el error de validación de datos de entrada
+func errDatosInvalidos(clave ClaveMensaje, args ...any) error {
+	return &ErrorBiblioteca{Tipo: ErrDatosInvalidos, mensaje: txt(clave, args...)}
+}
+
+// errJournal envuelve un fallo de escritura del journal
+func errJournal(causa error, clave ClaveMensaje, args ...any) error {
+	return &ErrorBiblioteca{Tipo: ErrJournal, mensaje: txt(clave, args...), Causa: causa}
+}
+
+// errArchivo envuelve un fallo de E/S al leer o escribir un archivo
+func errArchivo(causa error, clave ClaveMensaje, args ...any) error {
+	return &ErrorBiblioteca{Tipo: ErrArchivo, mensaje: txt(clave, args...), Causa: causa}
+}
+
+// errFormato indica un contenido que no se puede interpretar; causa es el
+// error del decodificador, si lo hay
+func errFormato(causa error, clave ClaveMensaje, args ...any) error {
+	return &ErrorBiblioteca{Tipo: ErrFormatoInvalido, mensaje: txt(clave, args...), Causa: causa}
+}
+
+// errNoExportable rechaza un registro que no entra en el formato de intercambio
+func errNoExportable(causa error, clave ClaveMensaje, args ...any) error {
+	return &ErrorBiblioteca{Tipo: ErrNoExportable, mensaje: txt(clave, args...), Causa: causa}
+}
+
+// datosInvalidos marca como ErrDatosInvalidos un error que todavía no es
+// de la biblioteca (por ejemplo el de un archivo MARC mal formado)
+func datosInvalidos(err error) error {
+	var errorBiblioteca *ErrorBiblioteca
+	if err == nil || errors.As(err, &errorBiblioteca) {
+		return err
+	}
+	return &ErrorBiblioteca{Tipo: ErrDatosInvalidos, Causa: err}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestErroresDeArchivosTienenTipo(t *testing.T) {
+	dir := t.TempDir()
+	escribir := func(nombre, contenido string) string {
+		path := filepath.Join(dir, nombre)
+		if err := os.WriteFile(path, []byte(contenido), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	_, errInexistente := CargarDesde(filepath.Join(dir, "no-existe.json"))
+	_, errSnapshot := CargarDesde(escribir("roto.json", "{"))
+	_, errVersion := CargarDesde(escribir("futuro.json", `{"Version": 99}`))
+	_, errCalendario := CargarCalendario(escribir("calendario.json", "[]"))
+	_, errJournal := ReconstruirDesdeJournal(escribir("roto.journal", "{\n{}\n"))
+	_, errMARC := LeerISO2709(strings.NewReader("00010nam\x1d"))
+	_, errMARCXML := LeerMARCXML(strings.NewReader("<record><leader>"))
+	_, errDC := LeerDublinCore(strings.NewReader("<dc><title>"))
+
+	casos := []struct {
+		nombre string
+		err    error
+		tipo   error
+		estado int
+	}{
+		{"snapshot inexistente", errInexistente, ErrArchivo, http.StatusInternalServerError},
+		{"snapshot ilegible", errSnapshot, ErrFormatoInvalido, http.StatusBadRequest},
+		{"versión desconocida", errVersion, ErrFormatoInvalido, http.StatusBadRequest},
+		{"calendario ilegible", errCalendario, ErrFormatoInvalido, http.StatusBadRequest},
+		{"journal corrupto", errJournal, ErrFormatoInvalido, http.StatusBadRequest},
+		{"ISO 2709 ilegible", errMARC, ErrFormatoInvalido, http.StatusBadRequest},
+		{"MARCXML ilegible", errMARCXML, ErrFormatoInvalido, http.StatusBadRequest},
+		{"Dublin Core ilegible", errDC, ErrFormatoInvalido, http.StatusBadRequest},
+	}
+	for _, c := range casos {
+		var errorBiblioteca *ErrorBiblioteca
+		if !errors.As(c.err, &errorBiblioteca) || !errors.Is(c.err, c.tipo) {
+			t.Errorf("%s: se esperaba un ErrorBiblioteca %v, se obtuvo %v", c.nombre, c.tipo, c.err)
+			continue
+		}
+		if estado := estadoHTTP(c.err); estado != c.estado {
+			t.Errorf("%s: estado HTTP %d, se esperaba %d", c.nombre, estado, c.estado)
+		}
+	}
+	if !errors.Is(errInexistente, os.ErrNotExist) {
+		t.Errorf("La causa del sistema debería seguir accesible: %v", errInexistente)
+	}
+	if mensaje := MensajeDeError(errVersion, IdiomaIngles); !strings.Contains(mensaje, "Unsupported snapshot version: 99") {
+		t.Errorf("Mensaje sin traducir: %s", mensaje)
+	}
+}
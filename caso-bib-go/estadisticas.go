@@ -0,0 +1,247 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// ESTADÍSTICAS DE CIRCULACIÓN
+// ==========================================
+
+// topEstadisticas es cuántos títulos y autores se listan en los rankings
+const topEstadisticas = 5
+
+// diasLector es la ventana en que un usuario con préstamos cuenta como lector
+const diasLector = 90
+
+// ConteoLibro es un libro con la cantidad de veces que se prestó
+type ConteoLibro struct {
+	LibroID   LibroID
+	Titulo    string
+	Prestamos int
+}
+
+// ConteoAutor es un autor con la cantidad de préstamos de sus libros
+type ConteoAutor struct {
+	Autor     string
+	Prestamos int
+}
+
+// CirculacionMes resume los movimientos de un mes ("2026-03")
+type CirculacionMes struct {
+	Mes          string
+	Prestamos    int
+	Devoluciones int
+}
+
+// Estadisticas es el estado de la colección y la circulación en un momento dado
+type Estadisticas struct {
+	Biblioteca            string
+	Fecha                 time.Time
+	TotalLibros           int
+	TotalEjemplares       int
+	EjemplaresPrestados   int
+	EjemplaresDisponibles int // sin prestar ni apartados para una reserva
+	TotalUsuarios         int
+	UsuariosActivos       int
+	Lectores              int     // usuarios activos con algún préstamo en los últimos diasLector días
+	ProporcionLectores    float64 // Lectores sobre usuarios activos (0 a 1)
+	TotalPrestamos        int
+	PrestamosActivos      int
+	PrestamosVencidos     int
+	TasaAtraso            float64 // préstamos devueltos tarde o vencidos sobre el total (0 a 1)
+	DuracionPromedio      float64 // días promedio entre préstamo y devolución
+	MasPrestados          []ConteoLibro
+	AutoresMasPrestados   []ConteoAutor
+	CirculacionMensual    []CirculacionMes // ordenada del mes más antiguo al más reciente
+}
+
+// ObtenerEstadisticas calcula las estadísticas a la fecha del reloj de la biblioteca
+func (b *Biblioteca) ObtenerEstadisticas() Estadisticas {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	ahora := b.ahora()
+	est := Estadisticas{
+		Biblioteca:          b.Nombre,
+		Fecha:               ahora,
+		TotalLibros:         len(b.Libros),
+		TotalUsuarios:       len(b.Usuarios),
+		TotalPrestamos:      len(b.Prestamos),
+		MasPrestados:        make([]ConteoLibro, 0),
+		AutoresMasPrestados: make([]ConteoAutor, 0),
+		CirculacionMensual:  make([]CirculacionMes, 0),
+	}
+
+	for _, libro := range b.Libros {
+		est.TotalEjemplares += len(libro.Ejemplares)
+		for _, ejemplar := range libro.Ejemplares {
+			if ejemplar.Prestado {
+				est.EjemplaresPrestados++
+			}
+			if ejemplar.EstaDisponible() {
+				est.EjemplaresDisponibles++
+			}
+		}
+	}
+
+	activos := make(map[UsuarioID]bool)
+	for _, usuario := range b.Usuarios {
+		if usuario.Activo {
+			est.UsuariosActivos++
+			activos[usuario.ID] = true
+		}
+	}
+
+	porLibro := make(map[LibroID]int)
+	porMes := make(map[string]*CirculacionMes)
+	lectores := make(map[UsuarioID]bool)
+	desdeLector := ahora.AddDate(0, 0, -diasLector)
+	atrasados := 0
+	devueltos := 0
+	var diasPrestados float64
+
+	mes := func(fecha time.Time) *CirculacionMes {
+		clave := fecha.Format("2006-01")
+		if porMes[clave] == nil {
+			porMes[clave] = &CirculacionMes{Mes: clave}
+		}
+		return porMes[clave]
+	}
+
+	for _, prestamo := range b.Prestamos {
+		porLibro[prestamo.LibroID]++
+		mes(prestamo.FechaPrestamo).Prestamos++
+		if !prestamo.FechaPrestamo.Before(desdeLector) {
+			lectores[prestamo.UsuarioID] = true
+		}
+
+		if !prestamo.Devuelto {
+			est.PrestamosActivos++
+			lectores[prestamo.UsuarioID] = true
+			if prestamo.EstaVencido(ahora) {
+				est.PrestamosVencidos++
+				atrasados++
+			}
+			continue
+		}
+		// los préstamos anteriores al registro de la fecha de devolución
+		// no aportan a la duración ni a la circulación mensual
+		if prestamo.FechaDevuelto.IsZero() {
+			continue
+		}
+		devueltos++
+		diasPrestados += prestamo.FechaDevuelto.Sub(prestamo.FechaPrestamo).Hours() / 24
+		mes(prestamo.FechaDevuelto).Devoluciones++
+		if prestamo.FechaDevuelto.After(prestamo.FechaDevolucion) {
+			atrasados++
+		}
+	}
+
+	// la proporción es sobre los activos: un usuario dado de baja no cuenta
+	// como lector aunque haya pedido préstamos en la ventana
+	for usuarioID := range lectores {
+		if activos[usuarioID] {
+			est.Lectores++
+		}
+	}
+	if est.UsuariosActivos > 0 {
+		est.ProporcionLectores = float64(est.Lectores) / float64(est.UsuariosActivos)
+	}
+	if est.TotalPrestamos > 0 {
+		est.TasaAtraso = float64(atrasados) / float64(est.TotalPrestamos)
+	}
+	if devueltos > 0 {
+		est.DuracionPromedio = diasPrestados / float64(devueltos)
+	}
+
+	porAutor := make(map[string]int)
+	for _, libro := range b.Libros {
+		if porLibro[libro.ID] == 0 {
+			continue
+		}
+		est.MasPrestados = append(est.MasPrestados,
+			ConteoLibro{LibroID: libro.ID, Titulo: libro.Titulo, Prestamos: porLibro[libro.ID]})
+		porAutor[libro.Autor] += porLibro[libro.ID]
+	}
+	// a igual cantidad de préstamos se mantiene el orden del catálogo
+	sort.SliceStable(est.MasPrestados, func(i, j int) bool {
+		return est.MasPrestados[i].Prestamos > est.MasPrestados[j].Prestamos
+	})
+	if len(est.MasPrestados) > topEstadisticas {
+		est.MasPrestados = est.MasPrestados[:topEstadisticas]
+	}
+
+	for autor, prestamos := range porAutor {
+		est.AutoresMasPrestados = append(est.AutoresMasPrestados, ConteoAutor{Autor: autor, Prestamos: prestamos})
+	}
+	sort.Slice(est.AutoresMasPrestados, func(i, j int) bool {
+		a, c := est.AutoresMasPrestados[i], est.AutoresMasPrestados[j]
+		if a.Prestamos != c.Prestamos {
+			return a.Prestamos > c.Prestamos
+		}
+		return a.Autor < c.Autor
+	})
+	if len(est.AutoresMasPrestados) > topEstadisticas {
+		est.AutoresMasPrestados = est.AutoresMasPrestados[:topEstadisticas]
+	}
+
+	for _, circulacion := range porMes {
+		est.CirculacionMensual = append(est.CirculacionMensual, *circulacion)
+	}
+	sort.Slice(est.CirculacionMensual, func(i, j int) bool {
+		return est.CirculacionMensual[i].Mes < est.CirculacionMensual[j].Mes
+	})
+	return est
+}
+
+// Texto formatea las estadísticas para mostrarlas en la terminal
+func (e Estadisticas) Texto() string {
+	return e.TextoEn(IdiomaPorDefecto)
+}
+
+// TextoEn es Texto en el idioma indicado
+func (e Estadisticas) TextoEn(idioma Idioma) string {
+	var sb strings.Builder
+	linea := func(mensaje string) {
+		sb.WriteString(mensaje)
+		sb.WriteByte('\n')
+	}
+	linea(idioma.Texto("estadisticas.titulo", e.Biblioteca, idioma.Fecha(e.Fecha)))
+	linea(idioma.Texto("estadisticas.libros", e.TotalLibros))
+	linea(idioma.Texto("estadisticas.ejemplares", e.TotalEjemplares))
+	linea(idioma.Texto("estadisticas.prestados", e.EjemplaresPrestados))
+	linea(idioma.Texto("estadisticas.disponibles", e.EjemplaresDisponibles))
+	linea(idioma.Texto("estadisticas.usuarios", e.UsuariosActivos, e.TotalUsuarios))
+	linea(idioma.Texto("estadisticas.lectores", diasLector, e.Lectores, idioma.Decimal(e.ProporcionLectores*100, 0)))
+	linea(idioma.Plural("estadisticas.prestamos", e.PrestamosVencidos, e.PrestamosActivos, e.PrestamosVencidos))
+	linea(idioma.Plural("estadisticas.atraso", e.TotalPrestamos, idioma.Decimal(e.TasaAtraso*100, 1), e.TotalPrestamos))
+	linea(idioma.Texto("estadisticas.duracion", idioma.Decimal(e.DuracionPromedio, 1)))
+
+	if len(e.MasPrestados) > 0 {
+		linea(idioma.Texto("estadisticas.mas_prestados"))
+		for _, c := range e.MasPrestados {
+			fmt.Fprintf(&sb, "      [%d] %s: %d\n", c.LibroID, c.Titulo, c.Prestamos)
+		}
+	}
+	if len(e.AutoresMasPrestados) > 0 {
+		linea(idioma.Texto("estadisticas.autores"))
+		for _, c := range e.AutoresMasPrestados {
+			fmt.Fprintf(&sb, "      %s: %d\n", c.Autor, c.Prestamos)
+		}
+	}
+	if len(e.CirculacionMensual) > 0 {
+		linea(idioma.Texto("estadisticas.circulacion"))
+		for _, c := range e.CirculacionMensual {
+			mes := c.Mes
+			if fecha, err := time.Parse("2006-01", c.Mes); err == nil {
+				mes = idioma.Mes(fecha)
+			}
+			fmt.Fprintf(&sb, "      %s: %d / %d\n", mes, c.Prestamos, c.Devoluciones)
+		}
+	}
+	return sb.String()
+}
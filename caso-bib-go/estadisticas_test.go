@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProporcionLectoresSoloCuentaActivos(t *testing.T) {
+	reloj := NuevoRelojVirtual(time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 200)
+	otro, _ := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+	if _, err := b.RegistrarUsuario("Carla", "carla@test", "", CategoriaEstudiante); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PrestarLibro(otro.ID, beto.ID); err != nil {
+		t.Fatal(err)
+	}
+	// Beto se da de baja después de pedir su préstamo
+	if _, err := b.DesactivarUsuario(beto.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	est := b.ObtenerEstadisticas()
+	if est.UsuariosActivos != 2 || est.Lectores != 1 {
+		t.Fatalf("Se esperaban 2 activos y 1 lector, se obtuvo %d y %d", est.UsuariosActivos, est.Lectores)
+	}
+	if est.ProporcionLectores != 0.5 {
+		t.Errorf("La proporción debería ser 0.5, se obtuvo %v", est.ProporcionLectores)
+	}
+}
+
+func TestEjemplaresApartadosNoEstanDisponibles(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 200)
+	if _, err := b.AgregarEjemplar(libro.ID, ""); err != nil {
+		t.Fatal(err)
+	}
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+	carla, _ := b.RegistrarUsuario("Carla", "carla@test", "", CategoriaEstudiante)
+	prestamo, err := b.PrestarLibro(libro.ID, ana.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PrestarLibro(libro.ID, beto.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.ReservarLibro(libro.ID, carla.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// la copia que devuelve Ana queda apartada para Carla
+	if _, err := b.DevolverEjemplar(prestamo.EjemplarID); err != nil {
+		t.Fatal(err)
+	}
+	est := b.ObtenerEstadisticas()
+	if est.TotalEjemplares != 2 || est.EjemplaresPrestados != 1 || est.EjemplaresDisponibles != 0 {
+		t.Errorf("Se esperaban 2 ejemplares, 1 prestado y 0 disponibles: %d, %d, %d",
+			est.TotalEjemplares, est.EjemplaresPrestados, est.EjemplaresDisponibles)
+	}
+}
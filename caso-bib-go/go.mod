@@ -1,3 +1,3 @@
-module main
+module biblioteca
 
 go 1.24.4
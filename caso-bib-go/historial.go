@@ -0,0 +1,169 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ==========================================
+// HISTORIAL DE PRÉSTAMOS POR USUARIO Y POR LIBRO
+// ==========================================
+
+// porPaginaPorDef es el tamaño de página cuando no se indica
+const porPaginaPorDef = 20
+
+// porPaginaMax es el tamaño de página más grande que se entrega; los
+// pedidos mayores se recortan
+const porPaginaMax = 100
+
+// FiltroHistorial limita y pagina un historial
+// Desde y Hasta filtran por fecha de préstamo (valores cero = sin límite);
+// Pagina empieza en 1
+type FiltroHistorial struct {
+	Desde     time.Time
+	Hasta     time.Time
+	Pagina    int
+	PorPagina int
+}
+
+// EntradaHistorial es un préstamo junto a sus datos calculados
+type EntradaHistorial struct {
+	Prestamo     Prestamo
+	DiasPrestado int     // hasta la devolución, o hasta ahora si sigue activo
+	DiasAtraso   int     // días que pasó de la fecha de devolución
+	Multa        float64 // cobrada al devolver, o la que corresponde hoy
+}
+
+// PaginaHistorial es una página de un historial, del préstamo más reciente
+// al más antiguo
+type PaginaHistorial struct {
+	Entradas  []EntradaHistorial
+	Total     int // entradas que cumplen el filtro, en todas las páginas
+	Pagina    int
+	PorPagina int
+}
+
+// HistorialUsuario retorna los préstamos (activos y devueltos) de un usuario
+func (b *Biblioteca) HistorialUsuario(usuarioID UsuarioID, filtro FiltroHistorial) (*PaginaHistorial, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.buscarUsuario(usuarioID) == nil {
+		return nil, errUsuarioNoEncontrado(usuarioID)
+	}
+	return b.historial(b.indices.prestamosUsuario[usuarioID], filtro, b.ahora())
+}
+
+// HistorialLibro retorna los préstamos (activos y devueltos) de todos los
+// ejemplares de un libro
+func (b *Biblioteca) HistorialLibro(libroID LibroID, filtro FiltroHistorial) (*PaginaHistorial, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.buscarLibro(libroID) == nil {
+		return nil, errLibroNoEncontrado(libroID)
+	}
+	return b.historial(b.indices.prestamosLibro[libroID], filtro, b.ahora())
+}
+
+// historial filtra, ordena y pagina los préstamos de las posiciones indicadas
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) historial(posiciones []int, filtro FiltroHistorial, ahora time.Time) (*PaginaHistorial, error) {
+	if filtro.Pagina < 0 || filtro.PorPagina < 0 {
+		return nil, errDatosInvalidos("error.pagina_negativa")
+	}
+	if filtro.Pagina == 0 {
+		filtro.Pagina = 1
+	}
+	if filtro.PorPagina == 0 {
+		filtro.PorPagina = porPaginaPorDef
+	}
+	filtro.PorPagina = min(filtro.PorPagina, porPaginaMax)
+	if !filtro.Desde.IsZero() && !filtro.Hasta.IsZero() && filtro.Hasta.Before(filtro.Desde) {
+		return nil, errDatosInvalidos("error.fechas_invertidas")
+	}
+
+	prestamos := make([]Prestamo, 0, len(posiciones))
+	for _, pos := range posiciones {
+		prestamo := b.Prestamos[pos]
+		if !filtro.Desde.IsZero() && prestamo.FechaPrestamo.Before(filtro.Desde) {
+			continue
+		}
+		if !filtro.Hasta.IsZero() && !prestamo.FechaPrestamo.Before(filtro.Hasta) {
+			continue
+		}
+		prestamos = append(prestamos, prestamo)
+	}
+	sort.SliceStable(prestamos, func(i, j int) bool {
+		return prestamos[i].FechaPrestamo.After(prestamos[j].FechaPrestamo)
+	})
+
+	pagina := &PaginaHistorial{
+		Entradas:  make([]EntradaHistorial, 0),
+		Total:     len(prestamos),
+		Pagina:    filtro.Pagina,
+		PorPagina: filtro.PorPagina,
+	}
+	// se compara dividiendo para que una página enorme no desborde el producto
+	if filtro.Pagina-1 >= (len(prestamos)+filtro.PorPagina-1)/filtro.PorPagina {
+		return pagina, nil
+	}
+	inicio := (filtro.Pagina - 1) * filtro.PorPagina
+	fin := min(inicio+filtro.PorPagina, len(prestamos))
+	for _, prestamo := range prestamos[inicio:fin] {
+		pagina.Entradas = append(pagina.Entradas, b.entradaHistorial(prestamo, ahora))
+	}
+	return pagina, nil
+}
+
+// entradaHistorial calcula duración, atraso y multa de un préstamo
+// Los préstamos devueltos antes de registrar FechaDevuelto no tienen
+// duración ni atraso conocidos; solo se informa la multa cobrada
+func (b *Biblioteca) entradaHistorial(prestamo Prestamo, ahora time.Time) EntradaHistorial {
+	entrada := EntradaHistorial{Prestamo: prestamo, Multa: prestamo.Multa}
+	if prestamo.Devuelto && prestamo.FechaDevuelto.IsZero() {
+		return entrada
+	}
+
+	fin := ahora
+	if prestamo.Devuelto {
+		fin = prestamo.FechaDevuelto
+	}
+	entrada.DiasPrestado = int(math.Ceil(fin.Sub(prestamo.FechaPrestamo).Hours() / 24))
+	entrada.DiasAtraso = b.diasAtraso(prestamo, fin)
+	if !prestamo.Devuelto {
+		entrada.Multa = b.politicaDe(prestamo.UsuarioID).CalcularMulta(entrada.DiasAtraso)
+	}
+	return entrada
+}
+
+// TotalPaginas retorna cuántas páginas tiene el historial completo
+func (p PaginaHistorial) TotalPaginas() int {
+	if p.PorPagina == 0 {
+		return 0
+	}
+	return (p.Total + p.PorPagina - 1) / p.PorPagina
+}
+
+// nuevoFiltroHistorial arma un filtro a partir de fechas AAAA-MM-DD (vacías =
+// sin límite), como las reciben la API y la CLI
+// hasta incluye el día indicado completo
+func nuevoFiltroHistorial(desde, hasta string, pagina, porPagina int) (FiltroHistorial, error) {
+	filtro := FiltroHistorial{Pagina: pagina, PorPagina: porPagina}
+	if desde != "" {
+		fecha, err := time.ParseInLocation("2006-01-02", desde, time.Local)
+		if err != nil {
+			return filtro, errDatosInvalidos("error.fecha_desde", desde)
+		}
+		filtro.Desde = fecha
+	}
+	if hasta != "" {
+		fecha, err := time.ParseInLocation("2006-01-02", hasta, time.Local)
+		if err != nil {
+			return filtro, errDatosInvalidos("error.fecha_hasta", hasta)
+		}
+		filtro.Hasta = fecha.AddDate(0, 0, 1)
+	}
+	return filtro, nil
+}
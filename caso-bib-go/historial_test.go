@@ -0,0 +1,94 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// bibliotecaConHistorial crea un usuario con n préstamos devueltos
+func bibliotecaConHistorial(t *testing.T, n int) (*Biblioteca, UsuarioID) {
+	t.Helper()
+	b := NuevaBiblioteca("Historial", "")
+	usuario, err := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaDocente)
+	if err != nil {
+		t.Fatal(err)
+	}
+	libro, err := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < n; i++ {
+		if _, err := b.PrestarLibro(libro.ID, usuario.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := b.DevolverLibro(libro.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return b, usuario.ID
+}
+
+func TestHistorialPaginas(t *testing.T) {
+	b, usuarioID := bibliotecaConHistorial(t, 7)
+
+	casos := []struct {
+		nombre    string
+		filtro    FiltroHistorial
+		entradas  int
+		porPagina int
+	}{
+		{"primera", FiltroHistorial{Pagina: 1, PorPagina: 5}, 5, 5},
+		{"última incompleta", FiltroHistorial{Pagina: 2, PorPagina: 5}, 2, 5},
+		{"fuera de rango", FiltroHistorial{Pagina: 3, PorPagina: 5}, 0, 5},
+		{"por defecto", FiltroHistorial{}, 7, porPaginaPorDef},
+		{"tamaño recortado", FiltroHistorial{Pagina: 1, PorPagina: math.MaxInt}, 7, porPaginaMax},
+		// (Pagina-1)*PorPagina desborda y antes daba un índice negativo
+		{"producto negativo", FiltroHistorial{Pagina: 2305843009213693953, PorPagina: 5}, 0, 5},
+		// (Pagina-1)*PorPagina desborda a 0 y antes repetía la página 1
+		{"producto cero", FiltroHistorial{Pagina: 4611686018427387905, PorPagina: 4}, 0, 4},
+		{"página máxima", FiltroHistorial{Pagina: math.MaxInt, PorPagina: math.MaxInt}, 0, porPaginaMax},
+	}
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			pagina, err := b.HistorialUsuario(usuarioID, c.filtro)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(pagina.Entradas) != c.entradas {
+				t.Errorf("%d entradas, se esperaban %d", len(pagina.Entradas), c.entradas)
+			}
+			if pagina.PorPagina != c.porPagina {
+				t.Errorf("PorPagina %d, se esperaba %d", pagina.PorPagina, c.porPagina)
+			}
+			if pagina.Total != 7 {
+				t.Errorf("Total %d, se esperaba 7", pagina.Total)
+			}
+		})
+	}
+}
+
+func TestHistorialPaginaNegativa(t *testing.T) {
+	b, usuarioID := bibliotecaConHistorial(t, 1)
+	if _, err := b.HistorialUsuario(usuarioID, FiltroHistorial{Pagina: -1}); err == nil {
+		t.Error("Una página negativa debería ser un error")
+	}
+}
+
+func TestAPIHistorialPaginaEnorme(t *testing.T) {
+	b, _ := bibliotecaConHistorial(t, 7)
+	servidor := NuevoServidorAPI(b)
+
+	for _, url := range []string{
+		"/usuarios/1/historial?pagina=2305843009213693953&por_pagina=5",
+		"/usuarios/1/historial?pagina=4611686018427387905&por_pagina=4",
+		"/libros/1/historial?pagina=9223372036854775807&por_pagina=9223372036854775807",
+	} {
+		w := httptest.NewRecorder()
+		servidor.ServeHTTP(w, httptest.NewRequest(http.MethodGet, url, nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("%s: estado %d, se esperaba 200", url, w.Code)
+		}
+	}
+}
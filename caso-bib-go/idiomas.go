@@ -0,0 +1,294 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// IDIOMAS Y CATÁLOGO DE MENSAJES
+// ==========================================
+
+// Idioma es el código ISO 639-1 de un idioma con catálogo de mensajes
+type Idioma string
+
+const (
+	IdiomaEspanol   Idioma = "es"
+	IdiomaIngles    Idioma = "en"
+	IdiomaPortugues Idioma = "pt"
+)
+
+// IdiomaPorDefecto es el idioma de Error() y de los métodos sin idioma
+const IdiomaPorDefecto = IdiomaEspanol
+
+// ClaveMensaje identifica un mensaje del catálogo
+// Los mensajes con plural tienen dos entradas: clave.uno y clave.otros
+type ClaveMensaje string
+
+// catalogo tiene los mensajes de cada idioma (mensajes_*.go)
+// Lo que falta en un idioma se toma de IdiomaPorDefecto
+var catalogo = map[Idioma]map[ClaveMensaje]string{
+	IdiomaEspanol:   mensajesES,
+	IdiomaIngles:    mensajesEN,
+	IdiomaPortugues: mensajesPT,
+}
+
+// Idiomas retorna los idiomas disponibles, ordenados
+func Idiomas() []Idioma {
+	idiomas := make([]Idioma, 0, len(catalogo))
+	for idioma := range catalogo {
+		idiomas = append(idiomas, idioma)
+	}
+	sort.Slice(idiomas, func(i, j int) bool { return idiomas[i] < idiomas[j] })
+	return idiomas
+}
+
+// ParsearIdioma reconoce "es", "EN", "pt-BR", "en_US.UTF-8"...
+func ParsearIdioma(texto string) (Idioma, error) {
+	codigo := strings.ToLower(strings.TrimSpace(texto))
+	if i := strings.IndexAny(codigo, "-_."); i >= 0 {
+		codigo = codigo[:i]
+	}
+	if _, ok := catalogo[Idioma(codigo)]; ok {
+		return Idioma(codigo), nil
+	}
+	nombres := make([]string, 0, len(catalogo))
+	for _, idioma := range Idiomas() {
+		nombres = append(nombres, string(idioma))
+	}
+	return "", &ErrorBiblioteca{Tipo: ErrDatosInvalidos, Valor: texto,
+		mensaje: txt("error.idioma_desconocido", texto, strings.Join(nombres, ", "))}
+}
+
+// CambiarIdioma fija el idioma de los comprobantes y mensajes de un usuario
+func (b *Biblioteca) CambiarIdioma(usuarioID UsuarioID, idioma Idioma) (*Usuario, error) {
+	idioma, err := ParsearIdioma(string(idioma))
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	usuario := b.buscarUsuario(usuarioID)
+	if usuario == nil {
+		return nil, errUsuarioNoEncontrado(usuarioID)
+	}
+	actualizado := *usuario
+	actualizado.Idioma = idioma
+	if err := b.escribirEvento(Evento{Tipo: EventoUsuarioActualizado, Usuario: &actualizado}); err != nil {
+		return nil, errJournal(err, "error.cambio_no_registrado")
+	}
+	*usuario = actualizado
+
+	copia := *usuario
+	return &copia, nil
+}
+
+// IdiomaPreferido es el idioma elegido por el usuario o IdiomaPorDefecto
+func (u Usuario) IdiomaPreferido() Idioma {
+	if u.Idioma == "" {
+		return IdiomaPorDefecto
+	}
+	return u.Idioma
+}
+
+// IdiomaDeAcceptLanguage elige el idioma de mayor preferencia de un
+// encabezado Accept-Language ("pt-BR,pt;q=0.9,en;q=0.8") que tenga
+// catálogo; si ninguno lo tiene retorna IdiomaPorDefecto
+func IdiomaDeAcceptLanguage(encabezado string) Idioma {
+	type opcion struct {
+		idioma Idioma
+		peso   float64
+	}
+	opciones := make([]opcion, 0)
+	for _, parte := range strings.Split(encabezado, ",") {
+		etiqueta, parametros, _ := strings.Cut(strings.TrimSpace(parte), ";")
+		idioma, err := ParsearIdioma(etiqueta)
+		if err != nil {
+			continue
+		}
+		peso := 1.0
+		if valor, ok := strings.CutPrefix(strings.TrimSpace(parametros), "q="); ok {
+			if q, err := strconv.ParseFloat(valor, 64); err == nil {
+				peso = q
+			}
+		}
+		if peso > 0 {
+			opciones = append(opciones, opcion{idioma, peso})
+		}
+	}
+	if len(opciones) == 0 {
+		return IdiomaPorDefecto
+	}
+	sort.SliceStable(opciones, func(i, j int) bool { return opciones[i].peso > opciones[j].peso })
+	return opciones[0].idioma
+}
+
+// buscar retorna la plantilla de la clave, con respaldo en IdiomaPorDefecto
+func (i Idioma) buscar(clave ClaveMensaje) (string, bool) {
+	if plantilla, ok := catalogo[i][clave]; ok {
+		return plantilla, true
+	}
+	plantilla, ok := catalogo[IdiomaPorDefecto][clave]
+	return plantilla, ok
+}
+
+// Texto traduce un mensaje del catálogo y le aplica los argumentos
+// Las plantillas usan los verbos de fmt; los argumentos que son a su vez
+// mensajes (texto, EstadoReserva...) se traducen al mismo idioma
+// Una clave inexistente se muestra tal cual, para que se note
+func (i Idioma) Texto(clave ClaveMensaje, args ...any) string {
+	plantilla, ok := i.buscar(clave)
+	if !ok {
+		return string(clave)
+	}
+	if len(args) == 0 {
+		return plantilla
+	}
+	return fmt.Sprintf(plantilla, i.traducirArgs(args)...)
+}
+
+// Plural es Texto eligiendo la forma (clave.uno o clave.otros) según n
+func (i Idioma) Plural(clave ClaveMensaje, n int, args ...any) string {
+	return i.Texto(clave+ClaveMensaje("."+i.formaPlural(n)), args...)
+}
+
+// formaPlural aplica la regla de plural del idioma (CLDR, forma cardinal)
+func (i Idioma) formaPlural(n int) string {
+	switch i {
+	case IdiomaPortugues:
+		// en portugués de Brasil el cero también es singular ("0 livro")
+		if n == 0 || n == 1 {
+			return "uno"
+		}
+	default:
+		if n == 1 {
+			return "uno"
+		}
+	}
+	return "otros"
+}
+
+func (i Idioma) traducirArgs(args []any) []any {
+	traducidos := make([]any, len(args))
+	for n, arg := range args {
+		if localizable, ok := arg.(localizable); ok {
+			traducidos[n] = localizable.textoEn(i)
+		} else {
+			traducidos[n] = arg
+		}
+	}
+	return traducidos
+}
+
+// Fecha formatea una fecha en la forma corta del idioma (16/10/2026, 10/16/2026)
+func (i Idioma) Fecha(fecha time.Time) string {
+	return fecha.Format(i.Texto("formato.fecha"))
+}
+
+// FechaLarga formatea una fecha con el nombre del mes ("16 de octubre de 2026")
+func (i Idioma) FechaLarga(fecha time.Time) string {
+	return i.Texto("formato.fecha_larga", fecha.Day(), i.nombreMes(fecha.Month()), fecha.Year())
+}
+
+// Mes formatea mes y año ("octubre de 2026")
+func (i Idioma) Mes(fecha time.Time) string {
+	return i.Texto("formato.mes", i.nombreMes(fecha.Month()), fecha.Year())
+}
+
+func (i Idioma) nombreMes(mes time.Month) string {
+	return i.Texto(ClaveMensaje("mes." + strconv.Itoa(int(mes))))
+}
+
+// Decimal formatea un número con el separador decimal del idioma
+func (i Idioma) Decimal(numero float64, decimales int) string {
+	texto := strconv.FormatFloat(numero, 'f', decimales, 64)
+	return strings.Replace(texto, ".", i.Texto("formato.separador_decimal"), 1)
+}
+
+// localizable es un valor que se muestra distinto según el idioma
+type localizable interface {
+	textoEn(idioma Idioma) string
+}
+
+// texto es un mensaje del catálogo todavía sin traducir
+// Los errores lo guardan para mostrarse en el idioma de quien los lee
+type texto struct {
+	clave  ClaveMensaje
+	plural bool
+	n      int
+	args   []any
+}
+
+// txt arma un mensaje sin plural
+func txt(clave ClaveMensaje, args ...any) texto {
+	return texto{clave: clave, args: args}
+}
+
+// txtN arma un mensaje cuya forma depende de la cantidad n
+func txtN(clave ClaveMensaje, n int, args ...any) texto {
+	return texto{clave: clave, plural: true, n: n, args: args}
+}
+
+func (t texto) textoEn(idioma Idioma) string {
+	if t.clave == "" {
+		return ""
+	}
+	if t.plural {
+		return idioma.Plural(t.clave, t.n, t.args...)
+	}
+	return idioma.Texto(t.clave, t.args...)
+}
+
+// textoEn traduce el estado de la reserva ("pendiente" → "pending")
+func (e EstadoReserva) textoEn(idioma Idioma) string {
+	if traduccion, ok := idioma.buscar(ClaveMensaje("reserva.estado." + string(e))); ok {
+		return traduccion
+	}
+	return string(e)
+}
+
+// textoEn traduce el nombre de la categoría; las categorías propias de la
+// biblioteca, sin traducción en el catálogo, se muestran tal cual
+func (c CategoriaUsuario) textoEn(idioma Idioma) string {
+	if traduccion, ok := idioma.buscar(ClaveMensaje("categoria." + string(c))); ok {
+		return traduccion
+	}
+	return string(c)
+}
+
+// listaTextos es una enumeración de mensajes que se muestra separada por comas
+type listaTextos []texto
+
+func (l listaTextos) textoEn(idioma Idioma) string {
+	partes := make([]string, len(l))
+	for i, t := range l {
+		partes[i] = t.textoEn(idioma)
+	}
+	return strings.Join(partes, ", ")
+}
+
+// monto es un importe que se muestra con el separador decimal del idioma
+type monto float64
+
+func (m monto) textoEn(idioma Idioma) string {
+	return idioma.Decimal(float64(m), 2)
+}
+
+// MensajeDeError retorna el mensaje del error en el idioma indicado
+// Los errores que no son de la biblioteca (E/S, JSON...) quedan como están
+func MensajeDeError(err error, idioma Idioma) string {
+	var errorLocalizable interface {
+		error
+		localizable
+	}
+	if errors.As(err, &errorLocalizable) {
+		return errorLocalizable.textoEn(idioma)
+	}
+	return err.Error()
+}
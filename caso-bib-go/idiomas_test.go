@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCatalogosTienenLasMismasClaves(t *testing.T) {
+	for idioma, mensajes := range catalogo {
+		for clave := range mensajesES {
+			if _, ok := mensajes[clave]; !ok {
+				t.Errorf("Al catálogo %s le falta la clave %s", idioma, clave)
+			}
+		}
+		for clave := range mensajes {
+			if _, ok := mensajesES[clave]; !ok {
+				t.Errorf("El catálogo %s tiene la clave %s, que no está en español", idioma, clave)
+			}
+		}
+	}
+}
+
+func TestCLIEnIngles(t *testing.T) {
+	dir := t.TempDir()
+	datos := filepath.Join(dir, "biblioteca.json")
+	csv := filepath.Join(dir, "catalogo.csv")
+	if err := os.WriteFile(csv, []byte("titulo,autor,paginas\nRayuela,Cortázar,abc\nFicciones,Bor\"ges,10\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ejecutar := func(args ...string) string {
+		t.Helper()
+		var salida, errores bytes.Buffer
+		ejecutarCLI(append(args, "--datos", datos, "--idioma", "en"), &salida, &errores)
+		return salida.String() + errores.String()
+	}
+
+	casos := []struct {
+		args  []string
+		texto string
+	}{
+		{[]string{"iniciar", "--nombre", "Central"}, "Library created: Central"},
+		{[]string{"libro", "listar"}, "No books"},
+		{[]string{"libro", "importar", "--archivo", csv}, "row 2: Invalid page count 'abc'"},
+		{[]string{"libro", "importar", "--archivo", csv}, "row 3: Invalid format in column"},
+		{[]string{"libro", "importar", "--archivo", csv}, "0 of 2 books imported, 2 with errors"},
+		{[]string{"libro", "buscar", "--consulta", "nada"}, "No results"},
+		{[]string{"prestamo", "vencidos"}, "No overdue loans"},
+		{[]string{"reserva", "listar", "--libro", "1"}, "No holds"},
+		{[]string{"verificar"}, "The library is consistent"},
+		{[]string{"calendario", "cargar"}, "You must provide --archivo"},
+		{[]string{"iniciar", "--nombre", "Otra"}, "already exists"},
+	}
+	for _, c := range casos {
+		if salida := ejecutar(c.args...); !strings.Contains(salida, c.texto) {
+			t.Errorf("%v: se esperaba %q en la salida:\n%s", c.args, c.texto, salida)
+		}
+	}
+}
+
+func TestReporteConsistenciaSeTraduce(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	if _, err := b.AgregarLibro("Rayuela", "Cortázar", "", 600); err != nil {
+		t.Fatal(err)
+	}
+	b.Libros[0].Ejemplares[0].Prestado = true
+
+	reporte, err := b.VerificarConsistencia(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reporte.Inconsistencias) != 1 || !strings.Contains(reporte.Inconsistencias[0].Detalle, "sin un préstamo activo") {
+		t.Fatalf("Se esperaba una inconsistencia en español: %+v", reporte.Inconsistencias)
+	}
+	reporte.Traducir(IdiomaIngles)
+	if detalle := reporte.Inconsistencias[0].Detalle; !strings.Contains(detalle, "without an active loan") {
+		t.Errorf("Detalle sin traducir: %s", detalle)
+	}
+}
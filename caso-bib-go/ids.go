@@ -0,0 +1,86 @@
+package main
+
+// ==========================================
+// IDENTIFICADORES TIPADOS POR ENTIDAD
+// ==========================================
+
+// Cada entidad tiene su propio tipo de ID, así el compilador rechaza pasar
+// un LibroID donde se espera un UsuarioID
+type (
+	LibroID    int
+	UsuarioID  int
+	PrestamoID int
+	EjemplarID int
+	ReservaID  int
+)
+
+// Secuencias guarda el próximo ID de cada entidad
+// Cada secuencia avanza por separado: el libro 1 y el usuario 1 pueden existir a la vez
+type Secuencias struct {
+	Libro    LibroID
+	Usuario  UsuarioID
+	Prestamo PrestamoID
+	Ejemplar EjemplarID
+	Reserva  ReservaID
+}
+
+// nuevasSecuencias retorna las secuencias de una biblioteca vacía
+func nuevasSecuencias() Secuencias {
+	return Secuencias{Libro: 1, Usuario: 1, Prestamo: 1, Ejemplar: 1, Reserva: 1}
+}
+
+// Los siguientes métodos entregan el próximo ID y avanzan la secuencia
+// Quien los llama debe tener tomado b.mu
+
+func (b *Biblioteca) siguienteLibroID() LibroID {
+	id := b.secuencias.Libro
+	b.secuencias.Libro++
+	return id
+}
+
+func (b *Biblioteca) siguienteUsuarioID() UsuarioID {
+	id := b.secuencias.Usuario
+	b.secuencias.Usuario++
+	return id
+}
+
+func (b *Biblioteca) siguientePrestamoID() PrestamoID {
+	id := b.secuencias.Prestamo
+	b.secuencias.Prestamo++
+	return id
+}
+
+func (b *Biblioteca) siguienteEjemplarID() EjemplarID {
+	id := b.secuencias.Ejemplar
+	b.secuencias.Ejemplar++
+	return id
+}
+
+func (b *Biblioteca) siguienteReservaID() ReservaID {
+	id := b.secuencias.Reserva
+	b.secuencias.Reserva++
+	return id
+}
+
+// ajustarSecuencias garantiza que ninguna secuencia entregue un ID ya usado
+// Se usa al migrar datos del contador compartido y al reproducir journals
+// antiguos, que no guardaban las secuencias
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) ajustarSecuencias() {
+	s := &b.secuencias
+	for _, libro := range b.Libros {
+		s.Libro = max(s.Libro, libro.ID+1)
+		for _, ejemplar := range libro.Ejemplares {
+			s.Ejemplar = max(s.Ejemplar, ejemplar.ID+1)
+		}
+	}
+	for _, usuario := range b.Usuarios {
+		s.Usuario = max(s.Usuario, usuario.ID+1)
+	}
+	for _, prestamo := range b.Prestamos {
+		s.Prestamo = max(s.Prestamo, prestamo.ID+1)
+	}
+	for _, reserva := range b.Reservas {
+		s.Reserva = max(s.Reserva, reserva.ID+1)
+	}
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// snapshotContadorCompartido es un archivo de la versión 2: libros, ejemplares,
+// usuarios y préstamos tomaban IDs del mismo ProximoID
+const snapshotContadorCompartido = `{
+  "Version": 2,
+  "Nombre": "Central",
+  "Libros": [
+    {"ID": 1, "Titulo": "Ficciones", "Autor": "Borges", "Paginas": 224,
+     "Ejemplares": [{"ID": 2, "CodigoBarras": "EJ-000002", "Prestado": true}]},
+    {"ID": 5, "Titulo": "Rayuela", "Autor": "Cortázar", "Paginas": 600,
+     "Ejemplares": [{"ID": 6, "CodigoBarras": "EJ-000006"}]}
+  ],
+  "Usuarios": [{"ID": 3, "Nombre": "Ana", "Email": "ana@test", "Activo": true, "Categoria": "estudiante"}],
+  "Prestamos": [{"ID": 4, "LibroID": 1, "UsuarioID": 3, "EjemplarID": 2,
+                 "FechaPrestamo": "2026-03-02T10:00:00Z", "FechaDevolucion": "2026-03-16T20:00:00Z"}],
+  "ProximoID": 7
+}`
+
+func TestCargarMigraElContadorCompartido(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "biblioteca.json")
+	if err := os.WriteFile(path, []byte(snapshotContadorCompartido), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	b, err := CargarDesde(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// los IDs existentes se conservan
+	if usuario := b.BuscarUsuario(3); usuario == nil || usuario.Nombre != "Ana" {
+		t.Fatalf("El usuario 3 debería conservar su ID: %+v", usuario)
+	}
+	if prestamo := b.BuscarPrestamo(4); prestamo == nil || prestamo.EjemplarID != 2 {
+		t.Fatalf("El préstamo 4 debería conservar su ejemplar: %+v", prestamo)
+	}
+
+	// cada secuencia sigue desde el mayor ID de su tipo
+	libro, err := b.AgregarLibro("El Aleph", "Borges", "", 200)
+	if err != nil {
+		t.Fatal(err)
+	}
+	beto, err := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prestamo, err := b.PrestarLibro(5, beto.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if libro.ID != 6 || libro.Ejemplares[0].ID != 7 || beto.ID != 4 || prestamo.ID != 5 {
+		t.Errorf("IDs nuevos inesperados: libro %d, ejemplar %d, usuario %d, préstamo %d",
+			libro.ID, libro.Ejemplares[0].ID, beto.ID, prestamo.ID)
+	}
+
+	// al guardar queda en la versión actual y las secuencias no retroceden
+	if err := b.GuardarEn(path); err != nil {
+		t.Fatal(err)
+	}
+	recargada, err := CargarDesde(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otro, _ := recargada.AgregarLibro("Bestiario", "Cortázar", "", 150); otro.ID != 7 {
+		t.Errorf("Después de recargar el próximo libro debería ser el 7, se obtuvo %d", otro.ID)
+	}
+}
+
+func TestSecuenciasIndependientesPorEntidad(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 224)
+	usuario, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	prestamo, err := b.PrestarLibro(libro.ID, usuario.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if libro.ID != 1 || usuario.ID != 1 || prestamo.ID != 1 || libro.Ejemplares[0].ID != 1 {
+		t.Errorf("Cada entidad debería empezar en 1: libro %d, usuario %d, préstamo %d, ejemplar %d",
+			libro.ID, usuario.ID, prestamo.ID, libro.Ejemplares[0].ID)
+	}
+}
+
+func TestCargarRechazaUnaVersionDesconocida(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "biblioteca.json")
+	if err := os.WriteFile(path, []byte(`{"Version": 99, "Nombre": "Central"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := CargarDesde(path); !errors.Is(err, ErrFormatoInvalido) {
+		t.Errorf("Se esperaba ErrFormatoInvalido, se obtuvo %v", err)
+	}
+}
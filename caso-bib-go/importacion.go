@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ==========================================
+// IMPORTACIÓN MASIVA DEL CATÁLOGO (CSV/TSV)
+// ==========================================
+
+// Campos del libro que se pueden importar
+const (
+	campoTitulo     = "titulo"
+	campoAutor      = "autor"
+	campoISBN       = "isbn"
+	campoPaginas    = "paginas"
+	campoEjemplares = "ejemplares"
+)
+
+// camposImportacion son los campos válidos en OpcionesImportacion.Columnas
+var camposImportacion = []string{campoTitulo, campoAutor, campoISBN, campoPaginas, campoEjemplares}
+
+// aliasColumnas reconoce los encabezados habituales (ya normalizados)
+var aliasColumnas = map[string]string{
+	"titulo":     campoTitulo,
+	"title":      campoTitulo,
+	"autor":      campoAutor,
+	"author":     campoAutor,
+	"isbn":       campoISBN,
+	"isbn10":     campoISBN,
+	"isbn13":     campoISBN,
+	"paginas":    campoPaginas,
+	"pages":      campoPaginas,
+	"ejemplares": campoEjemplares,
+	"copias":     campoEjemplares,
+	"copies":     campoEjemplares,
+}
+
+// OpcionesImportacion configura cómo se lee el archivo
+type OpcionesImportacion struct {
+	Separador rune              // ',' o '\t'; 0 detecta según el encabezado
+	Columnas  map[string]string // encabezado del archivo -> campo, para nombres no reconocidos
+	Prueba    bool              // valida todas las filas sin modificar el catálogo
+}
+
+// ResultadoFila es el resultado de importar una fila del archivo
+// Error está en IdiomaPorDefecto; ReporteImportacion.Traducir lo cambia
+type ResultadoFila struct {
+	Fila    int // línea del CSV (el encabezado es la 1) o número de registro
+	LibroID LibroID
+	Titulo  string
+	Error   string
+	err     error
+}
+
+// ReporteImportacion resume una importación fila por fila
+type ReporteImportacion struct {
+	Prueba            bool
+	Filas             int
+	Importados        int
+	Fallidos          int
+	ColumnasIgnoradas []string
+	Resultados        []ResultadoFila
+}
+
+// filaCSV es una fila ya leída del archivo, con sus valores por campo o el
+// error de formato que impidió leerla
+type filaCSV struct {
+	linea int
+	datos map[string]string
+	err   error
+}
+
+// ImportarCSV agrega al catálogo un libro por fila de r
+// Una fila con errores se informa en el reporte y no detiene la importación;
+// solo un encabezado sin título, autor o páginas hace fallar la importación completa
+func (b *Biblioteca) ImportarCSV(r io.Reader, opciones OpcionesImportacion) (*ReporteImportacion, error) {
+	entrada := bufio.NewReader(r)
+	separador := opciones.Separador
+	if separador == 0 {
+		separador = detectarSeparador(entrada)
+	}
+
+	lector := csv.NewReader(entrada)
+	lector.Comma = separador
+	lector.FieldsPerRecord = -1
+	lector.TrimLeadingSpace = true
+	if separador == '\t' {
+		lector.LazyQuotes = true
+	}
+
+	encabezado, err := lector.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errDatosInvalidos("error.archivo_vacio")
+		}
+		return nil, &ErrorBiblioteca{Tipo: ErrDatosInvalidos, mensaje: txt("error.encabezado_ilegible"), Causa: err}
+	}
+	reporte := &ReporteImportacion{
+		Prueba:            opciones.Prueba,
+		ColumnasIgnoradas: make([]string, 0),
+		Resultados:        make([]ResultadoFila, 0),
+	}
+	columnas, err := mapearColumnas(encabezado, opciones.Columnas, reporte)
+	if err != nil {
+		return nil, err
+	}
+
+	filas, err := leerFilasCSV(lector, columnas)
+	if err != nil {
+		return nil, err
+	}
+
+	// El archivo se lee antes de tomar el lock, así una entrada lenta no
+	// frena a los demás; las filas se aplican todas juntas para que nadie
+	// vea el catálogo a medio cargar
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// en modo prueba los ISBN del archivo no llegan al índice, así que
+	// los repetidos dentro del mismo archivo se detectan aparte
+	isbnVistos := make(map[string]bool)
+
+	for _, fila := range filas {
+		resultado := ResultadoFila{Fila: fila.linea, Titulo: fila.datos[campoTitulo], err: fila.err}
+		if fila.err == nil {
+			resultado.LibroID, resultado.err = b.importarFila(fila.datos, opciones.Prueba, isbnVistos)
+		}
+		reporte.agregar(resultado)
+	}
+	return reporte, nil
+}
+
+// leerFilasCSV lee todas las filas de datos y asigna cada valor a su campo
+// Las filas en blanco se saltean
+func leerFilasCSV(lector *csv.Reader, columnas []string) ([]filaCSV, error) {
+	var filas []filaCSV
+	for {
+		registro, err := lector.Read()
+		if errors.Is(err, io.EOF) {
+			return filas, nil
+		}
+		if err != nil {
+			var errorCSV *csv.ParseError
+			if !errors.As(err, &errorCSV) {
+				return nil, errArchivo(err, "error.leer_registros")
+			}
+			filas = append(filas, filaCSV{
+				linea: errorCSV.StartLine,
+				err:   errFormato(errorCSV.Err, "error.csv_columna", errorCSV.Column),
+			})
+			continue
+		}
+		linea, _ := lector.FieldPos(0)
+		if filaVacia(registro) {
+			continue
+		}
+
+		datos := make(map[string]string)
+		for i, valor := range registro {
+			if i < len(columnas) && columnas[i] != "" {
+				datos[columnas[i]] = strings.TrimSpace(valor)
+			}
+		}
+		filas = append(filas, filaCSV{linea: linea, datos: datos})
+	}
+}
+
+// importarFila valida una fila y, salvo en modo prueba, agrega el libro
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) importarFila(datos map[string]string, prueba bool, isbnVistos map[string]bool) (LibroID, error) {
+	// un libro sin páginas no se puede prestar: la columna es obligatoria
+	if datos[campoPaginas] == "" {
+		return 0, errDatosInvalidos("error.faltan_paginas")
+	}
+	paginas, err := strconv.Atoi(datos[campoPaginas])
+	if err != nil || paginas <= 0 {
+		return 0, errDatosInvalidos("error.paginas_invalidas", datos[campoPaginas])
+	}
+	ejemplares, err := enteroOpcional(datos[campoEjemplares], 1)
+	if err != nil || ejemplares < 1 {
+		return 0, errDatosInvalidos("error.ejemplares_invalidos", datos[campoEjemplares])
+	}
+
+	libro := Libro{Titulo: datos[campoTitulo], Autor: datos[campoAutor], ISBN: datos[campoISBN], Paginas: paginas}
+	return b.importarLibro(libro, ejemplares, prueba, isbnVistos)
+}
+
+// importarLibro agrega un libro leído de un archivo o, en modo prueba, solo lo valida
+// El ID del libro recibido se ignora: se asigna uno nuevo de la secuencia
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) importarLibro(libro Libro, ejemplares int, prueba bool, isbnVistos map[string]bool) (LibroID, error) {
+	if !prueba {
+		agregado, err := b.agregarLibro(libro.Titulo, libro.Autor, libro.ISBN, libro.Paginas, ejemplares)
+		if err != nil {
+			return 0, err
+		}
+		return agregado.ID, nil
+	}
+
+	isbn, err := b.validarLibroNuevo(libro.Titulo, libro.Autor, libro.ISBN)
+	if err != nil {
+		return 0, err
+	}
+	// mismo error que daría la importación real al llegar a esta fila
+	if isbn != "" && isbnVistos[isbn] {
+		return 0, errISBNDuplicado(isbn, 0)
+	}
+	isbnVistos[isbn] = true
+	return 0, nil
+}
+
+// agregar suma el resultado de una fila al reporte
+func (r *ReporteImportacion) agregar(resultado ResultadoFila) {
+	r.Filas++
+	if resultado.err == nil {
+		r.Importados++
+	} else {
+		resultado.Error = MensajeDeError(resultado.err, IdiomaPorDefecto)
+		r.Fallidos++
+	}
+	r.Resultados = append(r.Resultados, resultado)
+}
+
+// Traducir pasa el error de cada fila al idioma indicado
+func (r *ReporteImportacion) Traducir(idioma Idioma) {
+	for i, resultado := range r.Resultados {
+		if resultado.err != nil {
+			r.Resultados[i].Error = MensajeDeError(resultado.err, idioma)
+		}
+	}
+}
+
+// mapearColumnas asigna un campo a cada columna del encabezado
+// Las columnas que no corresponden a ningún campo se anotan como ignoradas
+func mapearColumnas(encabezado []string, extra map[string]string, reporte *ReporteImportacion) ([]string, error) {
+	alias := make(map[string]string, len(aliasColumnas)+len(extra))
+	for nombre, campo := range aliasColumnas {
+		alias[nombre] = campo
+	}
+	for nombre, campo := range extra {
+		campo = normalizarTexto(strings.TrimSpace(campo))
+		if !slices.Contains(camposImportacion, campo) {
+			return nil, errDatosInvalidos("error.campo_desconocido",
+				campo, nombre, strings.Join(camposImportacion, ", "))
+		}
+		alias[normalizarTexto(strings.TrimSpace(nombre))] = campo
+	}
+
+	columnas := make([]string, len(encabezado))
+	asignadas := make(map[string]string)
+	for i, nombre := range encabezado {
+		nombre = strings.TrimSpace(strings.TrimPrefix(nombre, "\ufeff"))
+		campo, ok := alias[normalizarTexto(nombre)]
+		if !ok {
+			reporte.ColumnasIgnoradas = append(reporte.ColumnasIgnoradas, nombre)
+			continue
+		}
+		if anterior, repetida := asignadas[campo]; repetida {
+			return nil, errDatosInvalidos("error.columnas_repetidas", anterior, nombre, campo)
+		}
+		asignadas[campo] = nombre
+		columnas[i] = campo
+	}
+
+	for _, campo := range []string{campoTitulo, campoAutor, campoPaginas} {
+		if _, ok := asignadas[campo]; !ok {
+			return nil, errDatosInvalidos("error.falta_columna", campo)
+		}
+	}
+	return columnas, nil
+}
+
+// detectarSeparador mira la primera línea: si tiene tabuladores es TSV
+func detectarSeparador(entrada *bufio.Reader) rune {
+	// Peek retorna lo que haya si el archivo es más corto que el buffer
+	inicio, _ := entrada.Peek(entrada.Size())
+	linea, _, _ := strings.Cut(string(inicio), "\n")
+	if strings.ContainsRune(linea, '\t') {
+		return '\t'
+	}
+	return ','
+}
+
+// enteroOpcional convierte texto en entero; vacío retorna porDefecto
+func enteroOpcional(texto string, porDefecto int) (int, error) {
+	if texto == "" {
+		return porDefecto, nil
+	}
+	return strconv.Atoi(texto)
+}
+
+// filaVacia indica si todas las celdas de la fila están en blanco
+func filaVacia(registro []string) bool {
+	for _, valor := range registro {
+		if strings.TrimSpace(valor) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// nuevasOpcionesImportacion arma las opciones a partir de texto de la CLI o la API
+// separador es "coma", "tab" o vacío; cada columna tiene la forma "Encabezado=campo"
+func nuevasOpcionesImportacion(separador string, columnas []string, prueba bool) (OpcionesImportacion, error) {
+	opciones := OpcionesImportacion{Columnas: make(map[string]string), Prueba: prueba}
+	switch separador {
+	case "":
+	case "coma", ",":
+		opciones.Separador = ','
+	case "tab", "\t":
+		opciones.Separador = '\t'
+	default:
+		return opciones, errDatosInvalidos("error.separador_desconocido", separador)
+	}
+	for _, columna := range columnas {
+		encabezado, campo, ok := strings.Cut(columna, "=")
+		if !ok || encabezado == "" {
+			return opciones, errDatosInvalidos("error.columna_invalida", columna)
+		}
+		opciones.Columnas[encabezado] = campo
+	}
+	return opciones, nil
+}
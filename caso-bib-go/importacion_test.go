@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// lectorVigilado anota si la biblioteca estaba bloqueada mientras se leía
+type lectorVigilado struct {
+	io.Reader
+	b         *Biblioteca
+	bloqueada bool
+}
+
+func (l *lectorVigilado) Read(p []byte) (int, error) {
+	if l.b.mu.TryRLock() {
+		l.b.mu.RUnlock()
+	} else {
+		l.bloqueada = true
+	}
+	// de a pocos bytes, para que la lectura siga después del encabezado
+	return l.Reader.Read(p[:min(len(p), 8)])
+}
+
+func TestImportarCSVLeeElArchivoSinBloquear(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	lector := &lectorVigilado{
+		Reader: strings.NewReader("titulo,autor,paginas\nRayuela,Cortázar,600\nFicciones,Borges,200\n"),
+		b:      b,
+	}
+	// con el separador indicado no se adelanta la lectura para detectarlo
+	reporte, err := b.ImportarCSV(lector, OpcionesImportacion{Separador: ','})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reporte.Importados != 2 || len(b.Libros) != 2 {
+		t.Errorf("Se esperaban 2 libros importados: %+v", reporte)
+	}
+	if lector.bloqueada {
+		t.Error("La biblioteca no debería estar bloqueada mientras se lee el archivo")
+	}
+}
+
+func TestImportarCSVExigeLasPaginas(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	_, err := b.ImportarCSV(strings.NewReader("titulo,autor\nRayuela,Cortázar\n"), OpcionesImportacion{})
+	if !errors.Is(err, ErrDatosInvalidos) {
+		t.Fatalf("Sin columna de páginas se esperaba un error de datos, se obtuvo %v", err)
+	}
+
+	reporte, err := b.ImportarCSV(strings.NewReader("titulo,autor,paginas\nRayuela,Cortázar,\nFicciones,Borges,0\nEl Aleph,Borges,150\n"), OpcionesImportacion{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reporte.Importados != 1 || reporte.Fallidos != 2 {
+		t.Fatalf("Se esperaba 1 importado y 2 fallidos: %+v", reporte)
+	}
+	for _, resultado := range reporte.Resultados[:2] {
+		if !errors.Is(resultado.err, ErrDatosInvalidos) {
+			t.Errorf("Fila %d: se esperaba un error de páginas, se obtuvo %v", resultado.Fila, resultado.err)
+		}
+	}
+	if libros := b.ListarLibros(); len(libros) != 1 || libros[0].Titulo != "El Aleph" {
+		t.Errorf("Solo debería estar El Aleph: %+v", libros)
+	}
+}
@@ -0,0 +1,158 @@
+package main
+
+// ==========================================
+// ÍNDICES POR ID, ISBN, EMAIL Y EJEMPLAR
+// ==========================================
+
+// indicesBiblioteca permite búsquedas en O(1) en lugar de recorrer los slices
+// Guarda posiciones dentro de los slices (no punteros), así siguen siendo
+// válidas cuando append realoca el arreglo
+// Todos los mapas se leen y escriben con b.mu tomado
+type indicesBiblioteca struct {
+	libros    map[LibroID]int    // ID de libro → posición en b.Libros
+	usuarios  map[UsuarioID]int  // ID de usuario → posición en b.Usuarios
+	prestamos map[PrestamoID]int // ID de préstamo → posición en b.Prestamos
+	reservas  map[ReservaID]int  // ID de reserva → posición en b.Reservas
+
+	isbn       map[string]LibroID     // ISBN canónico → ID de libro
+	emails     map[string]UsuarioID   // email → ID de usuario
+	ejemplares map[EjemplarID]LibroID // ID de ejemplar → ID de libro
+	codigos    map[string]EjemplarID  // código de barras → ID de ejemplar
+
+	// prestamoActivo asocia cada ejemplar prestado con su préstamo sin devolver
+	prestamoActivo map[EjemplarID]PrestamoID
+
+	// posiciones en b.Prestamos de todos los préstamos de cada usuario y libro
+	prestamosUsuario map[UsuarioID][]int
+	prestamosLibro   map[LibroID][]int
+}
+
+func nuevosIndices() indicesBiblioteca {
+	return indicesBiblioteca{
+		libros:         make(map[LibroID]int),
+		usuarios:       make(map[UsuarioID]int),
+		prestamos:      make(map[PrestamoID]int),
+		reservas:       make(map[ReservaID]int),
+		isbn:           make(map[string]LibroID),
+		emails:         make(map[string]UsuarioID),
+		ejemplares:     make(map[EjemplarID]LibroID),
+		codigos:        make(map[string]EjemplarID),
+		prestamoActivo: make(map[EjemplarID]PrestamoID),
+
+		prestamosUsuario: make(map[UsuarioID][]int),
+		prestamosLibro:   make(map[LibroID][]int),
+	}
+}
+
+// claveISBN es la clave del índice de ISBN: la forma canónica, o la forma
+// normalizada para valores que no son ISBN válidos (datos antiguos)
+func claveISBN(isbn string) string {
+	if canonico, err := CanonizarISBN(isbn); err == nil {
+		return canonico
+	}
+	return NormalizarISBN(isbn)
+}
+
+// indexarLibro registra el libro de la posición pos y sus ejemplares
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) indexarLibro(pos int) {
+	libro := b.Libros[pos]
+	b.indices.libros[libro.ID] = pos
+	if libro.ISBN != "" {
+		b.indices.isbn[claveISBN(libro.ISBN)] = libro.ID
+	}
+	for _, ejemplar := range libro.Ejemplares {
+		b.indices.ejemplares[ejemplar.ID] = libro.ID
+		b.indices.codigos[ejemplar.CodigoBarras] = ejemplar.ID
+	}
+}
+
+// indexarUsuario registra el usuario de la posición pos
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) indexarUsuario(pos int) {
+	usuario := b.Usuarios[pos]
+	b.indices.usuarios[usuario.ID] = pos
+	b.indices.emails[usuario.Email] = usuario.ID
+}
+
+// indexarPrestamo registra el préstamo de la posición pos y actualiza el
+// préstamo activo de su ejemplar
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) indexarPrestamo(pos int) {
+	prestamo := b.Prestamos[pos]
+	if _, existe := b.indices.prestamos[prestamo.ID]; !existe {
+		b.indices.prestamosUsuario[prestamo.UsuarioID] = append(b.indices.prestamosUsuario[prestamo.UsuarioID], pos)
+		b.indices.prestamosLibro[prestamo.LibroID] = append(b.indices.prestamosLibro[prestamo.LibroID], pos)
+	}
+	b.indices.prestamos[prestamo.ID] = pos
+	if !prestamo.Devuelto {
+		b.indices.prestamoActivo[prestamo.EjemplarID] = prestamo.ID
+	} else if b.indices.prestamoActivo[prestamo.EjemplarID] == prestamo.ID {
+		delete(b.indices.prestamoActivo, prestamo.EjemplarID)
+	}
+}
+
+// desindexarUltimoPrestamo quita de los índices el último préstamo agregado
+// Se usa para deshacer un préstamo cuyo evento no se pudo registrar
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) desindexarUltimoPrestamo() {
+	prestamo := b.Prestamos[len(b.Prestamos)-1]
+	delete(b.indices.prestamos, prestamo.ID)
+	if b.indices.prestamoActivo[prestamo.EjemplarID] == prestamo.ID {
+		delete(b.indices.prestamoActivo, prestamo.EjemplarID)
+	}
+	// indexarPrestamo agregó la posición al final de cada lista
+	delUsuario := b.indices.prestamosUsuario[prestamo.UsuarioID]
+	b.indices.prestamosUsuario[prestamo.UsuarioID] = delUsuario[:len(delUsuario)-1]
+	delLibro := b.indices.prestamosLibro[prestamo.LibroID]
+	b.indices.prestamosLibro[prestamo.LibroID] = delLibro[:len(delLibro)-1]
+}
+
+// indexarReserva registra la reserva de la posición pos
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) indexarReserva(pos int) {
+	b.indices.reservas[b.Reservas[pos].ID] = pos
+}
+
+// buscarLibroPorISBN retorna el libro con el mismo ISBN, si existe
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) buscarLibroPorISBN(isbn string) *Libro {
+	id, ok := b.indices.isbn[claveISBN(isbn)]
+	if !ok {
+		return nil
+	}
+	return b.buscarLibro(id)
+}
+
+// buscarUsuarioPorEmail retorna el usuario con ese email, si existe
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) buscarUsuarioPorEmail(email string) *Usuario {
+	id, ok := b.indices.emails[email]
+	if !ok {
+		return nil
+	}
+	return b.buscarUsuario(id)
+}
+
+// prestamoActivoDe retorna el préstamo sin devolver de un ejemplar, si existe
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) prestamoActivoDe(ejemplarID EjemplarID) *Prestamo {
+	id, ok := b.indices.prestamoActivo[ejemplarID]
+	if !ok {
+		return nil
+	}
+	return b.buscarPrestamo(id)
+}
+
+// prestamosActivosDe retorna los préstamos sin devolver de un libro
+// Recorre solo sus ejemplares, no todos los préstamos
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) prestamosActivosDe(libro *Libro) []*Prestamo {
+	var activos []*Prestamo
+	for _, ejemplar := range libro.Ejemplares {
+		if prestamo := b.prestamoActivoDe(ejemplar.ID); prestamo != nil {
+			activos = append(activos, prestamo)
+		}
+	}
+	return activos
+}
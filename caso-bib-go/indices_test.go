@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+)
+
+// tamanosBenchmark son las cantidades de libros, usuarios y préstamos con
+// que se miden las búsquedas: deberían tardar lo mismo en todas
+var tamanosBenchmark = []int{1_000, 10_000, 100_000, 1_000_000}
+
+// bibliotecasBenchmark guarda las bibliotecas ya armadas por tamaño, porque
+// la de un millón tarda más en armarse que todas las mediciones juntas
+var bibliotecasBenchmark = map[int]*Biblioteca{}
+
+// bibliotecaBenchmark arma una biblioteca con n libros de un ejemplar, n
+// usuarios y n préstamos activos, sin índice de texto (no se mide)
+func bibliotecaBenchmark(n int) *Biblioteca {
+	if b, ok := bibliotecasBenchmark[n]; ok {
+		return b
+	}
+	b := NuevaBiblioteca("Benchmark", "")
+	b.Libros = make([]Libro, n)
+	b.Usuarios = make([]Usuario, n)
+	b.Prestamos = make([]Prestamo, n)
+	for i := range n {
+		b.Libros[i] = Libro{ID: LibroID(i + 1), Titulo: "Libro " + strconv.Itoa(i), Autor: "Autor", Paginas: 100,
+			ISBN: isbnBenchmark(i), Ejemplares: []Ejemplar{{ID: EjemplarID(i + 1), CodigoBarras: fmt.Sprintf("EJ-%07d", i+1), Prestado: true}}}
+		b.Usuarios[i] = Usuario{ID: UsuarioID(i + 1), Nombre: "Usuario", Email: emailBenchmark(i), Activo: true}
+		b.Prestamos[i] = Prestamo{ID: PrestamoID(i + 1), LibroID: LibroID(i + 1), UsuarioID: UsuarioID(i + 1), EjemplarID: EjemplarID(i + 1)}
+		b.indexarLibro(i)
+		b.indexarUsuario(i)
+		b.indexarPrestamo(i)
+	}
+	bibliotecasBenchmark[n] = b
+	return b
+}
+
+// isbnBenchmark retorna un ISBN-13 válido y distinto para cada i
+func isbnBenchmark(i int) string {
+	isbn := fmt.Sprintf("978%09d", i)
+	suma := 0
+	for j, digito := range isbn {
+		peso := 1
+		if j%2 == 1 {
+			peso = 3
+		}
+		suma += int(digito-'0') * peso
+	}
+	return isbn + strconv.Itoa((10-suma%10)%10)
+}
+
+func emailBenchmark(i int) string {
+	return "usuario" + strconv.Itoa(i) + "@test"
+}
+
+// medirBusqueda corre buscar en cada tamaño, con claves repartidas en toda
+// la biblioteca para no medir siempre el mismo elemento
+func medirBusqueda(b *testing.B, buscar func(bib *Biblioteca, i int) bool) {
+	for _, n := range tamanosBenchmark {
+		b.Run(strconv.Itoa(n), func(b *testing.B) {
+			bib := bibliotecaBenchmark(n)
+			for i := 0; b.Loop(); i++ {
+				if !buscar(bib, (i*7919)%n) {
+					b.Fatal("No se encontró el elemento buscado")
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkBuscarLibro(b *testing.B) {
+	medirBusqueda(b, func(bib *Biblioteca, i int) bool {
+		return bib.buscarLibro(LibroID(i+1)) != nil
+	})
+}
+
+func BenchmarkBuscarUsuarioPorEmail(b *testing.B) {
+	emails := make([]string, tamanosBenchmark[len(tamanosBenchmark)-1])
+	for i := range emails {
+		emails[i] = emailBenchmark(i)
+	}
+	medirBusqueda(b, func(bib *Biblioteca, i int) bool {
+		return bib.buscarUsuarioPorEmail(emails[i]) != nil
+	})
+}
+
+func BenchmarkBuscarLibroPorISBN(b *testing.B) {
+	isbns := make([]string, tamanosBenchmark[len(tamanosBenchmark)-1])
+	for i := range isbns {
+		isbns[i] = isbnBenchmark(i)
+	}
+	medirBusqueda(b, func(bib *Biblioteca, i int) bool {
+		return bib.buscarLibroPorISBN(isbns[i]) != nil
+	})
+}
+
+func BenchmarkPrestamoActivoDe(b *testing.B) {
+	medirBusqueda(b, func(bib *Biblioteca, i int) bool {
+		return bib.prestamoActivoDe(EjemplarID(i+1)) != nil
+	})
+}
@@ -0,0 +1,133 @@
+package main
+
+import "io"
+
+// ==========================================
+// INTERCAMBIO DE REGISTROS CON OTRAS BIBLIOTECAS
+// ==========================================
+
+// FormatoRegistro es un formato de intercambio bibliográfico
+type FormatoRegistro string
+
+const (
+	FormatoISO2709    FormatoRegistro = "marc"    // MARC21 en ISO 2709
+	FormatoMARCXML    FormatoRegistro = "marcxml" // MARC21 en XML (esquema slim)
+	FormatoDublinCore FormatoRegistro = "dc"      // Dublin Core simple (oai_dc)
+)
+
+// ValidarFormato verifica que el formato de intercambio exista
+func ValidarFormato(formato FormatoRegistro) error {
+	switch formato {
+	case FormatoISO2709, FormatoMARCXML, FormatoDublinCore:
+		return nil
+	}
+	return errDatosInvalidos("error.formato_desconocido", formato)
+}
+
+// TipoContenido retorna el tipo MIME del formato para la API
+func (f FormatoRegistro) TipoContenido() string {
+	if f == FormatoISO2709 {
+		return "application/marc"
+	}
+	return "application/xml; charset=utf-8"
+}
+
+// EscribirLibros escribe los libros en el formato indicado
+func EscribirLibros(w io.Writer, libros []Libro, formato FormatoRegistro) error {
+	switch formato {
+	case FormatoISO2709, FormatoMARCXML:
+		registros := make([]RegistroMARC, 0, len(libros))
+		for _, libro := range libros {
+			registros = append(registros, MARCDeLibro(libro))
+		}
+		if formato == FormatoISO2709 {
+			return EscribirISO2709(w, registros)
+		}
+		return EscribirMARCXML(w, registros)
+	case FormatoDublinCore:
+		registros := make([]RegistroDC, 0, len(libros))
+		for _, libro := range libros {
+			registros = append(registros, DCDeLibro(libro))
+		}
+		return EscribirDublinCore(w, registros)
+	}
+	return ValidarFormato(formato)
+}
+
+// LeerLibros lee los registros de r en el formato indicado y los convierte en libros
+func LeerLibros(r io.Reader, formato FormatoRegistro) ([]Libro, error) {
+	libros := make([]Libro, 0)
+	switch formato {
+	case FormatoISO2709, FormatoMARCXML:
+		leer := LeerISO2709
+		if formato == FormatoMARCXML {
+			leer = LeerMARCXML
+		}
+		registros, err := leer(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, registro := range registros {
+			libros = append(libros, LibroDeMARC(registro))
+		}
+	case FormatoDublinCore:
+		registros, err := LeerDublinCore(r)
+		if err != nil {
+			return nil, err
+		}
+		for _, registro := range registros {
+			libros = append(libros, LibroDeDC(registro))
+		}
+	default:
+		return nil, ValidarFormato(formato)
+	}
+	return libros, nil
+}
+
+// ExportarCatalogo escribe todo el catálogo en el formato indicado
+func (b *Biblioteca) ExportarCatalogo(w io.Writer, formato FormatoRegistro) error {
+	if err := ValidarFormato(formato); err != nil {
+		return err
+	}
+	return EscribirLibros(w, b.ListarLibros(), formato)
+}
+
+// ExportarLibro escribe un único libro en el formato indicado
+func (b *Biblioteca) ExportarLibro(w io.Writer, id LibroID, formato FormatoRegistro) error {
+	if err := ValidarFormato(formato); err != nil {
+		return err
+	}
+	libro := b.BuscarLibro(id)
+	if libro == nil {
+		return errLibroNoEncontrado(id)
+	}
+	return EscribirLibros(w, []Libro{*libro}, formato)
+}
+
+// ImportarRegistros agrega al catálogo los libros de un archivo MARC21 o
+// Dublin Core, con un ejemplar cada uno. Igual que ImportarCSV, un registro
+// inválido se informa en el reporte sin detener la importación; Fila es
+// el número de registro dentro del archivo
+func (b *Biblioteca) ImportarRegistros(r io.Reader, formato FormatoRegistro, prueba bool) (*ReporteImportacion, error) {
+	libros, err := LeerLibros(r, formato)
+	if err != nil {
+		return nil, datosInvalidos(err)
+	}
+
+	reporte := &ReporteImportacion{
+		Prueba:            prueba,
+		ColumnasIgnoradas: make([]string, 0),
+		Resultados:        make([]ResultadoFila, 0, len(libros)),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	isbnVistos := make(map[string]bool)
+	for i, libro := range libros {
+		resultado := ResultadoFila{Fila: i + 1, Titulo: libro.Titulo}
+		resultado.LibroID, resultado.err = b.importarLibro(libro, 1, prueba, isbnVistos)
+		reporte.agregar(resultado)
+	}
+	return reporte, nil
+}
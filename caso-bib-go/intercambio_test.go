@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// librosDeIntercambio tiene títulos y autores que terminan o contienen
+// signos que ISBD usa como separadores
+var librosDeIntercambio = []Libro{
+	{ID: 1, Titulo: "Ficciones", Autor: "Borges, Jorge Luis", ISBN: "9788420633121", Paginas: 224},
+	{ID: 2, Titulo: "Título: subtítulo /", Autor: "Autor, Uno,", Paginas: 10},
+	{ID: 3, Titulo: "¿Quién teme? ;", Autor: "AC/DC", ISBN: "0306406152"},
+	{ID: 4, Titulo: "Álgebra = Algebra", Autor: "Baldor, Aurelio :", Paginas: 574},
+}
+
+func TestIntercambioIdaYVuelta(t *testing.T) {
+	for _, formato := range []FormatoRegistro{FormatoISO2709, FormatoMARCXML, FormatoDublinCore} {
+		t.Run(string(formato), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := EscribirLibros(&buf, librosDeIntercambio, formato); err != nil {
+				t.Fatal(err)
+			}
+			leidos, err := LeerLibros(&buf, formato)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(leidos) != len(librosDeIntercambio) {
+				t.Fatalf("Se leyeron %d libros, se esperaban %d", len(leidos), len(librosDeIntercambio))
+			}
+			for i, esperado := range librosDeIntercambio {
+				obtenido := leidos[i]
+				if obtenido.ID != esperado.ID || obtenido.Titulo != esperado.Titulo || obtenido.Autor != esperado.Autor ||
+					obtenido.ISBN != esperado.ISBN || obtenido.Paginas != esperado.Paginas {
+					t.Errorf("Libro %d\nesperado: %+v\nobtenido: %+v", i, esperado, obtenido)
+				}
+			}
+		})
+	}
+}
+
+func TestLibroDeMARCQuitaLaPuntuacionISBD(t *testing.T) {
+	// registro de otro catálogo, con puntuación ISBD (líder 'i')
+	registro := RegistroMARC{
+		Lider: "00000nam a2200000 i 4500",
+		Campos: []CampoMARC{
+			{Etiqueta: "100", Ind1: "1", Ind2: " ", Subcampos: []SubcampoMARC{{Codigo: "a", Valor: "García Márquez, Gabriel,"}}},
+			{Etiqueta: "245", Ind1: "1", Ind2: "0", Subcampos: []SubcampoMARC{{Codigo: "a", Valor: "Cien años de soledad /"}}},
+			{Etiqueta: "300", Ind1: " ", Ind2: " ", Subcampos: []SubcampoMARC{{Codigo: "a", Valor: "xii, 471 p. :"}}},
+		},
+	}
+	libro := LibroDeMARC(registro)
+	if libro.Titulo != "Cien años de soledad" || libro.Autor != "García Márquez, Gabriel" || libro.Paginas != 471 {
+		t.Errorf("Libro inesperado: %+v", libro)
+	}
+
+	// solo se quita el separador final, no lo que forma parte del título
+	registro.Campos[1].Subcampos[0].Valor = "Título: subtítulo / otro /"
+	if titulo := LibroDeMARC(registro).Titulo; titulo != "Título: subtítulo / otro" {
+		t.Errorf("Título inesperado %q", titulo)
+	}
+}
+
+func TestAPIExportacionConErrorNoEnviaUnCuerpoParcial(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	if _, err := b.AgregarLibro("Ficciones", "Borges", "", 200); err != nil {
+		t.Fatal(err)
+	}
+	// un campo de más de 9999 bytes no entra en ISO 2709
+	if _, err := b.AgregarLibro(strings.Repeat("x", 10000), "Autor", "", 100); err != nil {
+		t.Fatal(err)
+	}
+	api := NuevoServidorAPI(b)
+
+	for _, ruta := range []string{"/libros/exportacion?formato=marc", "/libros/2/exportacion?formato=marc"} {
+		rec := httptest.NewRecorder()
+		api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, ruta, nil))
+		if rec.Code != http.StatusConflict {
+			t.Errorf("%s: se esperaba %d, se obtuvo %d", ruta, http.StatusConflict, rec.Code)
+		}
+		if tipo := rec.Header().Get("Content-Type"); !strings.HasPrefix(tipo, "application/json") {
+			t.Errorf("%s: el error debería responderse en JSON, se obtuvo %q", ruta, tipo)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	api.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/libros/1/exportacion?formato=marcxml", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "Ficciones") {
+		t.Errorf("Exportación inesperada (%d): %s", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,271 @@
+package main
+
+import "strings"
+
+// ==========================================
+// ISBN: VALIDACIÓN, NORMALIZACIÓN Y CONVERSIÓN
+// ==========================================
+
+// NormalizarISBN quita guiones y espacios y pasa la X final a mayúscula
+// No valida: "978-84-376-0494-7" y "9788437604947" quedan iguales
+func NormalizarISBN(isbn string) string {
+	var sb strings.Builder
+	for _, r := range isbn {
+		switch {
+		case r == '-' || r == ' ':
+			continue
+		case r == 'x':
+			sb.WriteRune('X')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
+
+// ValidarISBN verifica el formato y el dígito de control de un ISBN-10 o ISBN-13
+func ValidarISBN(isbn string) error {
+	normal := NormalizarISBN(isbn)
+	switch len(normal) {
+	case 10:
+		for i, r := range normal {
+			if (r < '0' || r > '9') && !(r == 'X' && i == 9) {
+				return errISBNInvalido(isbn, txt("isbn.caracter", r))
+			}
+		}
+		if control10(normal[:9]) != normal[9] {
+			return errISBNInvalido(isbn, txt("isbn.control"))
+		}
+	case 13:
+		for _, r := range normal {
+			if r < '0' || r > '9' {
+				return errISBNInvalido(isbn, txt("isbn.caracter", r))
+			}
+		}
+		if !strings.HasPrefix(normal, "978") && !strings.HasPrefix(normal, "979") {
+			return errISBNInvalido(isbn, txt("isbn.prefijo"))
+		}
+		if control13(normal[:12]) != normal[12] {
+			return errISBNInvalido(isbn, txt("isbn.control"))
+		}
+	default:
+		return errISBNInvalido(isbn, txt("isbn.largo"))
+	}
+	return nil
+}
+
+// errISBNInvalido es el error de ValidarISBN; Valor es el ISBN tal como vino
+func errISBNInvalido(isbn string, motivo texto) error {
+	return &ErrorBiblioteca{Tipo: ErrDatosInvalidos, Valor: isbn, mensaje: txt("error.isbn_invalido", isbn, motivo)}
+}
+
+// ISBN10a13 convierte un ISBN-10 válido a ISBN-13 (sin guiones)
+func ISBN10a13(isbn string) (string, error) {
+	if err := ValidarISBN(isbn); err != nil {
+		return "", err
+	}
+	normal := NormalizarISBN(isbn)
+	if len(normal) != 10 {
+		return "", &ErrorBiblioteca{Tipo: ErrDatosInvalidos, Valor: isbn,
+			mensaje: txt("error.no_es_isbn10", isbn)}
+	}
+	base := "978" + normal[:9]
+	return base + string(control13(base)), nil
+}
+
+// ISBN13a10 convierte un ISBN-13 válido a ISBN-10 (sin guiones)
+// Solo los ISBN con prefijo 978 tienen equivalente de 10 dígitos
+func ISBN13a10(isbn string) (string, error) {
+	if err := ValidarISBN(isbn); err != nil {
+		return "", err
+	}
+	normal := NormalizarISBN(isbn)
+	if len(normal) != 13 {
+		return "", &ErrorBiblioteca{Tipo: ErrDatosInvalidos, Valor: isbn,
+			mensaje: txt("error.no_es_isbn13", isbn)}
+	}
+	if !strings.HasPrefix(normal, "978") {
+		return "", &ErrorBiblioteca{Tipo: ErrDatosInvalidos, Valor: isbn,
+			mensaje: txt("error.sin_isbn10", isbn)}
+	}
+	base := normal[3:12]
+	return base + string(control10(base)), nil
+}
+
+// CanonizarISBN valida un ISBN y retorna su forma canónica: ISBN-13 sin guiones
+func CanonizarISBN(isbn string) (string, error) {
+	if err := ValidarISBN(isbn); err != nil {
+		return "", err
+	}
+	normal := NormalizarISBN(isbn)
+	if len(normal) == 10 {
+		return ISBN10a13(normal)
+	}
+	return normal, nil
+}
+
+// FormatearISBN separa un ISBN válido con guiones para mostrarlo
+// ("9788437604947" → "978-84-376-0494-7"). Si no se conoce el rango de la
+// editorial se separa solo prefijo, grupo y dígito de control; si el ISBN
+// no es válido se retorna tal cual
+func FormatearISBN(isbn string) string {
+	if ValidarISBN(isbn) != nil {
+		return isbn
+	}
+	normal := NormalizarISBN(isbn)
+	isbn13 := normal
+	if len(normal) == 10 {
+		isbn13, _ = ISBN10a13(normal)
+	}
+
+	prefijo := isbn13[:3]
+	resto := isbn13[3:12]
+	partes := []string{prefijo}
+
+	largoGrupo := largoSegunRangos(rangosGrupo[prefijo], resto)
+	if largoGrupo > 0 {
+		grupo := resto[:largoGrupo]
+		partes = append(partes, grupo)
+		resto = resto[largoGrupo:]
+
+		largoEditorial := largoSegunRangos(rangosEditorial[prefijo+"-"+grupo], resto)
+		if largoEditorial > 0 && largoEditorial < len(resto) {
+			partes = append(partes, resto[:largoEditorial])
+			resto = resto[largoEditorial:]
+		}
+	}
+	partes = append(partes, resto)
+
+	if len(normal) == 10 {
+		partes = partes[1:]
+		partes = append(partes, normal[9:])
+	} else {
+		partes = append(partes, isbn13[12:])
+	}
+	return strings.Join(partes, "-")
+}
+
+// control10 calcula el dígito de control de los 9 primeros dígitos de un ISBN-10
+func control10(base string) byte {
+	suma := 0
+	for i := 0; i < 9; i++ {
+		suma += int(base[i]-'0') * (10 - i)
+	}
+	control := (11 - suma%11) % 11
+	if control == 10 {
+		return 'X'
+	}
+	return byte('0' + control)
+}
+
+// control13 calcula el dígito de control de los 12 primeros dígitos de un ISBN-13
+func control13(base string) byte {
+	suma := 0
+	for i := 0; i < 12; i++ {
+		peso := 1
+		if i%2 == 1 {
+			peso = 3
+		}
+		suma += int(base[i]-'0') * peso
+	}
+	return byte('0' + (10-suma%10)%10)
+}
+
+// rangoISBN asigna un largo de segmento a los valores entre desde y hasta
+// (comparando los siete dígitos siguientes del ISBN)
+type rangoISBN struct {
+	desde, hasta string
+	largo        int
+}
+
+// largoSegunRangos retorna el largo del segmento que empieza en digitos, o 0
+// si ningún rango lo cubre
+func largoSegunRangos(rangos []rangoISBN, digitos string) int {
+	clave := (digitos + "0000000")[:7]
+	for _, r := range rangos {
+		if clave >= r.desde && clave <= r.hasta {
+			return r.largo
+		}
+	}
+	return 0
+}
+
+// rangosGrupo son los largos de grupo de registro por prefijo
+// (extracto de la tabla de la Agencia Internacional del ISBN)
+var rangosGrupo = map[string][]rangoISBN{
+	"978": {
+		{"0000000", "5999999", 1},
+		{"6000000", "6499999", 3},
+		{"6500000", "6599999", 2},
+		{"6600000", "6999999", 3},
+		{"7000000", "7999999", 1},
+		{"8000000", "9499999", 2},
+		{"9500000", "9899999", 3},
+		{"9900000", "9989999", 4},
+		{"9990000", "9999999", 5},
+	},
+	"979": {
+		{"1000000", "1299999", 2},
+		{"8000000", "8999999", 1},
+	},
+}
+
+// rangosEditorial son los largos de editorial de algunos grupos frecuentes
+// en el catálogo (inglés, francés, alemán y español)
+var rangosEditorial = map[string][]rangoISBN{
+	"978-0": {
+		{"0000000", "1999999", 2},
+		{"2000000", "6999999", 3},
+		{"7000000", "8499999", 4},
+		{"8500000", "8999999", 5},
+		{"9000000", "9499999", 6},
+		{"9500000", "9999999", 7},
+	},
+	"978-1": {
+		{"0000000", "0999999", 2},
+		{"1000000", "3999999", 3},
+		{"4000000", "5499999", 4},
+		{"5500000", "8697999", 5},
+		{"8698000", "9989999", 6},
+		{"9990000", "9999999", 7},
+	},
+	"978-2": {
+		{"0000000", "1999999", 2},
+		{"2000000", "3499999", 3},
+		{"3500000", "3999999", 5},
+		{"4000000", "6999999", 3},
+		{"7000000", "8399999", 4},
+		{"8400000", "8999999", 5},
+		{"9000000", "9499999", 6},
+		{"9500000", "9999999", 7},
+	},
+	"978-3": {
+		{"0000000", "0299999", 2},
+		{"0300000", "0339999", 3},
+		{"0340000", "0369999", 4},
+		{"0370000", "0399999", 5},
+		{"0400000", "1999999", 2},
+		{"2000000", "6999999", 3},
+		{"7000000", "8499999", 4},
+		{"8500000", "8999999", 5},
+		{"9000000", "9499999", 6},
+		{"9500000", "9539999", 7},
+		{"9540000", "9699999", 5},
+		{"9700000", "9849999", 7},
+		{"9850000", "9999999", 5},
+	},
+	"978-84": {
+		{"0000000", "1399999", 2},
+		{"1400000", "1499999", 3},
+		{"1500000", "1999999", 5},
+		{"2000000", "6999999", 3},
+		{"7000000", "8499999", 4},
+		{"8500000", "8999999", 5},
+		{"9000000", "9199999", 4},
+		{"9200000", "9239999", 6},
+		{"9240000", "9299999", 5},
+		{"9300000", "9499999", 6},
+		{"9500000", "9699999", 5},
+		{"9700000", "9999999", 4},
+	},
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidarISBN(t *testing.T) {
+	validos := []string{
+		"0306406152",
+		"0-306-40615-2",
+		"080442957X", // dígito de control X
+		"080442957x",
+		"9780306406157",
+		"978-84-376-0494-7",
+		"978 84 376 0494 7",
+		"9791090636071", // prefijo 979
+	}
+	for _, isbn := range validos {
+		if err := ValidarISBN(isbn); err != nil {
+			t.Errorf("%s debería ser válido: %v", isbn, err)
+		}
+	}
+
+	invalidos := []string{
+		"",
+		"0306406153",     // control incorrecto
+		"9780306406158",  // control incorrecto
+		"978030640615",   // 12 dígitos
+		"03064061A2",     // letra
+		"03064X6152",     // X fuera del final
+		"978030640615X",  // X en un ISBN-13
+		"9770306406158",  // control correcto pero prefijo desconocido
+		"97803064061570", // 14 dígitos
+	}
+	for _, isbn := range invalidos {
+		err := ValidarISBN(isbn)
+		if !errors.Is(err, ErrDatosInvalidos) {
+			t.Errorf("%q debería ser inválido, se obtuvo %v", isbn, err)
+		}
+	}
+}
+
+func TestConversionISBN(t *testing.T) {
+	casos := []struct{ isbn10, isbn13 string }{
+		{"0306406152", "9780306406157"},
+		{"080442957X", "9780804429573"},
+		{"843760494X", "9788437604947"},
+	}
+	for _, c := range casos {
+		if obtenido, err := ISBN10a13(c.isbn10); err != nil || obtenido != c.isbn13 {
+			t.Errorf("ISBN10a13(%s) = %s, %v; se esperaba %s", c.isbn10, obtenido, err, c.isbn13)
+		}
+		if obtenido, err := ISBN13a10(c.isbn13); err != nil || obtenido != c.isbn10 {
+			t.Errorf("ISBN13a10(%s) = %s, %v; se esperaba %s", c.isbn13, obtenido, err, c.isbn10)
+		}
+		if obtenido, err := CanonizarISBN(c.isbn10); err != nil || obtenido != c.isbn13 {
+			t.Errorf("CanonizarISBN(%s) = %s, %v; se esperaba %s", c.isbn10, obtenido, err, c.isbn13)
+		}
+	}
+
+	// los ISBN 979 no tienen forma de 10 dígitos
+	if _, err := ISBN13a10("9791090636071"); !errors.Is(err, ErrDatosInvalidos) {
+		t.Errorf("Un ISBN 979 no debería convertirse a ISBN-10: %v", err)
+	}
+	if _, err := ISBN10a13("9780306406157"); !errors.Is(err, ErrDatosInvalidos) {
+		t.Errorf("ISBN10a13 debería rechazar un ISBN-13: %v", err)
+	}
+	if _, err := ISBN13a10("0306406153"); !errors.Is(err, ErrDatosInvalidos) {
+		t.Errorf("ISBN13a10 debería rechazar un ISBN inválido: %v", err)
+	}
+	if canonico, err := CanonizarISBN("978-84-376-0494-7"); err != nil || canonico != "9788437604947" {
+		t.Errorf("CanonizarISBN debería quitar los guiones: %s, %v", canonico, err)
+	}
+}
+
+func TestFormatearISBN(t *testing.T) {
+	casos := []struct{ isbn, esperado string }{
+		{"9788437604947", "978-84-376-0494-7"},
+		{"843760494X", "84-376-0494-X"},
+		{"9780306406157", "978-0-306-40615-7"},
+		{"0306406152", "0-306-40615-2"},
+		{"080442957x", "0-8044-2957-X"},
+		{"9791090636071", "979-10-9063607-1"}, // grupo conocido, editorial no
+		{"0306406153", "0306406153"},          // inválido: se retorna tal cual
+	}
+	for _, c := range casos {
+		if obtenido := FormatearISBN(c.isbn); obtenido != c.esperado {
+			t.Errorf("FormatearISBN(%s) = %s, se esperaba %s", c.isbn, obtenido, c.esperado)
+		}
+	}
+}
@@ -0,0 +1,621 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ==========================================
+// JOURNAL: REGISTRO DE EVENTOS SOLO-ANEXAR
+// ==========================================
+
+// TipoEvento identifica la operación que generó un evento
+type TipoEvento string
+
+const (
+	EventoBibliotecaCreada   TipoEvento = "biblioteca_creada"
+	EventoLibroAgregado      TipoEvento = "libro_agregado"
+	EventoEjemplarAgregado   TipoEvento = "ejemplar_agregado"
+	EventoLibroActualizado   TipoEvento = "libro_actualizado"
+	EventoUsuarioRegistrado  TipoEvento = "usuario_registrado"
+	EventoUsuarioActualizado TipoEvento = "usuario_actualizado"
+	EventoLibroPrestado      TipoEvento = "libro_prestado"
+	EventoLibroDevuelto      TipoEvento = "libro_devuelto"
+	EventoPrestamoRenovado   TipoEvento = "prestamo_renovado"
+	EventoMultaPagada        TipoEvento = "multa_pagada"
+	EventoReservaCreada      TipoEvento = "reserva_creada"
+	EventoReservaActualizada TipoEvento = "reserva_actualizada"
+	EventoCalendarioCambiado TipoEvento = "calendario_cambiado"
+	EventoPoliticaCambiada   TipoEvento = "politica_cambiada" // de una categoría, o la general si Categoria es vacía
+	EventoEstadoReparado     TipoEvento = "estado_reparado"   // corrección de VerificarConsistencia
+)
+
+// Evento es una línea del journal
+// Guarda el estado resultante de cada entidad tocada por la operación,
+// así reproducirlo no depende de time.Now() ni de reglas de negocio
+// El evento de creación trae en Estado un snapshot completo, porque el
+// journal puede empezar sobre una biblioteca que ya tenía datos
+type Evento struct {
+	Secuencia  int
+	Tipo       TipoEvento
+	Fecha      time.Time
+	Nombre     string
+	Direccion  string
+	Estado     json.RawMessage `json:",omitempty"`
+	Libro      *Libro
+	Usuario    *Usuario
+	Prestamo   *Prestamo
+	Reserva    *Reserva
+	Apartada   *Reserva `json:",omitempty"` // la siguiente de la cola, si Reserva liberó su ejemplar
+	Calendario *Calendario
+	Categoria  CategoriaUsuario `json:",omitempty"`
+	Politica   *PoliticaPrestamo
+	Secuencias *Secuencias
+
+	libroV1 *libroV1 // solo en eventos anteriores a los ejemplares (ver leerEvento)
+}
+
+// libroV1 es el estado que los eventos anteriores a los ejemplares guardaban
+// en el libro y que ahora corresponde a su único ejemplar
+type libroV1 struct {
+	Prestado      bool
+	ReservadoPara UsuarioID
+}
+
+// Journal es un archivo donde cada evento se agrega como una línea JSON
+type Journal struct {
+	path      string
+	archivo   archivoJournal
+	secuencia int
+	leidos    []Evento // los que había al abrirlo, hasta que UsarJournal los aplique
+	roto      error    // si no es nil, no se aceptan más eventos
+}
+
+// archivoJournal es lo que el journal usa de *os.File
+type archivoJournal interface {
+	io.WriteSeeker
+	Truncate(size int64) error
+	Sync() error
+	Close() error
+}
+
+// AbrirJournal abre (o crea) un journal para agregar eventos
+func AbrirJournal(path string) (*Journal, error) {
+	eventos, largoValido, err := leerEventos(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	archivo, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, errJournal(err, "error.journal_abrir", path)
+	}
+
+	// Descartar una última línea incompleta antes de seguir agregando
+	info, err := archivo.Stat()
+	if err != nil {
+		archivo.Close()
+		return nil, errJournal(err, "error.journal_abrir", path)
+	}
+	if largoValido > info.Size() {
+		// El último evento está completo pero le falta el salto de línea
+		_, err = archivo.Write([]byte{'\n'})
+	} else {
+		err = archivo.Truncate(largoValido)
+	}
+	if err != nil {
+		archivo.Close()
+		return nil, errJournal(err, "error.journal_reparar", path)
+	}
+
+	j := &Journal{path: path, archivo: archivo, leidos: eventos}
+	if len(eventos) > 0 {
+		j.secuencia = eventos[len(eventos)-1].Secuencia
+	}
+	return j, nil
+}
+
+// Registrar agrega un evento al final del journal y lo sincroniza a disco
+// Si la escritura o la sincronización fallan el archivo se recorta a como
+// estaba, así el evento no queda en disco cuando quien llama deshace la
+// operación. Si ni siquiera se puede recortar, el journal queda inutilizable
+// Usa receptor de PUNTERO porque avanza la secuencia
+func (j *Journal) Registrar(evento Evento) error {
+	if j.roto != nil {
+		return j.roto
+	}
+	evento.Secuencia = j.secuencia + 1
+	if evento.Fecha.IsZero() {
+		evento.Fecha = time.Now()
+	}
+
+	linea, err := json.Marshal(evento)
+	if err != nil {
+		return errJournal(err, "error.evento_serializar", evento.Tipo)
+	}
+	inicio, err := j.archivo.Seek(0, io.SeekEnd)
+	if err != nil {
+		return errJournal(err, "error.journal_escribir", j.path)
+	}
+	if _, err := j.archivo.Write(append(linea, '\n')); err != nil {
+		return j.descartarDesde(inicio, errJournal(err, "error.journal_escribir", j.path))
+	}
+	if err := j.archivo.Sync(); err != nil {
+		return j.descartarDesde(inicio, errJournal(err, "error.journal_escribir", j.path))
+	}
+
+	j.secuencia = evento.Secuencia
+	return nil
+}
+
+// descartarDesde recorta el journal en inicio, para no dejar una línea
+// parcial o un evento que la biblioteca no aplicó, y retorna err
+func (j *Journal) descartarDesde(inicio int64, err error) error {
+	if errRecorte := j.archivo.Truncate(inicio); errRecorte != nil {
+		j.roto = errJournal(errRecorte, "error.journal_inconsistente", j.path, err)
+		return j.roto
+	}
+	j.archivo.Sync()
+	return err
+}
+
+// Recortar quita del journal los eventos hasta la secuencia indicada, que
+// ya están en un snapshot guardado. Los posteriores se conservan en un
+// archivo nuevo que reemplaza al actual
+// Quien la llama debe impedir que se registren eventos mientras tanto
+func (j *Journal) Recortar(hasta int) error {
+	if j.roto != nil {
+		return j.roto
+	}
+	if j.secuencia <= hasta {
+		if err := j.archivo.Truncate(0); err != nil {
+			return errJournal(err, "error.journal_recortar", j.path)
+		}
+		if err := j.archivo.Sync(); err != nil {
+			return errJournal(err, "error.journal_recortar", j.path)
+		}
+		return nil
+	}
+
+	datos, err := os.ReadFile(j.path)
+	if err != nil {
+		return errJournal(err, "error.journal_leer", j.path)
+	}
+	inicio := 0
+	for inicio < len(datos) {
+		fin := bytes.IndexByte(datos[inicio:], '\n')
+		if fin < 0 {
+			fin = len(datos) - inicio
+		}
+		var evento struct{ Secuencia int }
+		if err := json.Unmarshal(datos[inicio:inicio+fin], &evento); err == nil && evento.Secuencia > hasta {
+			break
+		}
+		inicio += fin + 1
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".tmp-*")
+	if err != nil {
+		return errJournal(err, "error.journal_recortar", j.path)
+	}
+	_, err = tmp.Write(datos[min(inicio, len(datos)):])
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if errCierre := tmp.Close(); err == nil {
+		err = errCierre
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), j.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return errJournal(err, "error.journal_recortar", j.path)
+	}
+
+	// El archivo abierto quedó desvinculado: se sigue escribiendo en el nuevo
+	archivo, err := os.OpenFile(j.path, os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		j.roto = errJournal(err, "error.journal_abrir", j.path)
+		return j.roto
+	}
+	j.archivo.Close()
+	j.archivo = archivo
+	return nil
+}
+
+// Cerrar cierra el archivo del journal
+func (j *Journal) Cerrar() error {
+	return j.archivo.Close()
+}
+
+// UsarJournal conecta un journal a la biblioteca
+// Desde ese momento cada operación que modifica el estado queda registrada
+// Si el journal está vacío se registra primero la creación de la biblioteca
+// con su estado completo, salvo que el estado venga de un snapshot que
+// recortó el journal: entonces la numeración sigue desde ese snapshot
+// Si tiene eventos que el estado todavía no refleja (el proceso terminó
+// antes de guardar el snapshot) se aplican primero
+func (b *Biblioteca) UsarJournal(j *Journal) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	eventos := j.leidos
+	j.leidos = nil
+	if j.secuencia == 0 && b.secuenciaJournal > 0 {
+		j.secuencia = b.secuenciaJournal
+	} else if j.secuencia == 0 {
+		estado, err := json.Marshal(b.snapshot())
+		if err != nil {
+			return errJournal(err, "error.serializar_biblioteca")
+		}
+		err = j.Registrar(Evento{
+			Tipo:       EventoBibliotecaCreada,
+			Fecha:      b.ahora(),
+			Nombre:     b.Nombre,
+			Direccion:  b.Direccion,
+			Estado:     estado,
+			Secuencias: &b.secuencias,
+		})
+		if err != nil {
+			return err
+		}
+	} else if j.secuencia != b.secuenciaJournal {
+		if eventos == nil {
+			var err error
+			if eventos, _, err = leerEventos(j.path); err != nil {
+				return err
+			}
+		}
+		if err := b.ponerseAlDia(j.path, eventos); err != nil {
+			return err
+		}
+	}
+	b.journal = j
+	b.secuenciaJournal = j.secuencia
+	return nil
+}
+
+// RecuperarDesdeJournal aplica los eventos del journal que el estado todavía
+// no refleja, sin conectarlo. Un journal inexistente no tiene nada que aplicar
+func (b *Biblioteca) RecuperarDesdeJournal(path string) error {
+	eventos, _, err := leerEventos(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.ponerseAlDia(path, eventos)
+}
+
+// ponerseAlDia aplica los eventos posteriores a b.secuenciaJournal
+// Falla si el journal termina antes (no es el de estos datos) o si empieza
+// después, porque se recortó con un snapshot más nuevo que el cargado
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) ponerseAlDia(path string, eventos []Evento) error {
+	if len(eventos) == 0 {
+		return nil
+	}
+	if ultimo := eventos[len(eventos)-1].Secuencia; ultimo < b.secuenciaJournal {
+		return &ErrorBiblioteca{Tipo: ErrJournal, Valor: path,
+			mensaje: txt("error.journal_atrasado", path, ultimo, b.secuenciaJournal)}
+	}
+	for _, evento := range eventos {
+		if evento.Secuencia <= b.secuenciaJournal {
+			continue
+		}
+		if evento.Secuencia != b.secuenciaJournal+1 {
+			return &ErrorBiblioteca{Tipo: ErrJournal, Valor: path,
+				mensaje: txt("error.journal_incompleto", path, evento.Secuencia, b.secuenciaJournal)}
+		}
+		if err := b.aplicarEvento(evento); err != nil {
+			return errFormato(err, "error.evento_invalido", evento.Secuencia, path)
+		}
+		b.secuenciaJournal = evento.Secuencia
+	}
+	// Los journals anteriores a los IDs por entidad guardaban un contador
+	// compartido en lugar de las secuencias
+	b.ajustarSecuencias()
+	return nil
+}
+
+// AlAvisar fija quién recibe los avisos: fallos que no deshacen la operación
+// principal, como no poder apartar un ejemplar después de una devolución ya
+// registrada. Sin función los avisos van al log estándar
+// La función se llama con b.mu tomado: no debe usar la biblioteca
+func (b *Biblioteca) AlAvisar(avisar func(error)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.avisos = avisar
+}
+
+// avisar entrega un aviso a la función de AlAvisar o al log
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) avisar(err error) {
+	if b.avisos != nil {
+		b.avisos(err)
+		return
+	}
+	log.Printf("⚠️ %v", err)
+}
+
+// registrarEvento escribe el evento si la biblioteca tiene un journal
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) registrarEvento(evento Evento) error {
+	if err := b.escribirEvento(evento); err != nil {
+		return errJournal(err, "error.journal_no_registrado")
+	}
+	return nil
+}
+
+// escribirEvento es registrarEvento sin suponer que la operación quedó aplicada;
+// la usan las operaciones que deshacen sus cambios si el journal falla
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) escribirEvento(evento Evento) error {
+	if b.journal == nil {
+		return nil
+	}
+	evento.Secuencias = &b.secuencias
+	evento.Fecha = b.ahora()
+	if err := b.journal.Registrar(evento); err != nil {
+		return err
+	}
+	b.secuenciaJournal = b.journal.secuencia
+	return nil
+}
+
+// ReconstruirDesdeJournal crea una biblioteca reproduciendo todos los eventos
+// del journal en orden
+func ReconstruirDesdeJournal(path string) (*Biblioteca, error) {
+	eventos, _, err := leerEventos(path)
+	if err != nil {
+		return nil, err
+	}
+
+	b := NuevaBiblioteca("", "")
+	if err := b.ponerseAlDia(path, eventos); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// aplicarEvento reproduce un evento sobre la biblioteca sin volver a registrarlo
+func (b *Biblioteca) aplicarEvento(evento Evento) error {
+	switch evento.Tipo {
+	case EventoBibliotecaCreada:
+		// Los journals anteriores al estado completo solo traían el nombre
+		if evento.Estado == nil {
+			b.Nombre = evento.Nombre
+			b.Direccion = evento.Direccion
+			break
+		}
+		snap, err := leerSnapshot(evento.Estado)
+		if err != nil {
+			return err
+		}
+		b.restaurar(snap)
+	case EventoCalendarioCambiado:
+		if evento.Calendario == nil {
+			return errFormato(nil, "error.evento_incompleto", evento.Tipo)
+		}
+		b.Calendario = *evento.Calendario
+	case EventoPoliticaCambiada:
+		if evento.Politica == nil {
+			return errFormato(nil, "error.evento_incompleto", evento.Tipo)
+		}
+		b.fijarPolitica(evento.Categoria, *evento.Politica)
+	case EventoLibroAgregado, EventoEjemplarAgregado, EventoLibroActualizado, EventoUsuarioRegistrado, EventoUsuarioActualizado, EventoLibroPrestado, EventoLibroDevuelto,
+		EventoPrestamoRenovado, EventoMultaPagada, EventoReservaCreada, EventoReservaActualizada, EventoEstadoReparado:
+	default:
+		return errFormato(nil, "error.evento_desconocido", evento.Tipo)
+	}
+
+	if evento.Libro != nil {
+		libro := *evento.Libro
+		if evento.libroV1 != nil {
+			b.migrarLibroV1(&libro, *evento.libroV1)
+		}
+		b.reemplazarLibro(libro)
+	}
+	if evento.Usuario != nil {
+		b.reemplazarUsuario(*evento.Usuario)
+	}
+	// Los préstamos y las reservas listas anteriores a los ejemplares no
+	// dicen cuál tienen; es el único del libro, como en migrarSnapshotV1
+	if evento.Prestamo != nil {
+		prestamo := *evento.Prestamo
+		if prestamo.EjemplarID == 0 {
+			ejemplarID, err := b.ejemplarV1(evento.Tipo, prestamo.LibroID)
+			if err != nil {
+				return err
+			}
+			prestamo.EjemplarID = ejemplarID
+		}
+		b.reemplazarPrestamo(prestamo)
+	}
+	if evento.Reserva != nil {
+		reserva := *evento.Reserva
+		if reserva.Estado == ReservaLista && reserva.EjemplarID == 0 {
+			ejemplarID, err := b.ejemplarV1(evento.Tipo, reserva.LibroID)
+			if err != nil {
+				return err
+			}
+			reserva.EjemplarID = ejemplarID
+		}
+		b.reemplazarReserva(reserva)
+	}
+	if evento.Apartada != nil {
+		b.reemplazarReserva(*evento.Apartada)
+	}
+	if evento.Secuencias != nil {
+		b.secuencias = *evento.Secuencias
+	}
+	return nil
+}
+
+// migrarLibroV1 pasa al ejemplar el estado que un evento anterior a los
+// ejemplares guardaba en el libro. Si el libro ya existe se conserva su
+// ejemplar; si no, se le crea uno con el próximo ID
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) migrarLibroV1(libro *Libro, v1 libroV1) {
+	var ejemplar Ejemplar
+	if actual := b.buscarLibro(libro.ID); actual != nil && len(actual.Ejemplares) > 0 {
+		ejemplar = actual.Ejemplares[0]
+	} else {
+		ejemplar = b.nuevoEjemplar("")
+	}
+	ejemplar.Prestado = v1.Prestado
+	ejemplar.ReservadoPara = v1.ReservadoPara
+	libro.Ejemplares = []Ejemplar{ejemplar}
+}
+
+// ejemplarV1 retorna el ejemplar de un libro migrado por migrarLibroV1
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) ejemplarV1(tipo TipoEvento, libroID LibroID) (EjemplarID, error) {
+	libro := b.buscarLibro(libroID)
+	if libro == nil || len(libro.Ejemplares) != 1 {
+		return 0, errFormato(nil, "error.evento_sin_ejemplar", tipo, libroID)
+	}
+	return libro.Ejemplares[0].ID, nil
+}
+
+// reemplazarLibro actualiza el libro con el mismo ID o lo agrega si no existe
+func (b *Biblioteca) reemplazarLibro(libro Libro) {
+	b.indice.indexar(libro)
+	pos, ok := b.indices.libros[libro.ID]
+	if ok {
+		b.Libros[pos] = libro
+	} else {
+		b.Libros = append(b.Libros, libro)
+		pos = len(b.Libros) - 1
+	}
+	b.indexarLibro(pos)
+}
+
+// reemplazarUsuario actualiza el usuario con el mismo ID o lo agrega si no existe
+func (b *Biblioteca) reemplazarUsuario(usuario Usuario) {
+	pos, ok := b.indices.usuarios[usuario.ID]
+	if ok {
+		delete(b.indices.emails, b.Usuarios[pos].Email)
+		b.Usuarios[pos] = usuario
+	} else {
+		b.Usuarios = append(b.Usuarios, usuario)
+		pos = len(b.Usuarios) - 1
+	}
+	b.indexarUsuario(pos)
+}
+
+// reemplazarPrestamo actualiza el préstamo con el mismo ID o lo agrega si no existe
+func (b *Biblioteca) reemplazarPrestamo(prestamo Prestamo) {
+	pos, ok := b.indices.prestamos[prestamo.ID]
+	if ok {
+		b.Prestamos[pos] = prestamo
+	} else {
+		b.Prestamos = append(b.Prestamos, prestamo)
+		pos = len(b.Prestamos) - 1
+	}
+	b.indexarPrestamo(pos)
+}
+
+// reemplazarReserva actualiza la reserva con el mismo ID o la agrega si no existe
+func (b *Biblioteca) reemplazarReserva(reserva Reserva) {
+	pos, ok := b.indices.reservas[reserva.ID]
+	if ok {
+		b.Reservas[pos] = reserva
+	} else {
+		b.Reservas = append(b.Reservas, reserva)
+		pos = len(b.Reservas) - 1
+	}
+	b.indexarReserva(pos)
+}
+
+// leerEvento decodifica una línea del journal
+// Los eventos anteriores a los ejemplares traen el libro sin Ejemplares y
+// con Prestado y ReservadoPara, que se guardan en libroV1 para aplicarEvento
+func leerEvento(linea []byte) (Evento, error) {
+	var evento Evento
+	if err := json.Unmarshal(linea, &evento); err != nil {
+		return evento, err
+	}
+	if evento.Libro == nil {
+		return evento, nil
+	}
+	var v1 struct {
+		Libro struct {
+			Ejemplares    json.RawMessage
+			Prestado      bool
+			ReservadoPara UsuarioID
+		}
+	}
+	if err := json.Unmarshal(linea, &v1); err != nil {
+		return evento, err
+	}
+	if v1.Libro.Ejemplares == nil {
+		evento.libroV1 = &libroV1{Prestado: v1.Libro.Prestado, ReservadoPara: v1.Libro.ReservadoPara}
+	}
+	return evento, nil
+}
+
+// leerEventos lee todas las líneas del journal
+// Retorna también cuántos bytes del archivo contienen eventos completos
+func leerEventos(path string) ([]Evento, int64, error) {
+	archivo, err := os.Open(path)
+	if err != nil {
+		return nil, 0, errJournal(err, "error.journal_leer", path)
+	}
+	defer archivo.Close()
+
+	var eventos []Evento
+	var errLinea error
+	var largoValido int64
+	// Sin límite de largo por línea: el evento de creación trae el snapshot
+	// completo y puede ocupar varios megabytes
+	lector := bufio.NewReader(archivo)
+	linea := 0
+	for {
+		datos, errLectura := lector.ReadBytes('\n')
+		if errLectura != nil && errLectura != io.EOF {
+			return nil, 0, errJournal(errLectura, "error.journal_leer", path)
+		}
+		if len(datos) == 0 {
+			break
+		}
+		linea++
+		contenido := datos[:len(datos)-1]
+		if errLectura == io.EOF {
+			// Última línea sin salto: se cuenta como si lo tuviera, así
+			// AbrirJournal sabe que debe agregarlo
+			contenido = datos
+		}
+		if len(contenido) == 0 {
+			if errLinea == nil {
+				largoValido++
+			}
+			continue
+		}
+		// Una línea inválida solo se tolera si es la última (escritura
+		// interrumpida por un fallo); en medio del archivo es corrupción
+		if errLinea != nil {
+			return nil, 0, errLinea
+		}
+		evento, err := leerEvento(contenido)
+		if err != nil {
+			errLinea = errFormato(err, "error.journal_linea_invalida", linea, path)
+			continue
+		}
+		eventos = append(eventos, evento)
+		largoValido += int64(len(contenido)) + 1
+	}
+	return eventos, largoValido, nil
+}
@@ -0,0 +1,503 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// archivoConFallas envuelve el archivo del journal y falla a pedido
+type archivoConFallas struct {
+	*os.File
+	escrituraParcial func(linea []byte) bool // escribe la mitad de la línea y falla
+	fallarSync       bool
+}
+
+var errSimulado = errors.New("fallo simulado")
+
+func (a *archivoConFallas) Write(p []byte) (int, error) {
+	if a.escrituraParcial != nil && a.escrituraParcial(p) {
+		n, _ := a.File.Write(p[:len(p)/2])
+		return n, errSimulado
+	}
+	return a.File.Write(p)
+}
+
+func (a *archivoConFallas) Sync() error {
+	if a.fallarSync {
+		return errSimulado
+	}
+	return a.File.Sync()
+}
+
+// abrirJournalConFallas abre un journal nuevo y le pone el archivo con fallas
+func abrirJournalConFallas(t *testing.T) (*Journal, *archivoConFallas, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "journal.jsonl")
+	j, err := AbrirJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	archivo := &archivoConFallas{File: j.archivo.(*os.File)}
+	j.archivo = archivo
+	t.Cleanup(func() { j.Cerrar() })
+	return j, archivo, path
+}
+
+func TestRegistrarRecortaLaEscrituraParcial(t *testing.T) {
+	j, archivo, path := abrirJournalConFallas(t)
+	if err := j.Registrar(Evento{Tipo: EventoBibliotecaCreada, Nombre: "Central"}); err != nil {
+		t.Fatal(err)
+	}
+
+	archivo.escrituraParcial = func([]byte) bool { return true }
+	if err := j.Registrar(Evento{Tipo: EventoBibliotecaCreada, Nombre: "Perdido"}); !errors.Is(err, errSimulado) {
+		t.Fatalf("Se esperaba el fallo simulado, se obtuvo %v", err)
+	}
+	archivo.escrituraParcial = nil
+	if err := j.Registrar(Evento{Tipo: EventoBibliotecaCreada, Nombre: "Siguiente"}); err != nil {
+		t.Fatal(err)
+	}
+
+	eventos, _, err := leerEventos(path)
+	if err != nil {
+		t.Fatalf("El journal quedó corrupto: %v", err)
+	}
+	if len(eventos) != 2 || eventos[1].Nombre != "Siguiente" || eventos[1].Secuencia != 2 {
+		t.Errorf("Eventos inesperados: %+v", eventos)
+	}
+}
+
+func TestRegistrarRecortaSiFallaLaSincronizacion(t *testing.T) {
+	j, archivo, path := abrirJournalConFallas(t)
+	archivo.fallarSync = true
+	if err := j.Registrar(Evento{Tipo: EventoBibliotecaCreada}); err == nil {
+		t.Fatal("Se esperaba un error")
+	}
+	contenido, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contenido) != 0 {
+		t.Errorf("El evento rechazado quedó en disco: %q", contenido)
+	}
+}
+
+func TestDevolucionConApartadoFallidoNoFalla(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	j, archivo, path := abrirJournalConFallas(t)
+	if err := b.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	var avisos []error
+	b.AlAvisar(func(err error) { avisos = append(avisos, err) })
+
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 200)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaDocente)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaDocente)
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+	reserva, err := b.ReservarLibro(libro.ID, beto.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// falla solo el evento del apartado, después de registrar la devolución
+	archivo.escrituraParcial = func(linea []byte) bool {
+		return bytes.Contains(linea, []byte(EventoReservaActualizada))
+	}
+	prestamo, err := b.DevolverLibro(libro.ID)
+	if err != nil {
+		t.Fatalf("La devolución ya registrada no debería fallar: %v", err)
+	}
+	if !prestamo.Devuelto {
+		t.Error("El préstamo debería figurar devuelto")
+	}
+	if len(avisos) != 1 || !errors.Is(avisos[0], ErrJournal) {
+		t.Errorf("Se esperaba un aviso del journal, se obtuvo %v", avisos)
+	}
+	if r := b.BuscarReserva(reserva.ID); r.Estado != ReservaPendiente {
+		t.Errorf("La reserva debería seguir pendiente, está %s", r.Estado)
+	}
+
+	// el journal reproduce la devolución y no el apartado
+	reconstruida, err := ReconstruirDesdeJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := reconstruida.BuscarPrestamo(prestamo.ID); p == nil || !p.Devuelto {
+		t.Errorf("El journal no tiene la devolución: %+v", p)
+	}
+	if r := reconstruida.BuscarReserva(reserva.ID); r.Estado != ReservaPendiente {
+		t.Errorf("El journal tiene un apartado que no se aplicó: %s", r.Estado)
+	}
+}
+
+// estadoJSON serializa el estado completo para comparar dos bibliotecas
+func estadoJSON(t *testing.T, b *Biblioteca) string {
+	t.Helper()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	datos, err := json.Marshal(b.snapshot())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(datos)
+}
+
+func TestReconstruirDesdeJournalReproduceElEstado(t *testing.T) {
+	// la biblioteca ya tiene datos cuando se conecta el journal
+	b := NuevaBiblioteca("Central", "Calle 1")
+	previo, _ := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	if _, err := b.PrestarLibro(previo.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "biblioteca.journal")
+	j, err := AbrirJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Cerrar()
+	if err := b.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+
+	calendario := CalendarioPorDefecto()
+	calendario.Feriados = []string{"2026-12-25"}
+	if err := b.UsarCalendario(calendario); err != nil {
+		t.Fatal(err)
+	}
+	general := PoliticaPorDefecto()
+	general.DiasPrestamo = 21
+	if err := b.UsarPolitica("", general); err != nil {
+		t.Fatal(err)
+	}
+	investigador := PoliticaPorDefecto()
+	investigador.MaxPrestamos = 50
+	if err := b.UsarPolitica("investigador", investigador); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.CambiarCategoria(ana.ID, "investigador"); err != nil {
+		t.Fatal(err)
+	}
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 200)
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.DevolverLibro(previo.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	reconstruida, err := ReconstruirDesdeJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if esperado, obtenido := estadoJSON(t, b), estadoJSON(t, reconstruida); esperado != obtenido {
+		t.Errorf("El journal no reproduce el estado\nesperado: %s\nobtenido: %s", esperado, obtenido)
+	}
+}
+
+func TestUsarJournalRechazaUnJournalAtrasado(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	b.secuenciaJournal = 5 // como si el snapshot incluyera hasta el evento 5
+
+	path := filepath.Join(t.TempDir(), "biblioteca.journal")
+	otra := NuevaBiblioteca("Otra", "")
+	j, err := AbrirJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := otra.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	j.Cerrar()
+
+	if j, err = AbrirJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	defer j.Cerrar()
+	if err := b.UsarJournal(j); !errors.Is(err, ErrJournal) {
+		t.Errorf("Se esperaba un error del journal, se obtuvo %v", err)
+	}
+}
+
+func TestCLIRecuperaLoQueNoSeGuardo(t *testing.T) {
+	datos := filepath.Join(t.TempDir(), "biblioteca.json")
+	ejecutar := func(args ...string) string {
+		t.Helper()
+		var salida, errores bytes.Buffer
+		if codigo := ejecutarCLI(append(args, "--datos", datos), &salida, &errores); codigo != salidaOK {
+			t.Fatalf("%v terminó con %d: %s", args, codigo, errores.String())
+		}
+		return salida.String()
+	}
+
+	ejecutar("iniciar", "--nombre", "Central")
+	// un comando que terminó después de registrar el evento y antes de guardar
+	b, err := CargarDesde(datos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	j, err := AbrirJournal(rutaJournal(datos))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.AgregarLibro("Ficciones", "Borges", "", 200); err != nil {
+		t.Fatal(err)
+	}
+	j.Cerrar()
+
+	if salida := ejecutar("libro listar"); !strings.Contains(salida, "Ficciones") {
+		t.Errorf("El libro del journal no se recuperó:\n%s", salida)
+	}
+	ejecutar("usuario registrar", "--nombre", "Ana", "--email", "ana@test")
+	b, err = CargarDesde(datos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Libros) != 1 || len(b.Usuarios) != 1 {
+		t.Errorf("El archivo guardado debería tener el libro y el usuario: %d libros, %d usuarios", len(b.Libros), len(b.Usuarios))
+	}
+}
+
+func TestReconstruirMigraEventosAnterioresALosEjemplares(t *testing.T) {
+	// journal escrito antes de los ejemplares: el libro guarda Prestado y
+	// ReservadoPara, el préstamo no tiene EjemplarID y los IDs salen de ProximoID
+	lineas := []string{
+		`{"Secuencia":1,"Tipo":"biblioteca_creada","Nombre":"Central","ProximoID":1}`,
+		`{"Secuencia":2,"Tipo":"libro_agregado","Libro":{"ID":1,"Titulo":"Rayuela","Autor":"Cortázar","Paginas":600,"Prestado":false,"ReservadoPara":0},"ProximoID":2}`,
+		`{"Secuencia":3,"Tipo":"libro_agregado","Libro":{"ID":2,"Titulo":"Ficciones","Autor":"Borges","Paginas":200,"Prestado":false,"ReservadoPara":0},"ProximoID":3}`,
+		`{"Secuencia":4,"Tipo":"usuario_registrado","Usuario":{"ID":3,"Nombre":"Ana","Email":"ana@mail.com","Activo":true},"ProximoID":4}`,
+		`{"Secuencia":5,"Tipo":"libro_prestado","Libro":{"ID":2,"Titulo":"Ficciones","Autor":"Borges","Paginas":200,"Prestado":true,"ReservadoPara":0},` +
+			`"Prestamo":{"ID":4,"LibroID":2,"UsuarioID":3,"FechaPrestamo":"2024-03-01T10:00:00Z","FechaDevolucion":"2024-03-15T10:00:00Z"},"ProximoID":5}`,
+	}
+	path := filepath.Join(t.TempDir(), "viejo.journal")
+	if err := os.WriteFile(path, []byte(strings.Join(lineas, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ReconstruirDesdeJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	libro := b.BuscarLibro(2)
+	if libro == nil || len(libro.Ejemplares) != 1 || !libro.Ejemplares[0].Prestado {
+		t.Fatalf("El libro prestado debería tener un único ejemplar prestado: %+v", libro)
+	}
+	if prestamo := b.BuscarPrestamo(4); prestamo == nil || prestamo.EjemplarID != libro.Ejemplares[0].ID {
+		t.Fatalf("El préstamo debería quedar enlazado al ejemplar %d: %+v", libro.Ejemplares[0].ID, prestamo)
+	}
+	if otro := b.BuscarLibro(1); otro == nil || len(otro.Ejemplares) != 1 || otro.Ejemplares[0].ID == libro.Ejemplares[0].ID {
+		t.Fatalf("Cada libro debería tener su propio ejemplar: %+v", otro)
+	}
+	reporte, err := b.VerificarConsistencia(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reporte.Consistente() {
+		t.Errorf("La biblioteca migrada debería ser consistente: %+v", reporte.Inconsistencias)
+	}
+	if _, err := b.DevolverLibro(2); err != nil {
+		t.Errorf("El libro migrado debería poder devolverse: %v", err)
+	}
+}
+
+func TestJournalAceptaLineasDeMasDeUnMegabyte(t *testing.T) {
+	// el evento de creación lleva el snapshot completo, que crece con el catálogo
+	b := NuevaBiblioteca("Central", strings.Repeat("x", 2*1024*1024))
+	path := filepath.Join(t.TempDir(), "biblioteca.journal")
+	j, err := AbrirJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.AgregarLibro("Rayuela", "Cortázar", "", 600); err != nil {
+		t.Fatal(err)
+	}
+	j.Cerrar()
+
+	if j, err = AbrirJournal(path); err != nil {
+		t.Fatalf("No se pudo reabrir el journal: %v", err)
+	}
+	j.Cerrar()
+	reconstruida, err := ReconstruirDesdeJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reconstruida.Direccion != b.Direccion || len(reconstruida.Libros) != 1 {
+		t.Errorf("El journal no se reprodujo completo: %d libros", len(reconstruida.Libros))
+	}
+}
+
+func TestUsarJournalAplicaLoLeidoAlAbrir(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "biblioteca.journal")
+	b := NuevaBiblioteca("Central", "")
+	j, err := AbrirJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.AgregarLibro("Rayuela", "Cortázar", "", 600); err != nil {
+		t.Fatal(err)
+	}
+	j.Cerrar()
+
+	if j, err = AbrirJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	defer j.Cerrar()
+	// si UsarJournal volviera a leer el archivo no encontraría los eventos
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	otra := NuevaBiblioteca("", "")
+	if err := otra.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	if len(otra.Libros) != 1 || otra.Nombre != "Central" {
+		t.Errorf("No se aplicaron los eventos leídos al abrir: %d libros", len(otra.Libros))
+	}
+}
+
+func TestRecortarConservaLosEventosNoGuardados(t *testing.T) {
+	dir := t.TempDir()
+	datos := filepath.Join(dir, "biblioteca.json")
+	path := rutaJournal(datos)
+	b := NuevaBiblioteca("Central", "")
+	j, err := AbrirJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Cerrar()
+	if err := b.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	viejo := filepath.Join(dir, "viejo.json")
+	if err := b.GuardarEn(viejo); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.AgregarLibro("Rayuela", "Cortázar", "", 600); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.GuardarEn(datos); err != nil {
+		t.Fatal(err)
+	}
+	// otra petición registró un evento después de que se tomó el snapshot
+	if _, err := b.AgregarLibro("Ficciones", "Borges", "", 200); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.Recortar(2); err != nil {
+		t.Fatal(err)
+	}
+
+	eventos, _, err := leerEventos(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(eventos) != 1 || eventos[0].Secuencia != 3 {
+		t.Fatalf("Solo debería quedar el evento 3: %+v", eventos)
+	}
+	if _, err := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante); err != nil {
+		t.Fatalf("El journal recortado debería seguir aceptando eventos: %v", err)
+	}
+	recuperada, err := CargarDesde(datos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := recuperada.RecuperarDesdeJournal(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(recuperada.Libros) != 2 || len(recuperada.Usuarios) != 1 {
+		t.Errorf("Se esperaban 2 libros y 1 usuario, hay %d y %d", len(recuperada.Libros), len(recuperada.Usuarios))
+	}
+
+	// un snapshot anterior al recorte ya no se puede completar con el journal
+	anterior, err := CargarDesde(viejo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := anterior.RecuperarDesdeJournal(path); !errors.Is(err, ErrJournal) {
+		t.Errorf("Se esperaba un error del journal, se obtuvo %v", err)
+	}
+}
+
+func TestCLIRecortaElJournalAlGuardar(t *testing.T) {
+	datos := filepath.Join(t.TempDir(), "biblioteca.json")
+	ejecutar := func(args ...string) {
+		t.Helper()
+		var salida, errores bytes.Buffer
+		if codigo := ejecutarCLI(append(args, "--datos", datos), &salida, &errores); codigo != salidaOK {
+			t.Fatalf("%v terminó con %d: %s", args, codigo, errores.String())
+		}
+	}
+
+	ejecutar("iniciar", "--nombre", "Central")
+	ejecutar("libro agregar", "--titulo", "Ficciones", "--autor", "Borges", "--paginas", "200")
+	ejecutar("usuario registrar", "--nombre", "Ana", "--email", "ana@test")
+	if info, err := os.Stat(rutaJournal(datos)); err != nil || info.Size() != 0 {
+		t.Fatalf("El journal debería quedar vacío después de guardar: %v %v", info, err)
+	}
+
+	b, err := CargarDesde(datos)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.secuenciaJournal != 3 || len(b.Libros) != 1 || len(b.Usuarios) != 1 {
+		t.Errorf("El snapshot debería incluir hasta el evento 3: %d, %d libros, %d usuarios",
+			b.secuenciaJournal, len(b.Libros), len(b.Usuarios))
+	}
+}
+
+func TestOperacionesRechazadasPorElJournalNoCambianElEstado(t *testing.T) {
+	b := NuevaBiblioteca("Central", "")
+	j, archivo, _ := abrirJournalConFallas(t)
+	if err := b.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 200)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+	prestamo, err := b.PrestarLibro(libro.ID, ana.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	antes := estadoJSON(t, b)
+
+	archivo.fallarSync = true
+	operaciones := []struct {
+		nombre    string
+		operacion func() error
+	}{
+		{"agregar libro", func() error { _, err := b.AgregarLibro("Rayuela", "Cortázar", "", 600); return err }},
+		{"agregar ejemplar", func() error { _, err := b.AgregarEjemplar(libro.ID, ""); return err }},
+		{"actualizar libro", func() error { _, err := b.ActualizarLibro(libro.ID, "Ficciones", "J. L. Borges", 210); return err }},
+		{"registrar usuario", func() error { _, err := b.RegistrarUsuario("Carla", "carla@test", "", ""); return err }},
+		{"cambiar categoría", func() error { _, err := b.CambiarCategoria(ana.ID, CategoriaDocente); return err }},
+		{"cambiar idioma", func() error { _, err := b.CambiarIdioma(ana.ID, IdiomaIngles); return err }},
+		{"renovar", func() error { _, err := b.RenovarPrestamo(prestamo.ID); return err }},
+		{"reservar", func() error { _, err := b.ReservarLibro(libro.ID, beto.ID); return err }},
+		{"desactivar usuario", func() error { _, err := b.DesactivarUsuario(beto.ID); return err }},
+	}
+	for _, o := range operaciones {
+		if err := o.operacion(); !errors.Is(err, ErrJournal) {
+			t.Errorf("%s: se esperaba un error del journal, se obtuvo %v", o.nombre, err)
+		}
+		if despues := estadoJSON(t, b); despues != antes {
+			t.Errorf("%s: el estado cambió aunque el evento no se registró\nantes:   %s\ndespués: %s", o.nombre, antes, despues)
+		}
+	}
+}
@@ -2,40 +2,53 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
 // ==========================================
 // PASO 1: STRUCTS BÁSICOS
 // ==========================================
-// Libro representa un libro en la biblioteca
+// Libro representa un título del catálogo de la biblioteca
+// Las copias físicas que se prestan son sus Ejemplares
 type Libro struct {
-	ID       int
-	Titulo   string
-	Autor    string
-	ISBN     string
-	Paginas  int
-	Prestado bool
+	ID         LibroID
+	Titulo     string
+	Autor      string
+	ISBN       string
+	Paginas    int
+	Ejemplares []Ejemplar
 }
 
 // Usuario representa un usuario de la biblioteca
 type Usuario struct {
-	ID       int
-	Nombre   string
-	Email    string
-	Telefono string
-	Activo   bool
+	ID        UsuarioID
+	Nombre    string
+	Email     string
+	Telefono  string
+	Activo    bool
+	Categoria CategoriaUsuario // define plazos y límites de sus préstamos
+	Idioma    Idioma           `json:",omitempty"` // de sus comprobantes; vacío es IdiomaPorDefecto
+	// Suspension es nil salvo que la política lo haya suspendido (ver suspensiones.go)
+	// Se reemplaza entera en cada cambio, nunca se modifica
+	Suspension *Suspension `json:",omitempty"`
 }
 
 // Prestamo representa un prestamo de un libro
 type Prestamo struct {
-	ID              int
-	LibroID         int
-	UsuarioID       int
+	ID              PrestamoID
+	LibroID         LibroID
+	UsuarioID       UsuarioID
 	FechaPrestamo   time.Time
 	FechaDevolucion time.Time
 	Devuelto        bool
+	EjemplarID      EjemplarID // copia física prestada
+	FechaDevuelto   time.Time  // cuándo se devolvió (cero si sigue activo)
+	Multa           float64    // multa cobrada al devolver con atraso
+	Renovaciones    int
+	MultaPagada     bool `json:",omitempty"` // la multa ya se cobró (ver PagarMulta)
 }
 
 // ==========================================
@@ -45,17 +58,51 @@ type Prestamo struct {
 // ObtenerInfo retorna información básica del libro
 // Usa receptor de VALOR porque solo LEE, no modifica
 func (l Libro) ObtenerInfo() string {
-	estado := "Disponible"
-	if l.Prestado {
-		estado = "Prestado"
+	return l.ObtenerInfoEn(IdiomaPorDefecto)
+}
+
+// ObtenerInfoEn es ObtenerInfo en el idioma indicado
+func (l Libro) ObtenerInfoEn(idioma Idioma) string {
+	estado := idioma.Texto("libro.sin_ejemplares")
+	if len(l.Ejemplares) > 0 {
+		estado = l.DisponibilidadEn(idioma)
 	}
-	return fmt.Sprintf("[%d] %s por %s - %s", l.ID, l.Titulo, l.Autor, estado)
+	return idioma.Texto("libro.info", l.ID, l.Titulo, l.Autor, estado)
 }
 
-// EsPretable verifica si el libro se puede prestar
+// EsPretable verifica si el libro tiene algún ejemplar para prestar
 // Usa receptor de VALOR porque solo LEE
 func (l Libro) EsPrestable() bool {
-	return !l.Prestado && l.Paginas > 0
+	return l.Disponibles() > 0 && l.Paginas > 0
+}
+
+// Disponibles cuenta los ejemplares que no están prestados ni apartados
+// Usa receptor de VALOR porque solo LEE
+func (l Libro) Disponibles() int {
+	disponibles := 0
+	for _, ejemplar := range l.Ejemplares {
+		if ejemplar.EstaDisponible() {
+			disponibles++
+		}
+	}
+	return disponibles
+}
+
+// Disponibilidad retorna un texto del tipo "2 de 3 disponibles"
+// Usa receptor de VALOR porque solo LEE
+func (l Libro) Disponibilidad() string {
+	return l.DisponibilidadEn(IdiomaPorDefecto)
+}
+
+// DisponibilidadEn es Disponibilidad en el idioma indicado
+func (l Libro) DisponibilidadEn(idioma Idioma) string {
+	return l.disponibilidad().textoEn(idioma)
+}
+
+// disponibilidad es el mensaje sin traducir, para los errores
+func (l Libro) disponibilidad() texto {
+	disponibles := l.Disponibles()
+	return txtN("libro.disponibilidad", disponibles, disponibles, len(l.Ejemplares))
 }
 
 func (l Libro) EsGrande() bool {
@@ -65,15 +112,34 @@ func (l Libro) EsGrande() bool {
 // ObtenerResumen retorna un resumen del usuario
 // Usa receptor de VALOR porque solo LEE
 func (u Usuario) ObtenerResumen() string {
-	estado := "Inactivo"
-	if u.Activo {
-		estado = "Activo"
+	return u.ObtenerResumenEn(IdiomaPorDefecto)
+}
+
+// ObtenerResumenEn es ObtenerResumen en el idioma indicado
+func (u Usuario) ObtenerResumenEn(idioma Idioma) string {
+	estado := idioma.Texto("usuario.inactivo")
+	if u.Activo && u.Suspension != nil {
+		estado = idioma.Texto("usuario.suspendido")
+	} else if u.Activo {
+		estado = idioma.Texto("usuario.activo")
+	}
+	if u.Categoria != "" {
+		return idioma.Texto("usuario.resumen_categoria", u.Nombre, u.Email, u.Categoria, estado)
 	}
-	return fmt.Sprintf("%s (%s) - %s", u.Nombre, u.Email, estado)
+	return idioma.Texto("usuario.resumen", u.Nombre, u.Email, estado)
 }
 
-func (u Usuario) PuedePrestar() bool {
-	return u.Activo && u.Email != "" && u.Nombre != ""
+// PuedePrestar retorna nil si el usuario puede llevar libros, o el error que
+// explica por qué no: está inactivo o suspendido (con el motivo)
+// Usa receptor de VALOR porque solo LEE
+func (u Usuario) PuedePrestar() error {
+	if !u.Activo || u.Email == "" || u.Nombre == "" {
+		return errUsuarioInactivo(&u)
+	}
+	if u.Suspension != nil {
+		return errUsuarioSuspendido(&u)
+	}
+	return nil
 }
 
 // ==========================================
@@ -81,25 +147,39 @@ func (u Usuario) PuedePrestar() bool {
 // (Para MODIFICAR el estado del struct)
 // ==========================================
 
-// Prestar marca el libro como prestado
+// Prestar marca un ejemplar del libro como prestado
 // Usa receptor de PUNTERO porque MODIFICA el estado
 
-func (l *Libro) Prestar() error {
-	if l.Prestado {
-		return fmt.Errorf("El libro '%s' ya está prestado", l.Titulo)
-	}
+func (l *Libro) Prestar(ejemplarID EjemplarID) error {
 	if l.Paginas <= 0 {
-		return fmt.Errorf("El libro '%s' no es valido", l.Titulo)
+		return &ErrorBiblioteca{Tipo: ErrLibroNoPrestable, LibroID: l.ID,
+			mensaje: txt("error.libro_invalido", l.Titulo)}
+	}
+	ejemplar := l.buscarEjemplar(ejemplarID)
+	if ejemplar == nil {
+		return &ErrorBiblioteca{Tipo: ErrEjemplarNoEncontrado, LibroID: l.ID, EjemplarID: ejemplarID,
+			mensaje: txt("error.ejemplar_ajeno", l.Titulo, ejemplarID)}
+	}
+	if ejemplar.Prestado {
+		return &ErrorBiblioteca{Tipo: ErrLibroYaPrestado, LibroID: l.ID, EjemplarID: ejemplarID,
+			mensaje: txt("error.ejemplar_prestado", ejemplar.CodigoBarras, l.Titulo)}
 	}
-	l.Prestado = true
+	ejemplar.Prestado = true
 	return nil
 }
 
-func (l *Libro) Devolver() error {
-	if !l.Prestado {
-		return fmt.Errorf("El libro '%s' no está prestado", l.Titulo)
+// Devolver marca un ejemplar del libro como disponible nuevamente
+func (l *Libro) Devolver(ejemplarID EjemplarID) error {
+	ejemplar := l.buscarEjemplar(ejemplarID)
+	if ejemplar == nil {
+		return &ErrorBiblioteca{Tipo: ErrEjemplarNoEncontrado, LibroID: l.ID, EjemplarID: ejemplarID,
+			mensaje: txt("error.ejemplar_ajeno", l.Titulo, ejemplarID)}
 	}
-	l.Prestado = false
+	if !ejemplar.Prestado {
+		return &ErrorBiblioteca{Tipo: ErrLibroNoPrestado, LibroID: l.ID, EjemplarID: ejemplarID,
+			mensaje: txt("error.ejemplar_no_prestado", ejemplar.CodigoBarras, l.Titulo)}
+	}
+	ejemplar.Prestado = false
 	return nil
 }
 
@@ -107,10 +187,10 @@ func (l *Libro) Devolver() error {
 // Usa receptor de PUNTERO porque MODIFICA el estado
 func (l *Libro) ActualizarInfo(titulo, autor string, paginas int) error {
 	if titulo == "" || autor == "" {
-		return fmt.Errorf("Debe proporcionar titulo y autor")
+		return errDatosInvalidos("error.faltan_titulo_autor")
 	}
 	if paginas <= 0 {
-		return fmt.Errorf("Debe proporcionar cantidad de paginas")
+		return errDatosInvalidos("error.faltan_paginas")
 	}
 
 	l.Titulo = titulo
@@ -129,7 +209,8 @@ func (u *Usuario) Desactivar() {
 
 func (u *Usuario) ActualizarContacto(email, telefono string) error {
 	if !strings.Contains(email, "@") {
-		return fmt.Errorf("Email no válido '%s'", email)
+		return &ErrorBiblioteca{Tipo: ErrDatosInvalidos, UsuarioID: u.ID, Valor: email,
+			mensaje: txt("error.email_invalido", email)}
 	}
 	u.Email = email
 	u.Telefono = telefono
@@ -140,14 +221,28 @@ func (u *Usuario) ActualizarContacto(email, telefono string) error {
 // PASO 4: STRUCT PRINCIPAL CON COMPOSICIÓN
 // ==========================================
 // Biblioteca es el struct principal que maneja todo el sistema
+// Es segura para uso concurrente: las lecturas corren en paralelo y las
+// escrituras se serializan con mu
 
 type Biblioteca struct {
-	Nombre    string
-	Direccion string
-	Libros    []Libro
-	Usuarios  []Usuario
-	Prestamos []Prestamo
-	proximoID int
+	Nombre           string
+	Direccion        string
+	Libros           []Libro
+	Usuarios         []Usuario
+	Prestamos        []Prestamo
+	Reservas         []Reserva
+	Politica         PoliticaPrestamo // para usuarios sin categoría
+	Categorias       map[CategoriaUsuario]PoliticaPrestamo
+	Calendario       Calendario // días y horarios de atención para los vencimientos
+	reloj            Reloj
+	secuencias       Secuencias
+	journal          *Journal
+	secuenciaJournal int         // último evento del journal reflejado en el estado
+	avisos           func(error) // ver AlAvisar
+	indice           *indiceTexto
+	indices          indicesBiblioteca
+	mu               sync.RWMutex
+	guardado         sync.Mutex // serializa GuardarEn
 }
 
 // ==========================================
@@ -156,337 +251,494 @@ type Biblioteca struct {
 // NuevaBiblioteca es un constructor (patrón común en Go)
 func NuevaBiblioteca(nombre, direccion string) *Biblioteca {
 	return &Biblioteca{
-		Nombre:    nombre,
-		Direccion: direccion,
-		Libros:    make([]Libro, 0),
-		Usuarios:  make([]Usuario, 0),
-		Prestamos: make([]Prestamo, 0),
-		proximoID: 1,
+		Nombre:     nombre,
+		Direccion:  direccion,
+		Libros:     make([]Libro, 0),
+		Usuarios:   make([]Usuario, 0),
+		Prestamos:  make([]Prestamo, 0),
+		Reservas:   make([]Reserva, 0),
+		Politica:   PoliticaPorDefecto(),
+		Categorias: PoliticasPorCategoria(),
+		Calendario: CalendarioPorDefecto(),
+		reloj:      RelojSistema{},
+		secuencias: nuevasSecuencias(),
+		indice:     nuevoIndiceTexto(),
+		indices:    nuevosIndices(),
 	}
 }
 
 // AgregarLibro añade un nuevo libro a la biblioteca
 // Usa receptor de PUNTERO porque modifica el slice de libros
 func (b *Biblioteca) AgregarLibro(titulo, autor, isbn string, paginas int) (*Libro, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.agregarLibro(titulo, autor, isbn, paginas, 1)
+}
+
+// validarLibroNuevo verifica los datos de un título nuevo y retorna el ISBN
+// en su forma canónica (ISBN-13 sin guiones)
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) validarLibroNuevo(titulo, autor, isbn string) (string, error) {
 	if titulo == "" || autor == "" {
-		return nil, fmt.Errorf("Debe proporcionar titulo y autor")
+		return "", errDatosInvalidos("error.faltan_titulo_autor")
 	}
 
-	//verificar que no exista un lubro con el mismo ISBN
-	for _, libro := range b.Libros {
-		if libro.ISBN == isbn && isbn != "" {
-			return nil, fmt.Errorf("Ya existe un libro con el ISBN '%s'", isbn)
+	// el ISBN es opcional, pero si viene debe ser válido
+	if isbn != "" {
+		canonico, err := CanonizarISBN(isbn)
+		if err != nil {
+			return "", err
 		}
+		isbn = canonico
+	}
+
+	//verificar que no exista un lubro con el mismo ISBN
+	//(las copias adicionales se registran con AgregarEjemplar)
+	if existente := b.buscarLibroPorISBN(isbn); isbn != "" && existente != nil {
+		return "", errISBNDuplicado(isbn, existente.ID)
+	}
+	return isbn, nil
+}
+
+// agregarLibro es AgregarLibro con la cantidad de ejemplares iniciales
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) agregarLibro(titulo, autor, isbn string, paginas, ejemplares int) (*Libro, error) {
+	isbn, err := b.validarLibroNuevo(titulo, autor, isbn)
+	if err != nil {
+		return nil, err
 	}
 
+	// El evento se escribe antes de agregar el libro: si el journal falla
+	// solo hay que devolver los IDs tomados
+	secuenciasAntes := b.secuencias
 	libro := Libro{
-		ID:       b.proximoID,
-		Titulo:   titulo,
-		Autor:    autor,
-		ISBN:     isbn,
-		Paginas:  paginas,
-		Prestado: false,
+		ID:      b.siguienteLibroID(),
+		Titulo:  titulo,
+		Autor:   autor,
+		ISBN:    isbn,
+		Paginas: paginas,
 	}
 
+	// Todo título nuevo llega con al menos su primer ejemplar
+	libro.Ejemplares = []Ejemplar{b.nuevoEjemplar("")}
+	for i := 1; i < ejemplares; i++ {
+		libro.Ejemplares = append(libro.Ejemplares, b.nuevoEjemplar(""))
+	}
+
+	if err := b.escribirEvento(Evento{Tipo: EventoLibroAgregado, Libro: &libro}); err != nil {
+		b.secuencias = secuenciasAntes
+		return nil, errJournal(err, "error.libro_no_registrado")
+	}
 	b.Libros = append(b.Libros, libro)
-	b.proximoID++
+	b.indexarLibro(len(b.Libros) - 1)
+	b.indice.indexar(libro)
 
-	return &libro, nil
+	copia := libro.copiar()
+	return &copia, nil
 }
 
 // RegistrarUsuario registra un nuevo usuario
+// Sin categoría se registra como público general
 // Usa receptor de PUNTERO porque modifica el slice de usuarios
-func (b *Biblioteca) RegistrarUsuario(nombre, email, telefono string) (*Usuario, error) {
+func (b *Biblioteca) RegistrarUsuario(nombre, email, telefono string, categoria CategoriaUsuario) (*Usuario, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	if nombre == "" || email == "" {
-		return nil, fmt.Errorf("Debe proporcionar nombre y email")
+		return nil, errDatosInvalidos("error.faltan_nombre_email")
 	}
 
 	if !strings.Contains(email, "@") {
-		return nil, fmt.Errorf("Email no válido '%s'", email)
+		return nil, &ErrorBiblioteca{Tipo: ErrDatosInvalidos, Valor: email,
+			mensaje: txt("error.email_invalido", email)}
 	}
 
-	for _, usuario := range b.Usuarios {
-		if usuario.Email == email {
-			return nil, fmt.Errorf("Ya existe un usuario con el email '%s'", email)
-		}
+	if existente := b.buscarUsuarioPorEmail(email); existente != nil {
+		return nil, &ErrorBiblioteca{Tipo: ErrEmailDuplicado, UsuarioID: existente.ID, Valor: email,
+			mensaje: txt("error.email_duplicado", email)}
 	}
+
+	if categoria == "" {
+		categoria = CategoriaPublico
+	}
+	if err := b.validarCategoria(categoria); err != nil {
+		return nil, err
+	}
+
+	secuenciasAntes := b.secuencias
 	usuario := Usuario{
-		ID:       b.proximoID,
-		Nombre:   nombre,
-		Email:    email,
-		Telefono: telefono,
-		Activo:   true,
+		ID:        b.siguienteUsuarioID(),
+		Nombre:    nombre,
+		Email:     email,
+		Telefono:  telefono,
+		Activo:    true,
+		Categoria: categoria,
 	}
 
+	if err := b.escribirEvento(Evento{Tipo: EventoUsuarioRegistrado, Usuario: &usuario}); err != nil {
+		b.secuencias = secuenciasAntes
+		return nil, errJournal(err, "error.usuario_no_registrado")
+	}
 	b.Usuarios = append(b.Usuarios, usuario)
-	b.proximoID++
+	b.indexarUsuario(len(b.Usuarios) - 1)
 
 	return &usuario, nil
 }
 
-// BuscarLibro busca un libro por ID
-// Usa receptor de VALOR porque solo lee y retorna una copia
-func (b Biblioteca) BuscarLibro(id int) *Libro {
-	for i, libro := range b.Libros {
-		if libro.ID == id {
-			return &b.Libros[i] // Retorna puntero al libro original
+// DesactivarUsuario da de baja a un usuario: no puede pedir préstamos ni
+// reservas y sus reservas pendientes se saltean al apartar ejemplares
+// Los préstamos que ya tiene siguen activos hasta que los devuelva
+func (b *Biblioteca) DesactivarUsuario(usuarioID UsuarioID) (*Usuario, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	usuario := b.buscarUsuario(usuarioID)
+	if usuario == nil {
+		return nil, errUsuarioNoEncontrado(usuarioID)
+	}
+	if usuario.Activo {
+		actualizado := *usuario
+		actualizado.Desactivar()
+		if err := b.escribirEvento(Evento{Tipo: EventoUsuarioActualizado, Usuario: &actualizado}); err != nil {
+			return nil, errJournal(err, "error.cambio_no_registrado")
 		}
+		*usuario = actualizado
 	}
-	return nil
+
+	copia := *usuario
+	return &copia, nil
+}
+
+// BuscarLibro busca un libro por ID
+// Retorna una copia: modificarla no altera la biblioteca ni compite con
+// otras goroutines
+func (b *Biblioteca) BuscarLibro(id LibroID) *Libro {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	libro := b.buscarLibro(id)
+	if libro == nil {
+		return nil
+	}
+	copia := libro.copiar()
+	return &copia
 }
 
 // BuscarUsuario busca un usuario por ID
-// Usa receptor de VALOR porque solo lee
-func (b Biblioteca) BuscarUsuario(id int) *Usuario {
-	for i, usuario := range b.Usuarios {
-		if usuario.ID == id {
-			return &b.Usuarios[i] // Retorna puntero al usuario original
-		}
+// Retorna una copia, igual que BuscarLibro
+func (b *Biblioteca) BuscarUsuario(id UsuarioID) *Usuario {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	usuario := b.buscarUsuario(id)
+	if usuario == nil {
+		return nil
 	}
-	return nil
+	copia := *usuario
+	return &copia
+}
+
+// buscarLibro retorna un puntero al libro original dentro del slice
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) buscarLibro(id LibroID) *Libro {
+	pos, ok := b.indices.libros[id]
+	if !ok {
+		return nil
+	}
+	return &b.Libros[pos]
+}
+
+// buscarUsuario retorna un puntero al usuario original dentro del slice
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) buscarUsuario(id UsuarioID) *Usuario {
+	pos, ok := b.indices.usuarios[id]
+	if !ok {
+		return nil
+	}
+	return &b.Usuarios[pos]
+}
+
+// BuscarPrestamo busca un préstamo por ID
+// Retorna una copia, igual que BuscarLibro
+func (b *Biblioteca) BuscarPrestamo(id PrestamoID) *Prestamo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	prestamo := b.buscarPrestamo(id)
+	if prestamo == nil {
+		return nil
+	}
+	copia := *prestamo
+	return &copia
+}
+
+// buscarPrestamo retorna un puntero al préstamo original dentro del slice
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) buscarPrestamo(id PrestamoID) *Prestamo {
+	pos, ok := b.indices.prestamos[id]
+	if !ok {
+		return nil
+	}
+	return &b.Prestamos[pos]
+}
+
+// ListarLibros retorna una copia de todos los libros
+func (b *Biblioteca) ListarLibros() []Libro {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	libros := make([]Libro, len(b.Libros))
+	for i, libro := range b.Libros {
+		libros[i] = libro.copiar()
+	}
+	return libros
+}
+
+// ListarUsuarios retorna una copia de todos los usuarios
+func (b *Biblioteca) ListarUsuarios() []Usuario {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Usuario(nil), b.Usuarios...)
+}
+
+// ListarPrestamos retorna una copia de todos los préstamos
+func (b *Biblioteca) ListarPrestamos() []Prestamo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return append([]Prestamo(nil), b.Prestamos...)
 }
 
 // PrestarLibro realiza el préstamo de un libro
+// Entrega el ejemplar apartado para el usuario o el primero disponible
 // Usa receptor de PUNTERO porque modifica múltiples estados
-func (b *Biblioteca) PrestarLibro(libroID, usuarioID int) error {
+func (b *Biblioteca) PrestarLibro(libroID LibroID, usuarioID UsuarioID) (*Prestamo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	//Buscar libro
-	libro := b.BuscarLibro(libroID)
+	libro := b.buscarLibro(libroID)
 	if libro == nil {
-		return fmt.Errorf("No existe un libro con ID '%d'", libroID)
+		return nil, errLibroNoEncontrado(libroID)
+	}
+	// un usuario inexistente se informa antes que la falta de ejemplares
+	if b.buscarUsuario(usuarioID) == nil {
+		return nil, errUsuarioNoEncontrado(usuarioID)
 	}
 
+	// Elegir ejemplar
+	ejemplar := libro.ejemplarApartadoPara(usuarioID)
+	if ejemplar == nil {
+		ejemplar = libro.ejemplarDisponible()
+	}
+	if ejemplar == nil {
+		return nil, &ErrorBiblioteca{Tipo: ErrLibroYaPrestado, LibroID: libro.ID,
+			mensaje: txt("error.libro_no_disponible", libro.Titulo, libro.disponibilidad())}
+	}
+
+	return b.prestar(libro, ejemplar, usuarioID)
+}
+
+// prestar presta un ejemplar concreto al usuario
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) prestar(libro *Libro, ejemplar *Ejemplar, usuarioID UsuarioID) (*Prestamo, error) {
 	// Buscar Usuario
-	usuario := b.BuscarUsuario(usuarioID)
+	usuario := b.buscarUsuario(usuarioID)
 	if usuario == nil {
-		return fmt.Errorf("No existe un usuario con ID '%d'", usuarioID)
+		return nil, errUsuarioNoEncontrado(usuarioID)
 	}
 
-	// validar que el usuario pueda prestar
-	if !usuario.PuedePrestar() {
-		return fmt.Errorf("El usuario '%s' no puede prestar", usuario.Nombre)
+	// validar que el usuario pueda prestar; la suspensión se revisa antes
+	// porque el atraso de sus préstamos crece con los días
+	if err := b.revisarSuspension(usuario, b.ahora()); err != nil {
+		return nil, err
+	}
+	if err := usuario.PuedePrestar(); err != nil {
+		return nil, err
+	}
+
+	// un ejemplar apartado solo lo puede retirar quien lo reservó
+	if ejemplar.ReservadoPara != 0 && ejemplar.ReservadoPara != usuarioID {
+		return nil, &ErrorBiblioteca{Tipo: ErrLibroReservado, LibroID: libro.ID, EjemplarID: ejemplar.ID, UsuarioID: usuarioID,
+			mensaje: txt("error.ejemplar_reservado", ejemplar.CodigoBarras, libro.Titulo)}
 	}
 
-	// validar que el libro se puede prestar
-	if !libro.EsPrestable() {
-		return fmt.Errorf("El libro '%s' no se puede prestar", libro.Titulo)
+	// respetar el límite de préstamos simultáneos de su categoría
+	politica := b.politicaDe(usuarioID)
+	if activos := b.prestamosActivosDeUsuario(usuarioID); politica.MaxPrestamos > 0 && activos >= politica.MaxPrestamos {
+		return nil, &ErrorBiblioteca{Tipo: ErrLimitePrestamos, UsuarioID: usuarioID,
+			mensaje: txtN("error.limite_prestamos", activos,
+				usuario.Nombre, activos, usuario.Categoria, politica.MaxPrestamos)}
+	}
+
+	// Si el journal falla se deshace el préstamo completo, así el estado en
+	// memoria nunca difiere de lo registrado
+	ejemplarAntes := *ejemplar
+	secuenciasAntes := b.secuencias
+
+	// Marcar el ejemplar dentro del mismo lock, así dos goroutines nunca
+	// prestan la misma copia
+	if err := libro.Prestar(ejemplar.ID); err != nil {
+		return nil, err
+	}
+
+	var reserva *Reserva
+	if ejemplar.ReservadoPara == usuarioID {
+		reserva = b.retirarReserva(ejemplar, usuarioID)
 	}
 
 	// Realizar el prestamo
+	ahora := b.ahora()
 	prestamo := Prestamo{
-		ID:              b.proximoID,
-		LibroID:         libroID,
+		ID:              b.siguientePrestamoID(),
+		LibroID:         libro.ID,
 		UsuarioID:       usuarioID,
-		FechaPrestamo:   time.Now(),
-		FechaDevolucion: time.Now().AddDate(0, 0, 14), // 14 dias
+		FechaPrestamo:   ahora,
+		FechaDevolucion: b.Calendario.Vencimiento(ahora, politica.DiasPrestamo),
 		Devuelto:        false,
+		EjemplarID:      ejemplar.ID,
 	}
 	b.Prestamos = append(b.Prestamos, prestamo)
-	b.proximoID++
-
-	return nil
+	b.indexarPrestamo(len(b.Prestamos) - 1)
+
+	err := b.escribirEvento(Evento{
+		Tipo:     EventoLibroPrestado,
+		Libro:    libro,
+		Prestamo: &prestamo,
+		Reserva:  reserva,
+	})
+	if err != nil {
+		*ejemplar = ejemplarAntes
+		if reserva != nil {
+			reserva.Estado = ReservaLista
+		}
+		b.desindexarUltimoPrestamo()
+		b.Prestamos = b.Prestamos[:len(b.Prestamos)-1]
+		b.secuencias = secuenciasAntes
+		return nil, errJournal(err, "error.prestamo_no_registrado")
+	}
+	return &prestamo, nil
 }
 
 // DevolverLibro procesa la devolución de un libro
+// Si hay varios ejemplares del título prestados use DevolverEjemplar
 // Usa receptor de PUNTERO porque modifica estados
-func (b *Biblioteca) DevolverLibro(libroID int) error {
+func (b *Biblioteca) DevolverLibro(libroID LibroID) (*Prestamo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
 	//Buscar libro
-	libro := b.BuscarLibro(libroID)
+	libro := b.buscarLibro(libroID)
 	if libro == nil {
-		return fmt.Errorf("No existe un libro con ID '%d'", libroID)
+		return nil, errLibroNoEncontrado(libroID)
 	}
 
 	// Buscar prestamo activo
-	var prestamoActivo *Prestamo
-	for i := range b.Prestamos {
-		if b.Prestamos[i].LibroID == libroID && !b.Prestamos[i].Devuelto {
-			prestamoActivo = &b.Prestamos[i]
-			break
-		}
+	activos := b.prestamosActivosDe(libro)
+	if len(activos) == 0 {
+		return nil, &ErrorBiblioteca{Tipo: ErrLibroNoPrestado, LibroID: libro.ID,
+			mensaje: txt("error.libro_sin_prestamo", libro.Titulo)}
 	}
-	if prestamoActivo == nil {
-		return fmt.Errorf("No existe un prestamo activo para el libro '%s'", libro.Titulo)
+	if len(activos) > 1 {
+		return nil, &ErrorBiblioteca{Tipo: ErrDatosInvalidos, LibroID: libro.ID,
+			mensaje: txt("error.varios_prestados",
+				libro.Titulo, len(activos))}
 	}
 
+	return b.devolver(libro, activos[0])
+}
+
+// devolver cierra un préstamo activo y libera su ejemplar
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) devolver(libro *Libro, prestamoActivo *Prestamo) (*Prestamo, error) {
+	// Si el journal falla se deshace la devolución completa
+	prestamoAntes := *prestamoActivo
+
 	// Realizar la devolucion
-	if err := libro.Devolver(); err != nil {
-		return err
+	if err := libro.Devolver(prestamoActivo.EjemplarID); err != nil {
+		return nil, err
 	}
 
+	// Registrar la multa si se devuelve con atraso
+	ahora := b.ahora()
+	politica := b.politicaDe(prestamoActivo.UsuarioID)
+	prestamoActivo.Multa = politica.CalcularMulta(b.diasAtraso(*prestamoActivo, ahora))
+
 	// Marcar prestamo como devuelto
 	prestamoActivo.Devuelto = true
-
-	return nil
-}
-
-// ObtenerEstadisticas retorna estadísticas de la biblioteca
-// Usa receptor de VALOR porque solo lee información
-func (b Biblioteca) ObtenerEstadisticas() string {
-	totalLibros := len(b.Libros)
-	librosPrestados := 0
-	usuariosActivos := 0
-	prestamosActivos := 0
-
-	for _, libro := range b.Libros {
-		if libro.Prestado {
-			librosPrestados++
+	prestamoActivo.FechaDevuelto = ahora
+	delete(b.indices.prestamoActivo, prestamoActivo.EjemplarID)
+
+	// Devolver puede levantar la suspensión por atraso, y la multa puede
+	// suspender por deuda; el cambio va en el mismo evento
+	evento := Evento{
+		Tipo:     EventoLibroDevuelto,
+		Libro:    libro,
+		Prestamo: prestamoActivo,
+	}
+	usuario := b.buscarUsuario(prestamoActivo.UsuarioID)
+	var suspensionAntes *Suspension
+	if usuario != nil {
+		suspensionAntes = usuario.Suspension
+		if b.actualizarSuspension(usuario, ahora) {
+			evento.Usuario = usuario
 		}
 	}
 
-	for _, usuario := range b.Usuarios {
-		if usuario.Activo {
-			usuariosActivos++
+	if err := b.escribirEvento(evento); err != nil {
+		libro.buscarEjemplar(prestamoAntes.EjemplarID).Prestado = true
+		*prestamoActivo = prestamoAntes
+		b.indices.prestamoActivo[prestamoAntes.EjemplarID] = prestamoAntes.ID
+		if usuario != nil {
+			usuario.Suspension = suspensionAntes
 		}
+		return nil, errJournal(err, "error.devolucion_no_registrada")
 	}
 
-	for _, prestamo := range b.Prestamos {
-		if !prestamo.Devuelto {
-			prestamosActivos++
-		}
+	// Si hay reservas el ejemplar queda apartado para el primero de la cola
+	// La devolución ya quedó registrada: si falla el apartado solo se deshace
+	// el apartado y se avisa, la devolución no falla
+	if err := b.apartarParaSiguiente(libro, libro.buscarEjemplar(prestamoActivo.EjemplarID), ahora); err != nil {
+		b.avisar(err)
 	}
-	return fmt.Sprintf(`📊 Estadísticas de %s: 
-		📚 Total de libros: %d
-		📖 Libros prestados: %d
-		📕 Libros disponibles: %d
-		👥 Usuarios activos: %d
-		📋 Préstamos activos: %d`, b.Nombre, totalLibros, librosPrestados, totalLibros-librosPrestados, usuariosActivos, prestamosActivos)
+
+	devuelto := *prestamoActivo
+	return &devuelto, nil
 }
 
 // ListarLibrosDisponibles muestra todos los libros disponibles
-// Usa receptor de VALOR porque solo lee
-func (b Biblioteca) ListarLibrosDisponibles() {
-	fmt.Println("📚 Libros disponibles:")
+func (b *Biblioteca) ListarLibrosDisponibles() {
+	b.ListarLibrosDisponiblesEn(IdiomaPorDefecto)
+}
+
+// ListarLibrosDisponiblesEn es ListarLibrosDisponibles en el idioma indicado
+// Solo lee, por eso toma el lock de lectura
+func (b *Biblioteca) ListarLibrosDisponiblesEn(idioma Idioma) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	fmt.Println(idioma.Texto("disponibles.titulo"))
 	fmt.Println("=" + strings.Repeat("=", 50))
 
 	disponibles := 0
 	for _, libro := range b.Libros {
-		if !libro.Prestado {
-			fmt.Printf(" %s\n", libro.ObtenerInfo())
+		if libro.Disponibles() > 0 {
+			fmt.Printf(" %s\n", libro.ObtenerInfoEn(idioma))
 			if libro.EsGrande() {
-				fmt.Printf("     📖 Libro extenso (%d páginas)\n",
-					libro.Paginas)
+				fmt.Println(idioma.Texto("disponibles.extenso", libro.Paginas))
 			}
+			disponibles++
 		}
-		disponibles++
 	}
 
 	if disponibles == 0 {
-		fmt.Println(" No hay libros disponibles")
+		fmt.Println(idioma.Texto("disponibles.ninguno"))
 	}
 }
 
 // ==========================================
-// FUNCIÓN PRINCIPAL DEMOSTRATIVA
+// FUNCIÓN PRINCIPAL
 // ==========================================
+// main delega en la herramienta de línea de comandos (ver cli.go)
 func main() {
-	fmt.Println("🏛 SISTEMA DE BIBLIOTECA - DEMO PRÁCTICA")
-	fmt.Println("=" + strings.Repeat("=", 50))
-
-	// PASO 1: Crear biblioteca
-	biblioteca := NuevaBiblioteca("Biblioteca Central", "Av. Principal 123")
-	fmt.Printf("\n✅ Biblioteca creada: %s\n", biblioteca.Nombre)
-
-	// PASO 2: Agregar libros
-	fmt.Println("\n📚 Agregando libros...")
-
-	libros := []struct {
-		titulo, autor, isbn string
-		paginas             int
-	}{
-		{"El Quijote", "Miguel de Cervantes", "978-84-376-0494-7", 863},
-		{"Cien Años de Soledad", "Gabriel García Márquez", "978-84-376-0495-4", 471},
-		{"Go Programming", "Alan Donovan", "978-0-13-419044-0", 380},
-		{"Clean Code", "Robert Martin", "978-0-13-235088-4", 464},
-	}
-	for _, l := range libros {
-		libro, err := biblioteca.AgregarLibro(l.titulo, l.autor, l.isbn, l.paginas)
-		if err != nil {
-			fmt.Printf("❌ Error al agregar libro: %s\n", err)
-		} else {
-			fmt.Printf("✅ Agregado libro: %s\n", libro.ObtenerInfo())
-		}
-	}
-
-	// PASO 3: Registrar usuarios
-	fmt.Println("\n👥 Registrando usuarios...")
-
-	usuarios := []struct {
-		nombre, email, telefono string
-	}{
-		{"Carlos", "carlos@gmail.com", "+56 999 999 999"},
-		{"Maria", "maria@gmail.com", "+56 999 999 999"},
-		{"Juan", "juan@gmail.com", "+56 999 999 999"},
-		{"Pedro", "pedro@gmail.com", "+56 999 999 999"},
-	}
-
-	for _, u := range usuarios {
-		usuario, err := biblioteca.RegistrarUsuario(u.nombre, u.email, u.telefono)
-		if err != nil {
-			fmt.Printf("❌ Error al registrar usuario: %s\n", err)
-		} else {
-			fmt.Printf("✅ Registrado usuario: %s\n", usuario.ObtenerResumen())
-		}
-	}
-
-	// PASO 4: Realizar préstamos
-	fmt.Println("\n📋 Realizando préstamos...")
-
-	prestamos := []struct {
-		libroID, usuarioID int
-	}{
-		{1, 1},
-		{3, 2},
-		{2, 3},
-	}
-
-	for _, p := range prestamos {
-		err := biblioteca.PrestarLibro(p.libroID, p.usuarioID)
-		if err != nil {
-			fmt.Printf("❌ Error al realizar préstamo: %s\n", err)
-		} else {
-			libro := biblioteca.BuscarLibro(p.libroID)
-			usuario := biblioteca.BuscarUsuario(p.usuarioID)
-			fmt.Printf(" ✅ %s prestó '%s'\n", usuario.Nombre, libro.Titulo)
-		}
-	}
-
-	// PASO 5: Mostrar estado actual
-	biblioteca.ListarLibrosDisponibles()
-
-	// PASO 6: Devolver un libro
-	fmt.Println("\n🔄 Devolviendo libro...")
-	err := biblioteca.DevolverLibro(1) // Libro 1 - El Quijote
-	if err != nil {
-		fmt.Printf("❌ Error al devolver libro: %s\n", err)
-	} else {
-		fmt.Printf("✅ Libro devuelto\n")
-	}
-
-	// PASO 7: Mostrar estadísticas finales
-	fmt.Println("\n" + biblioteca.ObtenerEstadisticas())
-
-	// PASO 8: Demostrar diferencia entre receptor de valor y puntero
-	fmt.Println("\n🔍 DEMO: Diferencia entre receptores")
-	fmt.Println("=" + strings.Repeat("=", 50))
-
-	libro := biblioteca.BuscarLibro(4) // Clean Code
-	fmt.Printf("Estado inicial: %s\n", libro.ObtenerInfo())
-
-	// Intentar prestar (modifica el struct)
-	err = libro.Prestar()
-	if err != nil {
-		fmt.Printf("❌ Error al prestar libro: %s\n", err)
-	} else {
-		fmt.Printf("Despues del prestamo: %s\n", libro.ObtenerInfo())
-	}
-
-	// Verificar info (no modificada)
-	fmt.Printf("¿Es prestable?: %v\n", libro.EsPrestable())
-	fmt.Printf("¿Es libro grande?: %v\n", libro.EsGrande())
-	fmt.Println("\n🎯 ¡Demo completada! Los estudiantes pueden ver:")
-	fmt.Println(" • Structs básicos y composición")
-	fmt.Println(" • Métodos con receptor de valor (lectura)")
-	fmt.Println(" • Métodos con receptor de puntero (modificación)")
-	fmt.Println(" • Validaciones y manejo de errores")
-	fmt.Println(" • Lógica de negocio completa")
-
+	os.Exit(ejecutarCLI(os.Args[1:], os.Stdout, os.Stderr))
 }
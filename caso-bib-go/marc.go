@@ -0,0 +1,421 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ==========================================
+// MARC21: ISO 2709 Y MARCXML
+// ==========================================
+
+// Delimitadores de ISO 2709
+const (
+	finDeCampo    = 0x1E
+	finDeRegistro = 0x1D
+	subcampoMARC  = 0x1F
+)
+
+// liderPorDefecto describe un libro impreso en Unicode: estado nuevo (n),
+// material textual (a), monografía (m), codificación UTF-8 (a) y
+// catalogación sin puntuación ISBD (c), porque MARCDeLibro no la agrega
+// Las posiciones de largo y dirección base se completan al codificar
+const liderPorDefecto = "00000nam a2200000 c 4500"
+
+// posicionCatalogacion es la posición del líder que indica si los valores
+// traen puntuación ISBD ('c' = sin puntuación)
+const posicionCatalogacion = 18
+
+// SubcampoMARC es un subcampo ($a, $b, ...) de un campo de datos
+type SubcampoMARC struct {
+	Codigo string
+	Valor  string
+}
+
+// CampoMARC es un campo de control (001-009, solo Valor) o de datos
+// (010-999, con indicadores y subcampos)
+type CampoMARC struct {
+	Etiqueta  string
+	Ind1      string
+	Ind2      string
+	Valor     string
+	Subcampos []SubcampoMARC
+}
+
+// esControl indica si el campo es de control (etiquetas 00X)
+func (c CampoMARC) esControl() bool {
+	return strings.HasPrefix(c.Etiqueta, "00")
+}
+
+// RegistroMARC es un registro bibliográfico MARC21
+type RegistroMARC struct {
+	Lider  string
+	Campos []CampoMARC
+}
+
+// Subcampo retorna el primer subcampo con el código indicado de la
+// primera aparición de la etiqueta, o "" si no existe
+func (r RegistroMARC) Subcampo(etiqueta, codigo string) string {
+	for _, campo := range r.Campos {
+		if campo.Etiqueta != etiqueta {
+			continue
+		}
+		for _, sub := range campo.Subcampos {
+			if sub.Codigo == codigo {
+				return sub.Valor
+			}
+		}
+	}
+	return ""
+}
+
+// Control retorna el valor de un campo de control, o "" si no existe
+func (r RegistroMARC) Control(etiqueta string) string {
+	for _, campo := range r.Campos {
+		if campo.Etiqueta == etiqueta {
+			return campo.Valor
+		}
+	}
+	return ""
+}
+
+// MARCDeLibro convierte un libro en un registro MARC21:
+// 001 ID, 020 $a ISBN, 100 $a autor, 245 $a título y 300 $a páginas
+// Los valores se guardan sin puntuación ISBD para que la conversión sea reversible
+func MARCDeLibro(libro Libro) RegistroMARC {
+	registro := RegistroMARC{Lider: liderPorDefecto}
+	registro.Campos = append(registro.Campos, CampoMARC{Etiqueta: "001", Valor: strconv.Itoa(int(libro.ID))})
+	if libro.ISBN != "" {
+		registro.Campos = append(registro.Campos, CampoMARC{Etiqueta: "020", Ind1: " ", Ind2: " ",
+			Subcampos: []SubcampoMARC{{Codigo: "a", Valor: libro.ISBN}}})
+	}
+	registro.Campos = append(registro.Campos,
+		CampoMARC{Etiqueta: "100", Ind1: "1", Ind2: " ",
+			Subcampos: []SubcampoMARC{{Codigo: "a", Valor: libro.Autor}}},
+		CampoMARC{Etiqueta: "245", Ind1: "1", Ind2: "0",
+			Subcampos: []SubcampoMARC{{Codigo: "a", Valor: libro.Titulo}}})
+	if libro.Paginas > 0 {
+		registro.Campos = append(registro.Campos, CampoMARC{Etiqueta: "300", Ind1: " ", Ind2: " ",
+			Subcampos: []SubcampoMARC{{Codigo: "a", Valor: fmt.Sprintf("%d p.", libro.Paginas)}}})
+	}
+	return registro
+}
+
+// LibroDeMARC extrae los datos de un libro de un registro MARC21
+// Acepta registros de otros catálogos: quita la puntuación ISBD final si el
+// líder indica que la trae, toma el primer ISBN de 020 $a y el primer
+// número de 300 $a. Sin autor personal (100) usa el autor corporativo (110)
+func LibroDeMARC(registro RegistroMARC) Libro {
+	limpiar := strings.TrimSpace
+	if len(registro.Lider) != 24 || registro.Lider[posicionCatalogacion] != 'c' {
+		limpiar = sinPuntuacionISBD
+	}
+	libro := Libro{
+		Titulo:  limpiar(registro.Subcampo("245", "a")),
+		Autor:   limpiar(registro.Subcampo("100", "a")),
+		Paginas: primerNumero(registro.Subcampo("300", "a")),
+	}
+	if libro.Autor == "" {
+		libro.Autor = limpiar(registro.Subcampo("110", "a"))
+	}
+	if isbn := strings.Fields(registro.Subcampo("020", "a")); len(isbn) > 0 {
+		libro.ISBN = isbn[0]
+	}
+	if id, err := strconv.Atoi(strings.TrimSpace(registro.Control("001"))); err == nil {
+		libro.ID = LibroID(id)
+	}
+	return libro
+}
+
+// EscribirISO2709 codifica los registros en el formato de intercambio ISO 2709
+func EscribirISO2709(w io.Writer, registros []RegistroMARC) error {
+	for i, registro := range registros {
+		datos, err := codificarISO2709(registro)
+		if err != nil {
+			return errNoExportable(err, "error.marc_registro", i+1)
+		}
+		if _, err := w.Write(datos); err != nil {
+			return errArchivo(err, "error.escribir_registros")
+		}
+	}
+	return nil
+}
+
+// codificarISO2709 arma líder, directorio y campos de un registro
+func codificarISO2709(registro RegistroMARC) ([]byte, error) {
+	lider := registro.Lider
+	if len(lider) != 24 {
+		lider = liderPorDefecto
+	}
+
+	var directorio, campos bytes.Buffer
+	for _, campo := range registro.Campos {
+		if len(campo.Etiqueta) != 3 {
+			return nil, errNoExportable(nil, "error.marc_etiqueta", campo.Etiqueta)
+		}
+		inicio := campos.Len()
+		if campo.esControl() {
+			campos.WriteString(campo.Valor)
+		} else {
+			campos.WriteString(indicador(campo.Ind1))
+			campos.WriteString(indicador(campo.Ind2))
+			for _, sub := range campo.Subcampos {
+				campos.WriteByte(subcampoMARC)
+				campos.WriteString(sub.Codigo)
+				campos.WriteString(sub.Valor)
+			}
+		}
+		campos.WriteByte(finDeCampo)
+		largo := campos.Len() - inicio
+		if largo > 9999 || inicio > 99999 {
+			return nil, errNoExportable(nil, "error.marc_campo_grande", campo.Etiqueta)
+		}
+		fmt.Fprintf(&directorio, "%s%04d%05d", campo.Etiqueta, largo, inicio)
+	}
+	directorio.WriteByte(finDeCampo)
+
+	base := 24 + directorio.Len()
+	total := base + campos.Len() + 1
+	if total > 99999 {
+		return nil, errNoExportable(nil, "error.marc_registro_grande")
+	}
+
+	var salida bytes.Buffer
+	fmt.Fprintf(&salida, "%05d%s%05d%s", total, lider[5:12], base, lider[17:])
+	salida.Write(directorio.Bytes())
+	salida.Write(campos.Bytes())
+	salida.WriteByte(finDeRegistro)
+	return salida.Bytes(), nil
+}
+
+// LeerISO2709 decodifica todos los registros ISO 2709 de r
+// Se toleran saltos de línea entre registros, que agregan algunas herramientas
+func LeerISO2709(r io.Reader) ([]RegistroMARC, error) {
+	entrada := bufio.NewReader(r)
+	registros := make([]RegistroMARC, 0)
+	for {
+		datos, err := entrada.ReadBytes(finDeRegistro)
+		datos = bytes.TrimLeft(datos, "\r\n")
+		if len(datos) > 0 {
+			if datos[len(datos)-1] != finDeRegistro {
+				return nil, errFormato(nil, "error.marc_sin_fin", len(registros)+1)
+			}
+			registro, errRegistro := decodificarISO2709(datos)
+			if errRegistro != nil {
+				return nil, errFormato(errRegistro, "error.marc_registro_invalido", len(registros)+1)
+			}
+			registros = append(registros, registro)
+		}
+		if errors.Is(err, io.EOF) {
+			return registros, nil
+		}
+		if err != nil {
+			return nil, errArchivo(err, "error.leer_registros")
+		}
+	}
+}
+
+// decodificarISO2709 interpreta un registro completo, incluido el fin de registro
+func decodificarISO2709(datos []byte) (RegistroMARC, error) {
+	if len(datos) < 25 {
+		return RegistroMARC{}, errFormato(nil, "error.marc_corto")
+	}
+	lider := string(datos[:24])
+	base, err := strconv.Atoi(lider[12:17])
+	if err != nil || base < 25 || base > len(datos) {
+		return RegistroMARC{}, errFormato(nil, "error.marc_base", lider[12:17])
+	}
+
+	registro := RegistroMARC{Lider: lider}
+	directorio := datos[24 : base-1]
+	if len(directorio)%12 != 0 {
+		return RegistroMARC{}, errFormato(nil, "error.marc_directorio")
+	}
+	campos := datos[base:]
+	for i := 0; i < len(directorio); i += 12 {
+		entrada := string(directorio[i : i+12])
+		largo, errLargo := strconv.Atoi(entrada[3:7])
+		inicio, errInicio := strconv.Atoi(entrada[7:12])
+		if errLargo != nil || errInicio != nil || largo < 1 || inicio+largo > len(campos) {
+			return RegistroMARC{}, errFormato(nil, "error.marc_entrada", entrada)
+		}
+		// el campo termina con finDeCampo, que no forma parte del valor
+		contenido := string(campos[inicio : inicio+largo-1])
+		campo := CampoMARC{Etiqueta: entrada[:3]}
+		if campo.esControl() {
+			campo.Valor = contenido
+		} else {
+			if len(contenido) < 2 {
+				return RegistroMARC{}, errFormato(nil, "error.marc_sin_indicadores", campo.Etiqueta)
+			}
+			campo.Ind1, campo.Ind2 = contenido[:1], contenido[1:2]
+			for _, parte := range strings.Split(contenido[2:], string(rune(subcampoMARC)))[1:] {
+				if parte == "" {
+					continue
+				}
+				campo.Subcampos = append(campo.Subcampos, SubcampoMARC{Codigo: parte[:1], Valor: parte[1:]})
+			}
+		}
+		registro.Campos = append(registro.Campos, campo)
+	}
+	return registro, nil
+}
+
+// Estructuras de MARCXML
+type (
+	coleccionMARCXML struct {
+		XMLName   xml.Name          `xml:"http://www.loc.gov/MARC21/slim collection"`
+		Registros []registroMARCXML `xml:"record"`
+	}
+	registroMARCXML struct {
+		Lider     string           `xml:"leader"`
+		Controles []controlMARCXML `xml:"controlfield"`
+		Datos     []datoMARCXML    `xml:"datafield"`
+	}
+	controlMARCXML struct {
+		Etiqueta string `xml:"tag,attr"`
+		Valor    string `xml:",chardata"`
+	}
+	datoMARCXML struct {
+		Etiqueta  string            `xml:"tag,attr"`
+		Ind1      string            `xml:"ind1,attr"`
+		Ind2      string            `xml:"ind2,attr"`
+		Subcampos []subcampoMARCXML `xml:"subfield"`
+	}
+	subcampoMARCXML struct {
+		Codigo string `xml:"code,attr"`
+		Valor  string `xml:",chardata"`
+	}
+)
+
+// EscribirMARCXML escribe los registros como una colección MARCXML
+func EscribirMARCXML(w io.Writer, registros []RegistroMARC) error {
+	coleccion := coleccionMARCXML{Registros: make([]registroMARCXML, 0, len(registros))}
+	for _, registro := range registros {
+		xmlRegistro := registroMARCXML{Lider: registro.Lider}
+		for _, campo := range registro.Campos {
+			if campo.esControl() {
+				xmlRegistro.Controles = append(xmlRegistro.Controles, controlMARCXML{campo.Etiqueta, campo.Valor})
+				continue
+			}
+			dato := datoMARCXML{Etiqueta: campo.Etiqueta, Ind1: indicador(campo.Ind1), Ind2: indicador(campo.Ind2)}
+			for _, sub := range campo.Subcampos {
+				dato.Subcampos = append(dato.Subcampos, subcampoMARCXML{sub.Codigo, sub.Valor})
+			}
+			xmlRegistro.Datos = append(xmlRegistro.Datos, dato)
+		}
+		coleccion.Registros = append(coleccion.Registros, xmlRegistro)
+	}
+	return escribirXML(w, coleccion)
+}
+
+// LeerMARCXML lee todos los <record> de r, sea una colección o un registro suelto
+func LeerMARCXML(r io.Reader) ([]RegistroMARC, error) {
+	registros := make([]RegistroMARC, 0)
+	err := recorrerXML(r, "record", func(decoder *xml.Decoder, inicio xml.StartElement) error {
+		var xmlRegistro registroMARCXML
+		if err := decoder.DecodeElement(&xmlRegistro, &inicio); err != nil {
+			return err
+		}
+		registro := RegistroMARC{Lider: xmlRegistro.Lider}
+		for _, control := range xmlRegistro.Controles {
+			registro.Campos = append(registro.Campos, CampoMARC{Etiqueta: control.Etiqueta, Valor: control.Valor})
+		}
+		for _, dato := range xmlRegistro.Datos {
+			campo := CampoMARC{Etiqueta: dato.Etiqueta, Ind1: indicador(dato.Ind1), Ind2: indicador(dato.Ind2)}
+			for _, sub := range dato.Subcampos {
+				campo.Subcampos = append(campo.Subcampos, SubcampoMARC{sub.Codigo, sub.Valor})
+			}
+			registro.Campos = append(registro.Campos, campo)
+		}
+		// MARCXML separa control y datos; se restituye el orden por etiqueta
+		sort.SliceStable(registro.Campos, func(i, j int) bool {
+			return registro.Campos[i].Etiqueta < registro.Campos[j].Etiqueta
+		})
+		registros = append(registros, registro)
+		return nil
+	})
+	if err != nil {
+		return nil, errFormato(err, "error.marcxml_invalido")
+	}
+	return registros, nil
+}
+
+// escribirXML escribe la declaración XML y el valor indentado
+func escribirXML(w io.Writer, valor any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return errArchivo(err, "error.escribir_registros")
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(valor); err != nil {
+		return errArchivo(err, "error.escribir_registros")
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return errArchivo(err, "error.escribir_registros")
+	}
+	return nil
+}
+
+// recorrerXML llama a procesar por cada elemento con el nombre local indicado,
+// sin importar el namespace ni cuán anidado esté
+func recorrerXML(r io.Reader, nombre string, procesar func(*xml.Decoder, xml.StartElement) error) error {
+	decoder := xml.NewDecoder(r)
+	for {
+		token, err := decoder.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if inicio, ok := token.(xml.StartElement); ok && inicio.Name.Local == nombre {
+			if err := procesar(decoder, inicio); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// indicador normaliza un indicador vacío al blanco de MARC
+func indicador(valor string) string {
+	if valor == "" {
+		return " "
+	}
+	return valor
+}
+
+// sinPuntuacionISBD quita el separador que ISBD agrega al final de cada
+// elemento: " /", " :", " ;" o " =" precedidos de espacio, o una coma
+// Solo quita uno, así no recorta signos que forman parte del título
+func sinPuntuacionISBD(texto string) string {
+	texto = strings.TrimSpace(texto)
+	for _, separador := range []string{" /", " :", " ;", " =", ","} {
+		if recortado, ok := strings.CutSuffix(texto, separador); ok {
+			return strings.TrimSpace(recortado)
+		}
+	}
+	return texto
+}
+
+// primerNumero retorna el primer entero que aparece en el texto, o 0
+// ("xii, 345 p. : il." da 345 porque los romanos no son dígitos)
+func primerNumero(texto string) int {
+	inicio := strings.IndexFunc(texto, func(r rune) bool { return r >= '0' && r <= '9' })
+	if inicio < 0 {
+		return 0
+	}
+	fin := inicio
+	for fin < len(texto) && texto[fin] >= '0' && texto[fin] <= '9' {
+		fin++
+	}
+	numero, _ := strconv.Atoi(texto[inicio:fin])
+	return numero
+}
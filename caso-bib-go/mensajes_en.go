@@ -0,0 +1,312 @@
+package main
+
+// mensajesEN es el catálogo en inglés
+var mensajesEN = map[ClaveMensaje]string{
+	// formatos de fecha y número
+	"formato.fecha":             "01/02/2006",
+	"formato.fecha_larga":       "%[2]s %[1]d, %[3]d",
+	"formato.mes":               "%s %d",
+	"formato.separador_decimal": ".",
+
+	"mes.1": "January", "mes.2": "February", "mes.3": "March", "mes.4": "April",
+	"mes.5": "May", "mes.6": "June", "mes.7": "July", "mes.8": "August",
+	"mes.9": "September", "mes.10": "October", "mes.11": "November", "mes.12": "December",
+
+	"dia.0": "Sunday", "dia.1": "Monday", "dia.2": "Tuesday", "dia.3": "Wednesday",
+	"dia.4": "Thursday", "dia.5": "Friday", "dia.6": "Saturday",
+
+	"categoria.estudiante": "student",
+	"categoria.docente":    "faculty",
+	"categoria.publico":    "public",
+
+	"reserva.estado.pendiente": "pending",
+	"reserva.estado.lista":     "ready",
+	"reserva.estado.cumplida":  "fulfilled",
+	"reserva.estado.cancelada": "cancelled",
+	"reserva.estado.vencida":   "expired",
+
+	// libros y usuarios
+	"libro.info":                 "[%d] %s by %s - %s",
+	"libro.sin_ejemplares":       "No copies",
+	"libro.disponibilidad.uno":   "%d of %d available",
+	"libro.disponibilidad.otros": "%d of %d available",
+	"usuario.activo":             "Active",
+	"usuario.inactivo":           "Inactive",
+	"usuario.suspendido":         "Suspended",
+	"usuario.resumen":            "%s (%s) - %s",
+	"usuario.resumen_categoria":  "%s (%s) [%s] - %s",
+
+	"disponibles.titulo":  "📚 Available books:",
+	"disponibles.extenso": "     📖 Long book (%d pages)",
+	"disponibles.ninguno": " No books available",
+
+	// comprobantes de préstamo de la CLI
+	"prestamo.creado":         "✅ Loan %d created, due by %s",
+	"prestamo.devuelto":       "✅ Book returned (loan %d)",
+	"prestamo.multa":          "💰 Late fee: %s",
+	"prestamo.multa_pagada":   "✅ Late fee for loan %d paid (%s)",
+	"prestamo.renovado.uno":   "✅ Loan %d renewed until %s (%d renewal)",
+	"prestamo.renovado.otros": "✅ Loan %d renewed until %s (%d renewals)",
+
+	// salida de la CLI
+	"cli.comando_desconocido":          "Unknown command '%s'",
+	"cli.argumentos_inesperados":       "Unexpected arguments: %s",
+	"cli.falta_opcion":                 "You must provide %s",
+	"biblioteca.creada":                "✅ Library created: %s",
+	"libro.agregado":                   "✅ Added book: %s",
+	"libro.actualizado":                "✅ Updated book: %s",
+	"libro.ninguno":                    " No books",
+	"libro.sin_resultados":             " No results",
+	"libro.exportado":                  "✅ Catalog exported to '%s'",
+	"ejemplar.agregado":                "✅ Added copy [%d] %s: %s",
+	"usuario.registrado":               "✅ Registered user [%d]: %s",
+	"usuario.actualizado":              "✅ Updated user [%d]: %s",
+	"usuario.ninguno":                  " No users",
+	"usuario.revisados.uno":            "✅ %d user with suspension changes",
+	"usuario.revisados.otros":          "✅ %d users with suspension changes",
+	"importacion.fila.error":           " ❌ row %d: %s",
+	"importacion.fila.valida":          " ✔️  row %d: %s",
+	"importacion.fila.importada":       " ✅ row %d: [%d] %s",
+	"importacion.registro.error":       " ❌ record %d: %s",
+	"importacion.registro.valida":      " ✔️  record %d: %s",
+	"importacion.registro.importada":   " ✅ record %d: [%d] %s",
+	"importacion.columnas_ignoradas":   "Ignored columns: %s",
+	"importacion.resumen.uno":          "📥 %d of %d book imported, %d with errors",
+	"importacion.resumen.otros":        "📥 %d of %d books imported, %d with errors",
+	"importacion.resumen_prueba.uno":   "📥 %d of %d book valid (dry run, nothing was saved), %d with errors",
+	"importacion.resumen_prueba.otros": "📥 %d of %d books valid (dry run, nothing was saved), %d with errors",
+	"historial.vacio":                  " No loans",
+	"historial.prestamo.uno":           " [%d] book %d, user %d: lent on %s, %s, %d day",
+	"historial.prestamo.otros":         " [%d] book %d, user %d: lent on %s, %s, %d days",
+	"historial.atraso.uno":             ", %d day overdue, fine %s",
+	"historial.atraso.otros":           ", %d days overdue, fine %s",
+	"historial.pagina.uno":             " Page %d of %d (%d loan)",
+	"historial.pagina.otros":           " Page %d of %d (%d loans)",
+	"prestamo.estado.activo":           "active",
+	"prestamo.estado.devuelto":         "returned",
+	"prestamo.estado.devuelto_el":      "returned on %s",
+	"prestamo.ninguno":                 " No loans",
+	"prestamo.ninguno_vencido":         " No overdue loans",
+	"prestamo.linea":                   " [%d] book %d, user %d: %s, due %s",
+	"prestamo.vencido.uno":             " [%d] book %d, user %d: %d day overdue, fine %s",
+	"prestamo.vencido.otros":           " [%d] book %d, user %d: %d days overdue, fine %s",
+	"reserva.creada":                   "✅ Hold %d created, position %d in the queue",
+	"reserva.cancelada":                "✅ Hold %d cancelled",
+	"reserva.ninguna":                  " No holds",
+	"reserva.linea":                    " %d. [%d] user %d - %s",
+	"reserva.retirar_antes":            " (pick up by %s)",
+	"reserva.vencidas.uno":             "✅ %d hold expired",
+	"reserva.vencidas.otros":           "✅ %d holds expired",
+	"calendario.cerrado":               "closed",
+	"calendario.feriado":               "holiday",
+	"calendario.actualizado.uno":       "✅ Calendar updated (%d holiday)",
+	"calendario.actualizado.otros":     "✅ Calendar updated (%d holidays)",
+	"verificar.consistente":            "✅ The library is consistent",
+	"verificar.resumen.uno":            "%d inconsistency, repaired: %d",
+	"verificar.resumen.otros":          "%d inconsistencies, repaired: %d",
+	"servir.iniciado":                  "🏛 Serving %s on %s",
+
+	// verificación de consistencia
+	"consistencia.prestamo_huerfano":          "Loan %d points to %s",
+	"consistencia.prestamo_huerfano_devuelto": "Loan %d points to %s; it was already returned and is kept in the history",
+	"consistencia.libro_inexistente":          "a missing book (%d)",
+	"consistencia.usuario_inexistente":        "a missing user (%d)",
+	"consistencia.ejemplar_inexistente":       "a missing copy (%d)",
+	"consistencia.ejemplar_ajeno":             "copy %d, which belongs to book %d",
+	"consistencia.prestamo_duplicado":         "Copy %d has active loans %d and %d",
+	"consistencia.reserva_huerfana":           "Hold %d points to book %d and user %d, and one of them does not exist",
+	"consistencia.reserva_sin_apartado":       "Hold %d is ready but copy %d is not set aside for user %d",
+	"consistencia.ejemplar_sin_prestamo":      "Copy '%s' of '%s' is marked as lent without an active loan",
+	"consistencia.prestamo_sin_marca":         "Copy '%s' of '%s' has active loan %d but is marked as available",
+	"consistencia.apartado_sin_reserva":       "Copy '%s' of '%s' is set aside for user %d without a ready hold",
+	"consistencia.secuencias":                 "Sequences %s would repeat existing IDs, they should be %s",
+	"consistencia.indice":                     "The active loan index does not match the loans",
+
+	// simulación
+	"simulacion.periodo":       "📅 Period: %s to %s (%d opening days, seed %d)",
+	"simulacion.prestamos":     "📖 Loans: %d",
+	"simulacion.devoluciones":  "📕 Returns: %d (%d late)",
+	"simulacion.renovaciones":  "🔁 Renewals: %d",
+	"simulacion.reservas":      "📌 Holds: %d (%d expired without pickup)",
+	"simulacion.rechazos":      "🚫 Requests not served: %d",
+	"simulacion.multas":        "💰 Fines collected: %s",
+	"simulacion.cierre":        "📋 At close: %d active loans, %d overdue",
+	"simulacion.mas_prestados": "🏆 Most borrowed:",
+
+	// configuración de la CLI
+	"politica.actualizada":         "✅ Policy for category '%s' updated",
+	"politica.actualizada_general": "✅ Policy for users without a category updated",
+
+	// estadísticas
+	"estadisticas.titulo":          "📊 Statistics for %s as of %s",
+	"estadisticas.libros":          "   📚 Total books: %d",
+	"estadisticas.ejemplares":      "   📦 Total copies: %d",
+	"estadisticas.prestados":       "   📖 Copies on loan: %d",
+	"estadisticas.disponibles":     "   📕 Copies available: %d",
+	"estadisticas.usuarios":        "   👥 Active users: %d of %d",
+	"estadisticas.lectores":        "   🙋 Readers (last %d days): %d (%s%% of active users)",
+	"estadisticas.prestamos.uno":   "   📋 Active loans: %d (%d overdue)",
+	"estadisticas.prestamos.otros": "   📋 Active loans: %d (%d overdue)",
+	"estadisticas.atraso.uno":      "   ⏰ Late return rate: %s%% of %d loan",
+	"estadisticas.atraso.otros":    "   ⏰ Late return rate: %s%% of %d loans",
+	"estadisticas.duracion":        "   ⏳ Average loan length: %s days",
+	"estadisticas.mas_prestados":   "   🏆 Most borrowed titles:",
+	"estadisticas.autores":         "   ✍️  Most borrowed authors:",
+	"estadisticas.circulacion":     "   📅 Monthly circulation (loans / returns):",
+
+	// errores de búsqueda
+	"error.libro_no_encontrado":    "There is no book with ID '%d'",
+	"error.usuario_no_encontrado":  "There is no user with ID '%d'",
+	"error.ejemplar_no_encontrado": "There is no copy with ID '%d'",
+	"error.prestamo_no_encontrado": "There is no loan with ID '%d'",
+	"error.reserva_no_encontrada":  "There is no reservation with ID '%d'",
+	"error.ejemplar_ajeno":         "The book '%s' has no copy '%d'",
+	"error.libro_sin_prestamo":     "There is no active loan for the book '%s'",
+	"error.ejemplar_sin_prestamo":  "There is no active loan for the copy '%s'",
+
+	// errores de préstamos, renovaciones y reservas
+	"error.libro_invalido":            "The book '%s' is not valid",
+	"error.libro_no_disponible":       "The book '%s' cannot be lent: %s",
+	"error.ejemplar_prestado":         "The copy '%s' of '%s' is already on loan",
+	"error.ejemplar_no_prestado":      "The copy '%s' of '%s' is not on loan",
+	"error.ejemplar_reservado":        "The copy '%s' of '%s' is on hold for another user",
+	"error.usuario_inactivo":          "The user '%s' cannot borrow",
+	"error.usuario_suspendido_multas": "The user '%s' is suspended for unpaid late fees (owes %s)",
+	"error.usuario_suspendido_atraso": "The user '%s' is suspended: loan %d is %d days overdue",
+	"error.multa_no_pendiente":        "The loan '%d' has no late fee pending payment",
+	"error.limite_prestamos.uno":      "The user '%s' already has %d active loan, the limit for category '%s' is %d",
+	"error.limite_prestamos.otros":    "The user '%s' already has %d active loans, the limit for category '%s' is %d",
+	"error.varios_prestados":          "The book '%s' has %d copies on loan, specify which one is returned",
+	"error.prestamo_devuelto":         "The loan '%d' was already returned",
+	"error.prestamo_vencido":          "The loan '%d' is overdue and cannot be renewed",
+	"error.max_renovaciones.uno":      "The loan '%d' reached the maximum of %d renewal",
+	"error.max_renovaciones.otros":    "The loan '%d' reached the maximum of %d renewals",
+	"error.reservado_por_otros":       "Other users have reserved the book with ID '%d'",
+	"error.reserva_innecesaria":       "The book '%s' has %s, no reservation needed",
+	"error.ya_prestado_al_usuario":    "The user '%s' already has '%s' on loan",
+	"error.reserva_duplicada":         "The user '%s' already reserved '%s'",
+	"error.reserva_no_activa":         "The reservation '%d' is already %s",
+	"error.prestamo_no_registrado":    "The loan was not made because it could not be written to the journal",
+	"error.devolucion_no_registrada":  "The return was not made because it could not be written to the journal",
+	"error.libro_no_registrado":       "The book was not added because it could not be written to the journal",
+	"error.ejemplar_no_registrado":    "The copy was not added because it could not be written to the journal",
+	"error.usuario_no_registrado":     "The user was not registered because it could not be written to the journal",
+	"error.cambio_no_registrado":      "The change was not applied because it could not be written to the journal",
+	"error.renovacion_no_registrada":  "The renewal was not made because it could not be written to the journal",
+	"error.reserva_no_registrada":     "The hold was not placed because it could not be written to the journal",
+	"error.cancelacion_no_registrada": "Reservation %d was not cancelled because it could not be written to the journal",
+	"error.vencimiento_no_registrado": "Reservation %d was not marked as expired because it could not be written to the journal",
+	"error.apartado_no_registrado":    "Could not hold the copy '%s' for reservation %d",
+	"error.journal_no_registrado":     "The operation was applied but is not in the journal",
+	"error.suspension_no_registrada":  "The suspension of user '%s' could not be recorded in the journal",
+	"error.pago_no_registrado":        "The payment was not made because it could not be recorded in the journal",
+	"error.calendario_no_registrado":  "The calendar was not changed because it could not be written to the journal",
+	"error.politica_no_registrada":    "The policy was not changed because it could not be written to the journal",
+	"error.journal_atrasado":          "The journal '%s' ends at event %d but the data already includes event %d",
+	"error.journal_incompleto":        "The journal '%s' starts at event %d but the data only includes up to event %d",
+	"error.datos_en_uso":              "Another process is modifying '%s', try again later",
+
+	// errores de datos
+	"error.faltan_titulo_autor":   "Title and author are required",
+	"error.faltan_paginas":        "Page count is required",
+	"error.faltan_nombre_email":   "Name and email are required",
+	"error.email_invalido":        "Invalid email '%s'",
+	"error.email_duplicado":       "A user with the email '%s' already exists",
+	"error.isbn_duplicado":        "A book with the ISBN '%s' already exists, add a copy instead",
+	"error.codigo_duplicado":      "A copy with the barcode '%s' already exists",
+	"error.categoria_desconocida": "Unknown category '%s' (valid categories: %s)",
+	"error.idioma_desconocido":    "Unknown language '%s' (available languages: %s)",
+	"error.isbn_invalido":         "Invalid ISBN '%s': %s",
+	"error.no_es_isbn10":          "'%s' is not an ISBN-10",
+	"error.no_es_isbn13":          "'%s' is not an ISBN-13",
+	"error.sin_isbn10":            "The ISBN '%s' has no ISBN-10 equivalent",
+	"isbn.caracter":               "unexpected character '%c'",
+	"isbn.control":                "wrong check digit",
+	"isbn.prefijo":                "must start with 978 or 979",
+	"isbn.largo":                  "must have 10 or 13 digits",
+	"error.horario_formato":       "Invalid opening hours for %s: use HH:MM",
+	"error.horario_rango":         "Invalid opening hours for %s: opens at %s and closes at %s",
+	"error.calendario_sin_dias":   "The calendar must have at least one day with opening hours",
+	"error.feriado_invalido":      "Invalid holiday '%s', use YYYY-MM-DD",
+	"error.politica_sin_plazo":    "The policy must have DiasPrestamo greater than zero",
+	"error.politica_negativa":     "The policy cannot have negative values",
+	"error.pagina_negativa":       "Page and page size cannot be negative",
+	"error.fechas_invertidas":     "The end date cannot be before the start date",
+	"error.fecha_desde":           "Invalid start date '%s', use YYYY-MM-DD",
+	"error.fecha_hasta":           "Invalid end date '%s', use YYYY-MM-DD",
+	"error.simulacion_sin_datos":  "The simulation needs days, books and users greater than zero",
+	"error.cuerpo_json":           "Invalid JSON body",
+	"error.id_invalido":           "Invalid ID '%s'",
+	"error.valor_invalido":        "Invalid value for %s: '%s'",
+
+	// errores de importación y exportación
+	"error.archivo_vacio":          "The file is empty",
+	"error.encabezado_ilegible":    "Could not read the header",
+	"error.paginas_invalidas":      "Invalid page count '%s'",
+	"error.ejemplares_invalidos":   "Invalid copy count '%s'",
+	"error.campo_desconocido":      "Unknown field '%s' for column '%s' (valid fields: %s)",
+	"error.columnas_repetidas":     "The columns '%s' and '%s' map to the same field '%s'",
+	"error.falta_columna":          "The header has no column for '%s'",
+	"error.separador_desconocido":  "Unknown separator '%s', use comma or tab",
+	"error.columna_invalida":       "Invalid column '%s', use Header=field",
+	"error.formato_desconocido":    "Unknown format '%s' (valid formats: marc, marcxml, dc)",
+	"error.marc_registro":          "MARC record %d",
+	"error.marc_etiqueta":          "Invalid tag '%s'",
+	"error.marc_campo_grande":      "Field %s exceeds the maximum ISO 2709 size",
+	"error.marc_registro_grande":   "The record exceeds the maximum ISO 2709 size",
+	"error.marc_sin_fin":           "Invalid MARC record %d: missing record terminator",
+	"error.marc_registro_invalido": "Invalid MARC record %d",
+	"error.marc_corto":             "the record is too short",
+	"error.marc_base":              "base address '%s' out of range",
+	"error.marc_directorio":        "the directory has an invalid length",
+	"error.marc_entrada":           "invalid directory entry '%s'",
+	"error.marc_sin_indicadores":   "field %s has no indicators",
+	"error.marcxml_invalido":       "Invalid MARCXML",
+	"error.dc_invalido":            "Invalid Dublin Core",
+	"error.leer_registros":         "Could not read the records",
+	"error.escribir_registros":     "Could not write the records",
+	"error.opciones_solo_csv":      "--separador and --columna are only used with --formato csv",
+	"error.csv_columna":            "Invalid format in column %d",
+
+	// errores de archivos y del journal
+	"error.archivo_leer":           "Could not read '%s'",
+	"error.archivo_escribir":       "Could not write '%s'",
+	"error.archivo_temporal":       "Could not create a temporary file in '%s'",
+	"error.archivo_reemplazar":     "Could not replace '%s'",
+	"error.archivo_crear":          "Could not create '%s'",
+	"error.archivo_existente":      "The file '%s' already exists",
+	"error.serializar_biblioteca":  "Could not serialize the library",
+	"error.snapshot_invalido":      "The file '%s' is not a valid snapshot",
+	"error.snapshot_migrar":        "Could not migrate from version %d",
+	"error.snapshot_version":       "Unsupported snapshot version: %d",
+	"error.calendario_invalido":    "The file '%s' is not a valid calendar",
+	"error.journal_abrir":          "Could not open the journal '%s'",
+	"error.journal_reparar":        "Could not repair the journal '%s'",
+	"error.journal_leer":           "Could not read the journal '%s'",
+	"error.journal_escribir":       "Could not write to the journal '%s'",
+	"error.journal_inconsistente":  "The journal '%s' was left inconsistent (%s) and could not be truncated",
+	"error.journal_linea_invalida": "Line %d of the journal '%s' is not valid",
+	"error.journal_recortar":       "Could not truncate the journal '%s'",
+	"error.evento_serializar":      "Could not serialize the event '%s'",
+	"error.evento_invalido":        "Invalid event %d in the journal '%s'",
+	"error.evento_incompleto":      "The event '%s' is missing data",
+	"error.evento_desconocido":     "Unknown event type '%s'",
+	"error.evento_sin_ejemplar":    "Event '%s' predates copies and book %d has no single copy to link it to",
+
+	// errores de consultas estructuradas
+	"consulta.error":                  "Query error (position %d): %s",
+	"consulta.no_se_esperaba":         "unexpected '%s'",
+	"consulta.fin":                    "end of query",
+	"consulta.comillas_sin_cerrar":    "unterminated quotes",
+	"consulta.se_esperaba_distinto":   "expected '!='",
+	"consulta.falta_parentesis":       "expected ')' to close the '(' at position %d",
+	"consulta.falta_valor":            "expected a value after '%s%s'",
+	"consulta.falta_campo":            "missing field before '%s'",
+	"consulta.campo_desconocido":      "unknown field '%s' (valid fields: %s)",
+	"consulta.expresion_no_soportada": "unsupported expression",
+	"consulta.valor_invalido":         "the field '%s' expects %s, not '%s'",
+	"consulta.tipo_numero":            "a number",
+	"consulta.tipo_booleano":          "true or false",
+	"consulta.tipo_fecha":             "a YYYY-MM-DD date",
+	"consulta.operador_invalido":      "the operator '%s' cannot be used with the field '%s'",
+}
@@ -0,0 +1,313 @@
+package main
+
+// mensajesES es el catálogo en español, el idioma por defecto
+// Toda clave nueva se agrega primero acá
+var mensajesES = map[ClaveMensaje]string{
+	// formatos de fecha y número
+	"formato.fecha":             "02/01/2006",
+	"formato.fecha_larga":       "%d de %s de %d",
+	"formato.mes":               "%s de %d",
+	"formato.separador_decimal": ",",
+
+	"mes.1": "enero", "mes.2": "febrero", "mes.3": "marzo", "mes.4": "abril",
+	"mes.5": "mayo", "mes.6": "junio", "mes.7": "julio", "mes.8": "agosto",
+	"mes.9": "septiembre", "mes.10": "octubre", "mes.11": "noviembre", "mes.12": "diciembre",
+
+	"dia.0": "domingo", "dia.1": "lunes", "dia.2": "martes", "dia.3": "miércoles",
+	"dia.4": "jueves", "dia.5": "viernes", "dia.6": "sábado",
+
+	"categoria.estudiante": "estudiante",
+	"categoria.docente":    "docente",
+	"categoria.publico":    "publico",
+
+	"reserva.estado.pendiente": "pendiente",
+	"reserva.estado.lista":     "lista",
+	"reserva.estado.cumplida":  "cumplida",
+	"reserva.estado.cancelada": "cancelada",
+	"reserva.estado.vencida":   "vencida",
+
+	// libros y usuarios
+	"libro.info":                 "[%d] %s por %s - %s",
+	"libro.sin_ejemplares":       "Sin ejemplares",
+	"libro.disponibilidad.uno":   "%d de %d disponible",
+	"libro.disponibilidad.otros": "%d de %d disponibles",
+	"usuario.activo":             "Activo",
+	"usuario.inactivo":           "Inactivo",
+	"usuario.suspendido":         "Suspendido",
+	"usuario.resumen":            "%s (%s) - %s",
+	"usuario.resumen_categoria":  "%s (%s) [%s] - %s",
+
+	"disponibles.titulo":  "📚 Libros disponibles:",
+	"disponibles.extenso": "     📖 Libro extenso (%d páginas)",
+	"disponibles.ninguno": " No hay libros disponibles",
+
+	// comprobantes de préstamo de la CLI
+	"prestamo.creado":         "✅ Préstamo %d creado, devolver antes del %s",
+	"prestamo.devuelto":       "✅ Libro devuelto (préstamo %d)",
+	"prestamo.multa":          "💰 Multa por atraso: %s",
+	"prestamo.multa_pagada":   "✅ Multa del préstamo %d pagada (%s)",
+	"prestamo.renovado.uno":   "✅ Préstamo %d renovado hasta el %s (%d renovación)",
+	"prestamo.renovado.otros": "✅ Préstamo %d renovado hasta el %s (%d renovaciones)",
+
+	// salida de la CLI
+	"cli.comando_desconocido":          "Comando desconocido '%s'",
+	"cli.argumentos_inesperados":       "Argumentos inesperados: %s",
+	"cli.falta_opcion":                 "Debe proporcionar %s",
+	"biblioteca.creada":                "✅ Biblioteca creada: %s",
+	"libro.agregado":                   "✅ Agregado libro: %s",
+	"libro.actualizado":                "✅ Actualizado libro: %s",
+	"libro.ninguno":                    " No hay libros",
+	"libro.sin_resultados":             " Sin resultados",
+	"libro.exportado":                  "✅ Catálogo exportado en '%s'",
+	"ejemplar.agregado":                "✅ Agregado ejemplar [%d] %s: %s",
+	"usuario.registrado":               "✅ Registrado usuario [%d]: %s",
+	"usuario.actualizado":              "✅ Actualizado usuario [%d]: %s",
+	"usuario.ninguno":                  " No hay usuarios",
+	"usuario.revisados.uno":            "✅ %d usuario con cambios en su suspensión",
+	"usuario.revisados.otros":          "✅ %d usuarios con cambios en su suspensión",
+	"importacion.fila.error":           " ❌ fila %d: %s",
+	"importacion.fila.valida":          " ✔️  fila %d: %s",
+	"importacion.fila.importada":       " ✅ fila %d: [%d] %s",
+	"importacion.registro.error":       " ❌ registro %d: %s",
+	"importacion.registro.valida":      " ✔️  registro %d: %s",
+	"importacion.registro.importada":   " ✅ registro %d: [%d] %s",
+	"importacion.columnas_ignoradas":   "Columnas ignoradas: %s",
+	"importacion.resumen.uno":          "📥 %d de %d libro importado, %d con errores",
+	"importacion.resumen.otros":        "📥 %d de %d libros importados, %d con errores",
+	"importacion.resumen_prueba.uno":   "📥 %d de %d libro válido (prueba, no se guardó nada), %d con errores",
+	"importacion.resumen_prueba.otros": "📥 %d de %d libros válidos (prueba, no se guardó nada), %d con errores",
+	"historial.vacio":                  " Sin préstamos",
+	"historial.prestamo.uno":           " [%d] libro %d, usuario %d: prestado el %s, %s, %d día",
+	"historial.prestamo.otros":         " [%d] libro %d, usuario %d: prestado el %s, %s, %d días",
+	"historial.atraso.uno":             ", %d día de atraso, multa %s",
+	"historial.atraso.otros":           ", %d días de atraso, multa %s",
+	"historial.pagina.uno":             " Página %d de %d (%d préstamo)",
+	"historial.pagina.otros":           " Página %d de %d (%d préstamos)",
+	"prestamo.estado.activo":           "activo",
+	"prestamo.estado.devuelto":         "devuelto",
+	"prestamo.estado.devuelto_el":      "devuelto el %s",
+	"prestamo.ninguno":                 " No hay préstamos",
+	"prestamo.ninguno_vencido":         " No hay préstamos vencidos",
+	"prestamo.linea":                   " [%d] libro %d, usuario %d: %s, vence %s",
+	"prestamo.vencido.uno":             " [%d] libro %d, usuario %d: %d día de atraso, multa %s",
+	"prestamo.vencido.otros":           " [%d] libro %d, usuario %d: %d días de atraso, multa %s",
+	"reserva.creada":                   "✅ Reserva %d creada, posición %d en la cola",
+	"reserva.cancelada":                "✅ Reserva %d cancelada",
+	"reserva.ninguna":                  " No hay reservas",
+	"reserva.linea":                    " %d. [%d] usuario %d - %s",
+	"reserva.retirar_antes":            " (retirar antes del %s)",
+	"reserva.vencidas.uno":             "✅ %d reserva vencida",
+	"reserva.vencidas.otros":           "✅ %d reservas vencidas",
+	"calendario.cerrado":               "cerrado",
+	"calendario.feriado":               "feriado",
+	"calendario.actualizado.uno":       "✅ Calendario actualizado (%d feriado)",
+	"calendario.actualizado.otros":     "✅ Calendario actualizado (%d feriados)",
+	"verificar.consistente":            "✅ La biblioteca es consistente",
+	"verificar.resumen.uno":            "%d inconsistencia, reparadas: %d",
+	"verificar.resumen.otros":          "%d inconsistencias, reparadas: %d",
+	"servir.iniciado":                  "🏛 Sirviendo %s en %s",
+
+	// verificación de consistencia
+	"consistencia.prestamo_huerfano":          "El préstamo %d apunta a %s",
+	"consistencia.prestamo_huerfano_devuelto": "El préstamo %d apunta a %s; ya fue devuelto y se conserva en el historial",
+	"consistencia.libro_inexistente":          "un libro inexistente (%d)",
+	"consistencia.usuario_inexistente":        "un usuario inexistente (%d)",
+	"consistencia.ejemplar_inexistente":       "un ejemplar inexistente (%d)",
+	"consistencia.ejemplar_ajeno":             "el ejemplar %d, que es del libro %d",
+	"consistencia.prestamo_duplicado":         "El ejemplar %d tiene activos los préstamos %d y %d",
+	"consistencia.reserva_huerfana":           "La reserva %d apunta al libro %d y al usuario %d, y alguno no existe",
+	"consistencia.reserva_sin_apartado":       "La reserva %d está lista pero el ejemplar %d no está apartado para el usuario %d",
+	"consistencia.ejemplar_sin_prestamo":      "El ejemplar '%s' de '%s' figura prestado sin un préstamo activo",
+	"consistencia.prestamo_sin_marca":         "El ejemplar '%s' de '%s' tiene el préstamo activo %d pero figura disponible",
+	"consistencia.apartado_sin_reserva":       "El ejemplar '%s' de '%s' está apartado para el usuario %d sin una reserva lista",
+	"consistencia.secuencias":                 "Las secuencias %s repetirían IDs existentes, deberían ser %s",
+	"consistencia.indice":                     "El índice de préstamos activos no coincide con los préstamos",
+
+	// simulación
+	"simulacion.periodo":       "📅 Período: %s al %s (%d días de atención, semilla %d)",
+	"simulacion.prestamos":     "📖 Préstamos: %d",
+	"simulacion.devoluciones":  "📕 Devoluciones: %d (%d con atraso)",
+	"simulacion.renovaciones":  "🔁 Renovaciones: %d",
+	"simulacion.reservas":      "📌 Reservas: %d (%d vencidas sin retirar)",
+	"simulacion.rechazos":      "🚫 Pedidos no atendidos: %d",
+	"simulacion.multas":        "💰 Multas cobradas: %s",
+	"simulacion.cierre":        "📋 Al cierre: %d préstamos activos, %d vencidos",
+	"simulacion.mas_prestados": "🏆 Más prestados:",
+
+	// configuración de la CLI
+	"politica.actualizada":         "✅ Política de la categoría '%s' actualizada",
+	"politica.actualizada_general": "✅ Política de los usuarios sin categoría actualizada",
+
+	// estadísticas
+	"estadisticas.titulo":          "📊 Estadísticas de %s al %s",
+	"estadisticas.libros":          "   📚 Total de libros: %d",
+	"estadisticas.ejemplares":      "   📦 Total de ejemplares: %d",
+	"estadisticas.prestados":       "   📖 Ejemplares prestados: %d",
+	"estadisticas.disponibles":     "   📕 Ejemplares disponibles: %d",
+	"estadisticas.usuarios":        "   👥 Usuarios activos: %d de %d",
+	"estadisticas.lectores":        "   🙋 Lectores (últimos %d días): %d (%s%% de los activos)",
+	"estadisticas.prestamos.uno":   "   📋 Préstamos activos: %d (%d vencido)",
+	"estadisticas.prestamos.otros": "   📋 Préstamos activos: %d (%d vencidos)",
+	"estadisticas.atraso.uno":      "   ⏰ Tasa de atraso: %s%% de %d préstamo",
+	"estadisticas.atraso.otros":    "   ⏰ Tasa de atraso: %s%% de %d préstamos",
+	"estadisticas.duracion":        "   ⏳ Duración promedio: %s días",
+	"estadisticas.mas_prestados":   "   🏆 Títulos más prestados:",
+	"estadisticas.autores":         "   ✍️  Autores más prestados:",
+	"estadisticas.circulacion":     "   📅 Circulación mensual (préstamos / devoluciones):",
+
+	// errores de búsqueda
+	"error.libro_no_encontrado":    "No existe un libro con ID '%d'",
+	"error.usuario_no_encontrado":  "No existe un usuario con ID '%d'",
+	"error.ejemplar_no_encontrado": "No existe un ejemplar con ID '%d'",
+	"error.prestamo_no_encontrado": "No existe un préstamo con ID '%d'",
+	"error.reserva_no_encontrada":  "No existe una reserva con ID '%d'",
+	"error.ejemplar_ajeno":         "El libro '%s' no tiene el ejemplar '%d'",
+	"error.libro_sin_prestamo":     "No existe un prestamo activo para el libro '%s'",
+	"error.ejemplar_sin_prestamo":  "No existe un prestamo activo para el ejemplar '%s'",
+
+	// errores de préstamos, renovaciones y reservas
+	"error.libro_invalido":            "El libro '%s' no es valido",
+	"error.libro_no_disponible":       "El libro '%s' no se puede prestar: %s",
+	"error.ejemplar_prestado":         "El ejemplar '%s' de '%s' ya está prestado",
+	"error.ejemplar_no_prestado":      "El ejemplar '%s' de '%s' no está prestado",
+	"error.ejemplar_reservado":        "El ejemplar '%s' de '%s' está reservado para otro usuario",
+	"error.usuario_inactivo":          "El usuario '%s' no puede prestar",
+	"error.usuario_suspendido_multas": "El usuario '%s' está suspendido por multas impagas (debe %s)",
+	"error.usuario_suspendido_atraso": "El usuario '%s' está suspendido: el préstamo %d tiene %d días de atraso",
+	"error.multa_no_pendiente":        "El préstamo '%d' no tiene una multa pendiente de pago",
+	"error.limite_prestamos.uno":      "El usuario '%s' ya tiene %d préstamo activo, el máximo de la categoría '%s' es %d",
+	"error.limite_prestamos.otros":    "El usuario '%s' ya tiene %d préstamos activos, el máximo de la categoría '%s' es %d",
+	"error.varios_prestados":          "El libro '%s' tiene %d ejemplares prestados, indique cuál se devuelve",
+	"error.prestamo_devuelto":         "El préstamo '%d' ya fue devuelto",
+	"error.prestamo_vencido":          "El préstamo '%d' está vencido y no se puede renovar",
+	"error.max_renovaciones.uno":      "El préstamo '%d' alcanzó el máximo de %d renovación",
+	"error.max_renovaciones.otros":    "El préstamo '%d' alcanzó el máximo de %d renovaciones",
+	"error.reservado_por_otros":       "Otros usuarios tienen reservado el libro con ID '%d'",
+	"error.reserva_innecesaria":       "El libro '%s' tiene %s, no necesita reserva",
+	"error.ya_prestado_al_usuario":    "El usuario '%s' ya tiene prestado '%s'",
+	"error.reserva_duplicada":         "El usuario '%s' ya reservó '%s'",
+	"error.reserva_no_activa":         "La reserva '%d' ya está %s",
+	"error.prestamo_no_registrado":    "El préstamo no se realizó porque no se pudo registrar en el journal",
+	"error.devolucion_no_registrada":  "La devolución no se realizó porque no se pudo registrar en el journal",
+	"error.libro_no_registrado":       "El libro no se agregó porque no se pudo registrar en el journal",
+	"error.ejemplar_no_registrado":    "El ejemplar no se agregó porque no se pudo registrar en el journal",
+	"error.usuario_no_registrado":     "El usuario no se registró porque no se pudo registrar en el journal",
+	"error.cambio_no_registrado":      "El cambio no se aplicó porque no se pudo registrar en el journal",
+	"error.renovacion_no_registrada":  "La renovación no se realizó porque no se pudo registrar en el journal",
+	"error.reserva_no_registrada":     "La reserva no se realizó porque no se pudo registrar en el journal",
+	"error.cancelacion_no_registrada": "La reserva %d no se canceló porque no se pudo registrar en el journal",
+	"error.vencimiento_no_registrado": "La reserva %d no se marcó como vencida porque no se pudo registrar en el journal",
+	"error.apartado_no_registrado":    "No se pudo apartar el ejemplar '%s' para la reserva %d",
+	"error.journal_no_registrado":     "La operación se aplicó pero no quedó en el journal",
+	"error.suspension_no_registrada":  "No se pudo registrar en el journal la suspensión del usuario '%s'",
+	"error.pago_no_registrado":        "El pago no se realizó porque no se pudo registrar en el journal",
+	"error.calendario_no_registrado":  "El calendario no se cambió porque no se pudo registrar en el journal",
+	"error.politica_no_registrada":    "La política no se cambió porque no se pudo registrar en el journal",
+	"error.journal_atrasado":          "El journal '%s' termina en el evento %d pero los datos ya incluyen el %d",
+	"error.journal_incompleto":        "El journal '%s' empieza en el evento %d pero los datos solo incluyen hasta el %d",
+	"error.datos_en_uso":              "Otro proceso está modificando '%s', intente de nuevo más tarde",
+
+	// errores de datos
+	"error.faltan_titulo_autor":   "Debe proporcionar titulo y autor",
+	"error.faltan_paginas":        "Debe proporcionar cantidad de paginas",
+	"error.faltan_nombre_email":   "Debe proporcionar nombre y email",
+	"error.email_invalido":        "Email no válido '%s'",
+	"error.email_duplicado":       "Ya existe un usuario con el email '%s'",
+	"error.isbn_duplicado":        "Ya existe un libro con el ISBN '%s', agregue un ejemplar",
+	"error.codigo_duplicado":      "Ya existe un ejemplar con el código '%s'",
+	"error.categoria_desconocida": "Categoría desconocida '%s' (categorías válidas: %s)",
+	"error.idioma_desconocido":    "Idioma desconocido '%s' (idiomas disponibles: %s)",
+	"error.isbn_invalido":         "ISBN no válido '%s': %s",
+	"error.no_es_isbn10":          "'%s' no es un ISBN-10",
+	"error.no_es_isbn13":          "'%s' no es un ISBN-13",
+	"error.sin_isbn10":            "El ISBN '%s' no tiene equivalente ISBN-10",
+	"isbn.caracter":               "carácter '%c' inesperado",
+	"isbn.control":                "dígito de control incorrecto",
+	"isbn.prefijo":                "debe comenzar con 978 o 979",
+	"isbn.largo":                  "debe tener 10 o 13 dígitos",
+	"error.horario_formato":       "Horario no válido para %s: use HH:MM",
+	"error.horario_rango":         "Horario no válido para %s: abre a las %s y cierra a las %s",
+	"error.calendario_sin_dias":   "El calendario debe tener al menos un día con horario",
+	"error.feriado_invalido":      "Feriado no válido '%s', use AAAA-MM-DD",
+	"error.politica_sin_plazo":    "La política debe tener DiasPrestamo mayor que cero",
+	"error.politica_negativa":     "La política no puede tener valores negativos",
+	"error.pagina_negativa":       "La página y el tamaño de página no pueden ser negativos",
+	"error.fechas_invertidas":     "La fecha hasta no puede ser anterior a la fecha desde",
+	"error.fecha_desde":           "Fecha desde no válida '%s', use AAAA-MM-DD",
+	"error.fecha_hasta":           "Fecha hasta no válida '%s', use AAAA-MM-DD",
+	"error.simulacion_sin_datos":  "La simulación necesita días, libros y usuarios mayores que cero",
+	"error.cuerpo_json":           "Cuerpo JSON inválido",
+	"error.id_invalido":           "ID inválido '%s'",
+	"error.valor_invalido":        "Valor inválido para %s: '%s'",
+
+	// errores de importación y exportación
+	"error.archivo_vacio":          "El archivo está vacío",
+	"error.encabezado_ilegible":    "No se pudo leer el encabezado",
+	"error.paginas_invalidas":      "Cantidad de páginas inválida '%s'",
+	"error.ejemplares_invalidos":   "Cantidad de ejemplares inválida '%s'",
+	"error.campo_desconocido":      "Campo desconocido '%s' para la columna '%s' (campos válidos: %s)",
+	"error.columnas_repetidas":     "Las columnas '%s' y '%s' corresponden al mismo campo '%s'",
+	"error.falta_columna":          "El encabezado no tiene una columna para '%s'",
+	"error.separador_desconocido":  "Separador desconocido '%s', use coma o tab",
+	"error.columna_invalida":       "Columna inválida '%s', use Encabezado=campo",
+	"error.formato_desconocido":    "Formato desconocido '%s' (formatos válidos: marc, marcxml, dc)",
+	"error.marc_registro":          "Registro MARC %d",
+	"error.marc_etiqueta":          "Etiqueta inválida '%s'",
+	"error.marc_campo_grande":      "El campo %s excede el tamaño máximo de ISO 2709",
+	"error.marc_registro_grande":   "El registro excede el tamaño máximo de ISO 2709",
+	"error.marc_sin_fin":           "Registro MARC %d inválido: falta el fin de registro",
+	"error.marc_registro_invalido": "Registro MARC %d inválido",
+	"error.marc_corto":             "el registro es demasiado corto",
+	"error.marc_base":              "dirección base '%s' fuera de rango",
+	"error.marc_directorio":        "el directorio tiene un largo inválido",
+	"error.marc_entrada":           "entrada de directorio inválida '%s'",
+	"error.marc_sin_indicadores":   "el campo %s no tiene indicadores",
+	"error.marcxml_invalido":       "MARCXML inválido",
+	"error.dc_invalido":            "Dublin Core inválido",
+	"error.leer_registros":         "No se pudieron leer los registros",
+	"error.escribir_registros":     "No se pudieron escribir los registros",
+	"error.opciones_solo_csv":      "--separador y --columna solo se usan con --formato csv",
+	"error.csv_columna":            "Formato inválido en la columna %d",
+
+	// errores de archivos y del journal
+	"error.archivo_leer":           "No se pudo leer '%s'",
+	"error.archivo_escribir":       "No se pudo escribir '%s'",
+	"error.archivo_temporal":       "No se pudo crear un archivo temporal en '%s'",
+	"error.archivo_reemplazar":     "No se pudo reemplazar '%s'",
+	"error.archivo_crear":          "No se pudo crear '%s'",
+	"error.archivo_existente":      "El archivo '%s' ya existe",
+	"error.serializar_biblioteca":  "No se pudo serializar la biblioteca",
+	"error.snapshot_invalido":      "El archivo '%s' no es un snapshot válido",
+	"error.snapshot_migrar":        "No se pudo migrar desde la versión %d",
+	"error.snapshot_version":       "Versión de snapshot no soportada: %d",
+	"error.calendario_invalido":    "El archivo '%s' no es un calendario válido",
+	"error.journal_abrir":          "No se pudo abrir el journal '%s'",
+	"error.journal_reparar":        "No se pudo reparar el journal '%s'",
+	"error.journal_leer":           "No se pudo leer el journal '%s'",
+	"error.journal_escribir":       "No se pudo escribir en el journal '%s'",
+	"error.journal_inconsistente":  "El journal '%s' quedó inconsistente (%s) y no se pudo recortar",
+	"error.journal_linea_invalida": "Línea %d del journal '%s' no es válida",
+	"error.journal_recortar":       "No se pudo recortar el journal '%s'",
+	"error.evento_serializar":      "No se pudo serializar el evento '%s'",
+	"error.evento_invalido":        "Evento %d del journal '%s' inválido",
+	"error.evento_incompleto":      "El evento '%s' no trae todos sus datos",
+	"error.evento_desconocido":     "Tipo de evento desconocido '%s'",
+	"error.evento_sin_ejemplar":    "El evento '%s' es anterior a los ejemplares y el libro %d no tiene un único ejemplar al que enlazarlo",
+
+	// errores de consultas estructuradas
+	"consulta.error":                  "Error en la consulta (posición %d): %s",
+	"consulta.no_se_esperaba":         "no se esperaba '%s'",
+	"consulta.fin":                    "fin de la consulta",
+	"consulta.comillas_sin_cerrar":    "comillas sin cerrar",
+	"consulta.se_esperaba_distinto":   "se esperaba '!='",
+	"consulta.falta_parentesis":       "se esperaba ')' para cerrar el '(' de la posición %d",
+	"consulta.falta_valor":            "se esperaba un valor después de '%s%s'",
+	"consulta.falta_campo":            "falta el campo antes de '%s'",
+	"consulta.campo_desconocido":      "campo desconocido '%s' (campos válidos: %s)",
+	"consulta.expresion_no_soportada": "expresión no soportada",
+	"consulta.valor_invalido":         "el campo '%s' espera %s, no '%s'",
+	"consulta.tipo_numero":            "un número",
+	"consulta.tipo_booleano":          "true o false",
+	"consulta.tipo_fecha":             "una fecha AAAA-MM-DD",
+	"consulta.operador_invalido":      "el operador '%s' no se puede usar con el campo '%s'",
+}
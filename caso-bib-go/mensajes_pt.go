@@ -0,0 +1,312 @@
+package main
+
+// mensajesPT es el catálogo en portugués (Brasil)
+var mensajesPT = map[ClaveMensaje]string{
+	// formatos de fecha y número
+	"formato.fecha":             "02/01/2006",
+	"formato.fecha_larga":       "%d de %s de %d",
+	"formato.mes":               "%s de %d",
+	"formato.separador_decimal": ",",
+
+	"mes.1": "janeiro", "mes.2": "fevereiro", "mes.3": "março", "mes.4": "abril",
+	"mes.5": "maio", "mes.6": "junho", "mes.7": "julho", "mes.8": "agosto",
+	"mes.9": "setembro", "mes.10": "outubro", "mes.11": "novembro", "mes.12": "dezembro",
+
+	"dia.0": "domingo", "dia.1": "segunda-feira", "dia.2": "terça-feira", "dia.3": "quarta-feira",
+	"dia.4": "quinta-feira", "dia.5": "sexta-feira", "dia.6": "sábado",
+
+	"categoria.estudiante": "estudante",
+	"categoria.docente":    "docente",
+	"categoria.publico":    "público",
+
+	"reserva.estado.pendiente": "pendente",
+	"reserva.estado.lista":     "pronta",
+	"reserva.estado.cumplida":  "atendida",
+	"reserva.estado.cancelada": "cancelada",
+	"reserva.estado.vencida":   "expirada",
+
+	// libros y usuarios
+	"libro.info":                 "[%d] %s de %s - %s",
+	"libro.sin_ejemplares":       "Sem exemplares",
+	"libro.disponibilidad.uno":   "%d de %d disponível",
+	"libro.disponibilidad.otros": "%d de %d disponíveis",
+	"usuario.activo":             "Ativo",
+	"usuario.inactivo":           "Inativo",
+	"usuario.suspendido":         "Suspenso",
+	"usuario.resumen":            "%s (%s) - %s",
+	"usuario.resumen_categoria":  "%s (%s) [%s] - %s",
+
+	"disponibles.titulo":  "📚 Livros disponíveis:",
+	"disponibles.extenso": "     📖 Livro extenso (%d páginas)",
+	"disponibles.ninguno": " Não há livros disponíveis",
+
+	// comprobantes de préstamo de la CLI
+	"prestamo.creado":         "✅ Empréstimo %d criado, devolver até %s",
+	"prestamo.devuelto":       "✅ Livro devolvido (empréstimo %d)",
+	"prestamo.multa":          "💰 Multa por atraso: %s",
+	"prestamo.multa_pagada":   "✅ Multa do empréstimo %d paga (%s)",
+	"prestamo.renovado.uno":   "✅ Empréstimo %d renovado até %s (%d renovação)",
+	"prestamo.renovado.otros": "✅ Empréstimo %d renovado até %s (%d renovações)",
+
+	// salida de la CLI
+	"cli.comando_desconocido":          "Comando desconhecido '%s'",
+	"cli.argumentos_inesperados":       "Argumentos inesperados: %s",
+	"cli.falta_opcion":                 "Informe %s",
+	"biblioteca.creada":                "✅ Biblioteca criada: %s",
+	"libro.agregado":                   "✅ Livro adicionado: %s",
+	"libro.actualizado":                "✅ Livro atualizado: %s",
+	"libro.ninguno":                    " Não há livros",
+	"libro.sin_resultados":             " Nenhum resultado",
+	"libro.exportado":                  "✅ Catálogo exportado em '%s'",
+	"ejemplar.agregado":                "✅ Exemplar adicionado [%d] %s: %s",
+	"usuario.registrado":               "✅ Usuário cadastrado [%d]: %s",
+	"usuario.actualizado":              "✅ Usuário atualizado [%d]: %s",
+	"usuario.ninguno":                  " Não há usuários",
+	"usuario.revisados.uno":            "✅ %d usuário com mudanças na suspensão",
+	"usuario.revisados.otros":          "✅ %d usuários com mudanças na suspensão",
+	"importacion.fila.error":           " ❌ linha %d: %s",
+	"importacion.fila.valida":          " ✔️  linha %d: %s",
+	"importacion.fila.importada":       " ✅ linha %d: [%d] %s",
+	"importacion.registro.error":       " ❌ registro %d: %s",
+	"importacion.registro.valida":      " ✔️  registro %d: %s",
+	"importacion.registro.importada":   " ✅ registro %d: [%d] %s",
+	"importacion.columnas_ignoradas":   "Colunas ignoradas: %s",
+	"importacion.resumen.uno":          "📥 %d de %d livro importado, %d com erros",
+	"importacion.resumen.otros":        "📥 %d de %d livros importados, %d com erros",
+	"importacion.resumen_prueba.uno":   "📥 %d de %d livro válido (teste, nada foi salvo), %d com erros",
+	"importacion.resumen_prueba.otros": "📥 %d de %d livros válidos (teste, nada foi salvo), %d com erros",
+	"historial.vacio":                  " Nenhum empréstimo",
+	"historial.prestamo.uno":           " [%d] livro %d, usuário %d: emprestado em %s, %s, %d dia",
+	"historial.prestamo.otros":         " [%d] livro %d, usuário %d: emprestado em %s, %s, %d dias",
+	"historial.atraso.uno":             ", %d dia de atraso, multa %s",
+	"historial.atraso.otros":           ", %d dias de atraso, multa %s",
+	"historial.pagina.uno":             " Página %d de %d (%d empréstimo)",
+	"historial.pagina.otros":           " Página %d de %d (%d empréstimos)",
+	"prestamo.estado.activo":           "ativo",
+	"prestamo.estado.devuelto":         "devolvido",
+	"prestamo.estado.devuelto_el":      "devolvido em %s",
+	"prestamo.ninguno":                 " Não há empréstimos",
+	"prestamo.ninguno_vencido":         " Não há empréstimos atrasados",
+	"prestamo.linea":                   " [%d] livro %d, usuário %d: %s, vence em %s",
+	"prestamo.vencido.uno":             " [%d] livro %d, usuário %d: %d dia de atraso, multa %s",
+	"prestamo.vencido.otros":           " [%d] livro %d, usuário %d: %d dias de atraso, multa %s",
+	"reserva.creada":                   "✅ Reserva %d criada, posição %d na fila",
+	"reserva.cancelada":                "✅ Reserva %d cancelada",
+	"reserva.ninguna":                  " Não há reservas",
+	"reserva.linea":                    " %d. [%d] usuário %d - %s",
+	"reserva.retirar_antes":            " (retirar até %s)",
+	"reserva.vencidas.uno":             "✅ %d reserva expirada",
+	"reserva.vencidas.otros":           "✅ %d reservas expiradas",
+	"calendario.cerrado":               "fechado",
+	"calendario.feriado":               "feriado",
+	"calendario.actualizado.uno":       "✅ Calendário atualizado (%d feriado)",
+	"calendario.actualizado.otros":     "✅ Calendário atualizado (%d feriados)",
+	"verificar.consistente":            "✅ A biblioteca está consistente",
+	"verificar.resumen.uno":            "%d inconsistência, reparadas: %d",
+	"verificar.resumen.otros":          "%d inconsistências, reparadas: %d",
+	"servir.iniciado":                  "🏛 Servindo %s em %s",
+
+	// verificación de consistencia
+	"consistencia.prestamo_huerfano":          "O empréstimo %d aponta para %s",
+	"consistencia.prestamo_huerfano_devuelto": "O empréstimo %d aponta para %s; já foi devolvido e é mantido no histórico",
+	"consistencia.libro_inexistente":          "um livro inexistente (%d)",
+	"consistencia.usuario_inexistente":        "um usuário inexistente (%d)",
+	"consistencia.ejemplar_inexistente":       "um exemplar inexistente (%d)",
+	"consistencia.ejemplar_ajeno":             "o exemplar %d, que é do livro %d",
+	"consistencia.prestamo_duplicado":         "O exemplar %d tem ativos os empréstimos %d e %d",
+	"consistencia.reserva_huerfana":           "A reserva %d aponta para o livro %d e o usuário %d, e algum não existe",
+	"consistencia.reserva_sin_apartado":       "A reserva %d está pronta mas o exemplar %d não está separado para o usuário %d",
+	"consistencia.ejemplar_sin_prestamo":      "O exemplar '%s' de '%s' consta como emprestado sem um empréstimo ativo",
+	"consistencia.prestamo_sin_marca":         "O exemplar '%s' de '%s' tem o empréstimo ativo %d mas consta como disponível",
+	"consistencia.apartado_sin_reserva":       "O exemplar '%s' de '%s' está separado para o usuário %d sem uma reserva pronta",
+	"consistencia.secuencias":                 "As sequências %s repetiriam IDs existentes, deveriam ser %s",
+	"consistencia.indice":                     "O índice de empréstimos ativos não coincide com os empréstimos",
+
+	// simulación
+	"simulacion.periodo":       "📅 Período: %s a %s (%d dias de atendimento, semente %d)",
+	"simulacion.prestamos":     "📖 Empréstimos: %d",
+	"simulacion.devoluciones":  "📕 Devoluções: %d (%d com atraso)",
+	"simulacion.renovaciones":  "🔁 Renovações: %d",
+	"simulacion.reservas":      "📌 Reservas: %d (%d expiradas sem retirada)",
+	"simulacion.rechazos":      "🚫 Pedidos não atendidos: %d",
+	"simulacion.multas":        "💰 Multas cobradas: %s",
+	"simulacion.cierre":        "📋 No fechamento: %d empréstimos ativos, %d atrasados",
+	"simulacion.mas_prestados": "🏆 Mais emprestados:",
+
+	// configuración de la CLI
+	"politica.actualizada":         "✅ Política da categoria '%s' atualizada",
+	"politica.actualizada_general": "✅ Política dos usuários sem categoria atualizada",
+
+	// estadísticas
+	"estadisticas.titulo":          "📊 Estatísticas de %s em %s",
+	"estadisticas.libros":          "   📚 Total de livros: %d",
+	"estadisticas.ejemplares":      "   📦 Total de exemplares: %d",
+	"estadisticas.prestados":       "   📖 Exemplares emprestados: %d",
+	"estadisticas.disponibles":     "   📕 Exemplares disponíveis: %d",
+	"estadisticas.usuarios":        "   👥 Usuários ativos: %d de %d",
+	"estadisticas.lectores":        "   🙋 Leitores (últimos %d dias): %d (%s%% dos ativos)",
+	"estadisticas.prestamos.uno":   "   📋 Empréstimos ativos: %d (%d atrasado)",
+	"estadisticas.prestamos.otros": "   📋 Empréstimos ativos: %d (%d atrasados)",
+	"estadisticas.atraso.uno":      "   ⏰ Taxa de atraso: %s%% de %d empréstimo",
+	"estadisticas.atraso.otros":    "   ⏰ Taxa de atraso: %s%% de %d empréstimos",
+	"estadisticas.duracion":        "   ⏳ Duração média: %s dias",
+	"estadisticas.mas_prestados":   "   🏆 Títulos mais emprestados:",
+	"estadisticas.autores":         "   ✍️  Autores mais emprestados:",
+	"estadisticas.circulacion":     "   📅 Circulação mensal (empréstimos / devoluções):",
+
+	// errores de búsqueda
+	"error.libro_no_encontrado":    "Não existe um livro com ID '%d'",
+	"error.usuario_no_encontrado":  "Não existe um usuário com ID '%d'",
+	"error.ejemplar_no_encontrado": "Não existe um exemplar com ID '%d'",
+	"error.prestamo_no_encontrado": "Não existe um empréstimo com ID '%d'",
+	"error.reserva_no_encontrada":  "Não existe uma reserva com ID '%d'",
+	"error.ejemplar_ajeno":         "O livro '%s' não tem o exemplar '%d'",
+	"error.libro_sin_prestamo":     "Não existe um empréstimo ativo para o livro '%s'",
+	"error.ejemplar_sin_prestamo":  "Não existe um empréstimo ativo para o exemplar '%s'",
+
+	// errores de préstamos, renovaciones y reservas
+	"error.libro_invalido":            "O livro '%s' não é válido",
+	"error.libro_no_disponible":       "O livro '%s' não pode ser emprestado: %s",
+	"error.ejemplar_prestado":         "O exemplar '%s' de '%s' já está emprestado",
+	"error.ejemplar_no_prestado":      "O exemplar '%s' de '%s' não está emprestado",
+	"error.ejemplar_reservado":        "O exemplar '%s' de '%s' está reservado para outro usuário",
+	"error.usuario_inactivo":          "O usuário '%s' não pode pegar emprestado",
+	"error.usuario_suspendido_multas": "O usuário '%s' está suspenso por multas não pagas (deve %s)",
+	"error.usuario_suspendido_atraso": "O usuário '%s' está suspenso: o empréstimo %d tem %d dias de atraso",
+	"error.multa_no_pendiente":        "O empréstimo '%d' não tem multa pendente de pagamento",
+	"error.limite_prestamos.uno":      "O usuário '%s' já tem %d empréstimo ativo, o máximo da categoria '%s' é %d",
+	"error.limite_prestamos.otros":    "O usuário '%s' já tem %d empréstimos ativos, o máximo da categoria '%s' é %d",
+	"error.varios_prestados":          "O livro '%s' tem %d exemplares emprestados, indique qual é devolvido",
+	"error.prestamo_devuelto":         "O empréstimo '%d' já foi devolvido",
+	"error.prestamo_vencido":          "O empréstimo '%d' está atrasado e não pode ser renovado",
+	"error.max_renovaciones.uno":      "O empréstimo '%d' atingiu o máximo de %d renovação",
+	"error.max_renovaciones.otros":    "O empréstimo '%d' atingiu o máximo de %d renovações",
+	"error.reservado_por_otros":       "Outros usuários reservaram o livro com ID '%d'",
+	"error.reserva_innecesaria":       "O livro '%s' tem %s, não precisa de reserva",
+	"error.ya_prestado_al_usuario":    "O usuário '%s' já está com '%s' emprestado",
+	"error.reserva_duplicada":         "O usuário '%s' já reservou '%s'",
+	"error.reserva_no_activa":         "A reserva '%d' já está %s",
+	"error.prestamo_no_registrado":    "O empréstimo não foi feito porque não pôde ser registrado no journal",
+	"error.devolucion_no_registrada":  "A devolução não foi feita porque não pôde ser registrada no journal",
+	"error.libro_no_registrado":       "O livro não foi adicionado porque não pôde ser registrado no journal",
+	"error.ejemplar_no_registrado":    "O exemplar não foi adicionado porque não pôde ser registrado no journal",
+	"error.usuario_no_registrado":     "O usuário não foi cadastrado porque não pôde ser registrado no journal",
+	"error.cambio_no_registrado":      "A alteração não foi aplicada porque não pôde ser registrada no journal",
+	"error.renovacion_no_registrada":  "A renovação não foi feita porque não pôde ser registrada no journal",
+	"error.reserva_no_registrada":     "A reserva não foi feita porque não pôde ser registrada no journal",
+	"error.cancelacion_no_registrada": "A reserva %d não foi cancelada porque não pôde ser registrada no journal",
+	"error.vencimiento_no_registrado": "A reserva %d não foi marcada como vencida porque não pôde ser registrada no journal",
+	"error.apartado_no_registrado":    "Não foi possível separar o exemplar '%s' para a reserva %d",
+	"error.journal_no_registrado":     "A operação foi aplicada mas não ficou no journal",
+	"error.suspension_no_registrada":  "Não foi possível registrar no journal a suspensão do usuário '%s'",
+	"error.pago_no_registrado":        "O pagamento não foi feito porque não pôde ser registrado no journal",
+	"error.calendario_no_registrado":  "O calendário não foi alterado porque não pôde ser registrado no journal",
+	"error.politica_no_registrada":    "A política não foi alterada porque não pôde ser registrada no journal",
+	"error.journal_atrasado":          "O journal '%s' termina no evento %d mas os dados já incluem o %d",
+	"error.journal_incompleto":        "O journal '%s' começa no evento %d mas os dados só incluem até o %d",
+	"error.datos_en_uso":              "Outro processo está modificando '%s', tente novamente mais tarde",
+
+	// errores de datos
+	"error.faltan_titulo_autor":   "Informe título e autor",
+	"error.faltan_paginas":        "Informe a quantidade de páginas",
+	"error.faltan_nombre_email":   "Informe nome e email",
+	"error.email_invalido":        "Email inválido '%s'",
+	"error.email_duplicado":       "Já existe um usuário com o email '%s'",
+	"error.isbn_duplicado":        "Já existe um livro com o ISBN '%s', adicione um exemplar",
+	"error.codigo_duplicado":      "Já existe um exemplar com o código '%s'",
+	"error.categoria_desconocida": "Categoria desconhecida '%s' (categorias válidas: %s)",
+	"error.idioma_desconocido":    "Idioma desconhecido '%s' (idiomas disponíveis: %s)",
+	"error.isbn_invalido":         "ISBN inválido '%s': %s",
+	"error.no_es_isbn10":          "'%s' não é um ISBN-10",
+	"error.no_es_isbn13":          "'%s' não é um ISBN-13",
+	"error.sin_isbn10":            "O ISBN '%s' não tem equivalente ISBN-10",
+	"isbn.caracter":               "caractere '%c' inesperado",
+	"isbn.control":                "dígito verificador incorreto",
+	"isbn.prefijo":                "deve começar com 978 ou 979",
+	"isbn.largo":                  "deve ter 10 ou 13 dígitos",
+	"error.horario_formato":       "Horário inválido para %s: use HH:MM",
+	"error.horario_rango":         "Horário inválido para %s: abre às %s e fecha às %s",
+	"error.calendario_sin_dias":   "O calendário deve ter pelo menos um dia com horário",
+	"error.feriado_invalido":      "Feriado inválido '%s', use AAAA-MM-DD",
+	"error.politica_sin_plazo":    "A política deve ter DiasPrestamo maior que zero",
+	"error.politica_negativa":     "A política não pode ter valores negativos",
+	"error.pagina_negativa":       "A página e o tamanho da página não podem ser negativos",
+	"error.fechas_invertidas":     "A data final não pode ser anterior à data inicial",
+	"error.fecha_desde":           "Data inicial inválida '%s', use AAAA-MM-DD",
+	"error.fecha_hasta":           "Data final inválida '%s', use AAAA-MM-DD",
+	"error.simulacion_sin_datos":  "A simulação precisa de dias, livros e usuários maiores que zero",
+	"error.cuerpo_json":           "Corpo JSON inválido",
+	"error.id_invalido":           "ID inválido '%s'",
+	"error.valor_invalido":        "Valor inválido para %s: '%s'",
+
+	// errores de importación y exportación
+	"error.archivo_vacio":          "O arquivo está vazio",
+	"error.encabezado_ilegible":    "Não foi possível ler o cabeçalho",
+	"error.paginas_invalidas":      "Quantidade de páginas inválida '%s'",
+	"error.ejemplares_invalidos":   "Quantidade de exemplares inválida '%s'",
+	"error.campo_desconocido":      "Campo desconhecido '%s' para a coluna '%s' (campos válidos: %s)",
+	"error.columnas_repetidas":     "As colunas '%s' e '%s' correspondem ao mesmo campo '%s'",
+	"error.falta_columna":          "O cabeçalho não tem uma coluna para '%s'",
+	"error.separador_desconocido":  "Separador desconhecido '%s', use vírgula ou tab",
+	"error.columna_invalida":       "Coluna inválida '%s', use Cabeçalho=campo",
+	"error.formato_desconocido":    "Formato desconhecido '%s' (formatos válidos: marc, marcxml, dc)",
+	"error.marc_registro":          "Registro MARC %d",
+	"error.marc_etiqueta":          "Etiqueta inválida '%s'",
+	"error.marc_campo_grande":      "O campo %s excede o tamanho máximo de ISO 2709",
+	"error.marc_registro_grande":   "O registro excede o tamanho máximo de ISO 2709",
+	"error.marc_sin_fin":           "Registro MARC %d inválido: falta o fim de registro",
+	"error.marc_registro_invalido": "Registro MARC %d inválido",
+	"error.marc_corto":             "o registro é curto demais",
+	"error.marc_base":              "endereço base '%s' fora do intervalo",
+	"error.marc_directorio":        "o diretório tem um comprimento inválido",
+	"error.marc_entrada":           "entrada de diretório inválida '%s'",
+	"error.marc_sin_indicadores":   "o campo %s não tem indicadores",
+	"error.marcxml_invalido":       "MARCXML inválido",
+	"error.dc_invalido":            "Dublin Core inválido",
+	"error.leer_registros":         "Não foi possível ler os registros",
+	"error.escribir_registros":     "Não foi possível escrever os registros",
+	"error.opciones_solo_csv":      "--separador e --columna só são usados com --formato csv",
+	"error.csv_columna":            "Formato inválido na coluna %d",
+
+	// errores de archivos y del journal
+	"error.archivo_leer":           "Não foi possível ler '%s'",
+	"error.archivo_escribir":       "Não foi possível escrever '%s'",
+	"error.archivo_temporal":       "Não foi possível criar um arquivo temporário em '%s'",
+	"error.archivo_reemplazar":     "Não foi possível substituir '%s'",
+	"error.archivo_crear":          "Não foi possível criar '%s'",
+	"error.archivo_existente":      "O arquivo '%s' já existe",
+	"error.serializar_biblioteca":  "Não foi possível serializar a biblioteca",
+	"error.snapshot_invalido":      "O arquivo '%s' não é um snapshot válido",
+	"error.snapshot_migrar":        "Não foi possível migrar da versão %d",
+	"error.snapshot_version":       "Versão de snapshot não suportada: %d",
+	"error.calendario_invalido":    "O arquivo '%s' não é um calendário válido",
+	"error.journal_abrir":          "Não foi possível abrir o journal '%s'",
+	"error.journal_reparar":        "Não foi possível reparar o journal '%s'",
+	"error.journal_leer":           "Não foi possível ler o journal '%s'",
+	"error.journal_escribir":       "Não foi possível escrever no journal '%s'",
+	"error.journal_inconsistente":  "O journal '%s' ficou inconsistente (%s) e não pôde ser recortado",
+	"error.journal_linea_invalida": "A linha %d do journal '%s' não é válida",
+	"error.journal_recortar":       "Não foi possível recortar o journal '%s'",
+	"error.evento_serializar":      "Não foi possível serializar o evento '%s'",
+	"error.evento_invalido":        "Evento %d do journal '%s' inválido",
+	"error.evento_incompleto":      "O evento '%s' não traz todos os seus dados",
+	"error.evento_desconocido":     "Tipo de evento desconhecido '%s'",
+	"error.evento_sin_ejemplar":    "O evento '%s' é anterior aos exemplares e o livro %d não tem um único exemplar ao qual vinculá-lo",
+
+	// errores de consultas estructuradas
+	"consulta.error":                  "Erro na consulta (posição %d): %s",
+	"consulta.no_se_esperaba":         "não se esperava '%s'",
+	"consulta.fin":                    "fim da consulta",
+	"consulta.comillas_sin_cerrar":    "aspas sem fechar",
+	"consulta.se_esperaba_distinto":   "esperava-se '!='",
+	"consulta.falta_parentesis":       "esperava-se ')' para fechar o '(' da posição %d",
+	"consulta.falta_valor":            "esperava-se um valor depois de '%s%s'",
+	"consulta.falta_campo":            "falta o campo antes de '%s'",
+	"consulta.campo_desconocido":      "campo desconhecido '%s' (campos válidos: %s)",
+	"consulta.expresion_no_soportada": "expressão não suportada",
+	"consulta.valor_invalido":         "o campo '%s' espera %s, não '%s'",
+	"consulta.tipo_numero":            "um número",
+	"consulta.tipo_booleano":          "true ou false",
+	"consulta.tipo_fecha":             "uma data AAAA-MM-DD",
+	"consulta.operador_invalido":      "o operador '%s' não pode ser usado com o campo '%s'",
+}
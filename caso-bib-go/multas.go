@@ -0,0 +1,80 @@
+package main
+
+import "time"
+
+// ==========================================
+// VENCIMIENTOS Y MULTAS
+// ==========================================
+
+// PoliticaPrestamo agrupa las reglas configurables de los préstamos
+type PoliticaPrestamo struct {
+	DiasPrestamo      int     // duración de un préstamo
+	MaxPrestamos      int     // préstamos activos a la vez (0 = sin límite)
+	MaxRenovaciones   int     // veces que se puede renovar un préstamo
+	DiasRetiroReserva int     // plazo para retirar un libro apartado
+	MultaDiaria       float64 // monto por cada día de atraso
+	MultaMaxima       float64 // tope de la multa por préstamo (0 = sin tope)
+
+	// Suspensión automática (ver suspensiones.go); 0 la desactiva
+	DeudaMaxima          float64 // multas impagas por encima de las que se suspende
+	DiasAtrasoSuspension int     // días de atraso de un préstamo por encima de los que se suspende
+}
+
+// PoliticaPorDefecto retorna las reglas que usa NuevaBiblioteca para los
+// usuarios sin categoría
+func PoliticaPorDefecto() PoliticaPrestamo {
+	return PoliticaPrestamo{
+		DiasPrestamo:      14,
+		MaxRenovaciones:   2,
+		DiasRetiroReserva: 3,
+		MultaDiaria:       0.5,
+		MultaMaxima:       10,
+
+		DeudaMaxima:          10,
+		DiasAtrasoSuspension: 30,
+	}
+}
+
+// Validar verifica que la política tenga plazo de préstamo y ningún valor negativo
+func (p PoliticaPrestamo) Validar() error {
+	if p.DiasPrestamo <= 0 {
+		return errDatosInvalidos("error.politica_sin_plazo")
+	}
+	if p.MaxPrestamos < 0 || p.MaxRenovaciones < 0 || p.DiasRetiroReserva < 0 || p.MultaDiaria < 0 ||
+		p.MultaMaxima < 0 || p.DeudaMaxima < 0 || p.DiasAtrasoSuspension < 0 {
+		return errDatosInvalidos("error.politica_negativa")
+	}
+	return nil
+}
+
+// CalcularMulta retorna la multa para una cantidad de días de atraso
+// Usa receptor de VALOR porque solo lee la configuración
+func (p PoliticaPrestamo) CalcularMulta(diasAtraso int) float64 {
+	if diasAtraso <= 0 {
+		return 0
+	}
+	multa := float64(diasAtraso) * p.MultaDiaria
+	if p.MultaMaxima > 0 && multa > p.MultaMaxima {
+		multa = p.MultaMaxima
+	}
+	return multa
+}
+
+// EstaVencido indica si el préstamo sigue activo después de su fecha de devolución
+func (p Prestamo) EstaVencido(ahora time.Time) bool {
+	return !p.Devuelto && ahora.After(p.FechaDevolucion)
+}
+
+// PrestamosVencidos retorna los préstamos activos cuya fecha de devolución ya pasó
+func (b *Biblioteca) PrestamosVencidos(ahora time.Time) []Prestamo {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	vencidos := make([]Prestamo, 0)
+	for _, prestamo := range b.Prestamos {
+		if prestamo.EstaVencido(ahora) {
+			vencidos = append(vencidos, prestamo)
+		}
+	}
+	return vencidos
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCalcularMultaConTope(t *testing.T) {
+	politica := PoliticaPrestamo{DiasPrestamo: 14, MultaDiaria: 0.5, MultaMaxima: 10}
+	casos := []struct {
+		dias  int
+		multa float64
+	}{
+		{-1, 0},
+		{0, 0},
+		{1, 0.5},
+		{7, 3.5},
+		{20, 10},
+		{45, 10}, // tope
+	}
+	for _, c := range casos {
+		if multa := politica.CalcularMulta(c.dias); multa != c.multa {
+			t.Errorf("CalcularMulta(%d) = %v, se esperaba %v", c.dias, multa, c.multa)
+		}
+	}
+
+	politica.MultaMaxima = 0 // sin tope
+	if multa := politica.CalcularMulta(45); multa != 22.5 {
+		t.Errorf("Sin tope la multa debería ser 22.5, se obtuvo %v", multa)
+	}
+}
+
+func TestDevolucionAtrasadaRegistraLaMulta(t *testing.T) {
+	reloj := NuevoRelojVirtual(marzo(2, 10, 0))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 224)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+
+	// el estudiante tiene 14 días: vence el lunes 16 al cierre
+	prestamo, err := b.PrestarLibro(libro.ID, ana.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vencidos := b.PrestamosVencidos(marzo(16, 19, 0)); len(vencidos) != 0 {
+		t.Errorf("El préstamo todavía no venció: %+v", vencidos)
+	}
+	if vencidos := b.PrestamosVencidos(marzo(17, 9, 0)); len(vencidos) != 1 || vencidos[0].ID != prestamo.ID {
+		t.Errorf("El préstamo debería estar vencido: %+v", vencidos)
+	}
+
+	// martes, miércoles y jueves a 0.5 por día
+	reloj.Fijar(marzo(19, 12, 0))
+	devuelto, err := b.DevolverLibro(libro.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if devuelto.Multa != 1.5 {
+		t.Errorf("La multa debería ser 1.5, se obtuvo %v", devuelto.Multa)
+	}
+	if vencidos := b.PrestamosVencidos(reloj.Ahora()); len(vencidos) != 0 {
+		t.Errorf("Un préstamo devuelto no está vencido: %+v", vencidos)
+	}
+
+	if _, err := b.PagarMulta(devuelto.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PagarMulta(devuelto.ID); !errors.Is(err, ErrMultaNoPendiente) {
+		t.Errorf("Una multa pagada no se paga dos veces, se obtuvo %v", err)
+	}
+}
+
+func TestDevolucionATiempoNoTieneMulta(t *testing.T) {
+	reloj := NuevoRelojVirtual(marzo(2, 10, 0))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 224)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	reloj.Fijar(marzo(16, 19, 59))
+	devuelto, err := b.DevolverLibro(libro.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if devuelto.Multa != 0 {
+		t.Errorf("Devuelto antes del cierre no debería tener multa: %v", devuelto.Multa)
+	}
+	if _, err := b.PagarMulta(devuelto.ID); !errors.Is(err, ErrMultaNoPendiente) {
+		t.Errorf("Sin multa no hay nada que pagar, se obtuvo %v", err)
+	}
+}
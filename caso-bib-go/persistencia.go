@@ -0,0 +1,255 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ==========================================
+// PERSISTENCIA: SNAPSHOT EN DISCO
+// ==========================================
+
+// VersionSnapshot es la versión actual del formato de archivo
+// 1: un Libro era una sola copia física con Prestado/ReservadoPara
+// 2: cada Libro tiene Ejemplares y los préstamos apuntan a un EjemplarID
+// 3: una secuencia de IDs por entidad en lugar del contador ProximoID
+const VersionSnapshot = 3
+
+// snapshotBiblioteca es la representación en disco de la biblioteca
+// Incluye las secuencias de IDs para poder reconstruir el estado completo
+type snapshotBiblioteca struct {
+	Version    int
+	Nombre     string
+	Direccion  string
+	Libros     []Libro
+	Usuarios   []Usuario
+	Prestamos  []Prestamo
+	Reservas   []Reserva
+	Politica   *PoliticaPrestamo
+	Categorias map[CategoriaUsuario]PoliticaPrestamo
+	Calendario *Calendario
+	Secuencias *Secuencias
+	Journal    int `json:",omitempty"` // último evento del journal que ya incluye
+}
+
+// GuardarEn escribe el estado completo de la biblioteca en un archivo JSON
+// Escribe primero en un archivo temporal y luego lo renombra, así un
+// fallo a mitad de escritura nunca deja un catálogo corrupto
+// Los guardados se hacen de a uno, así el último en renombrar es también
+// el que tomó el estado más reciente
+func (b *Biblioteca) GuardarEn(path string) error {
+	b.guardado.Lock()
+	defer b.guardado.Unlock()
+	_, err := b.guardar(path)
+	return err
+}
+
+// Consolidar guarda el estado como GuardarEn y después recorta del journal
+// conectado los eventos que el archivo guardado ya incluye, para que no
+// crezca sin límite. Solo debe usarse con el archivo de datos del journal
+// No poder recortar no deshace el guardado: se informa como aviso
+func (b *Biblioteca) Consolidar(path string) error {
+	b.guardado.Lock()
+	defer b.guardado.Unlock()
+	hasta, err := b.guardar(path)
+	if err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.journal != nil {
+		if err := b.journal.Recortar(hasta); err != nil {
+			b.avisar(err)
+		}
+	}
+	return nil
+}
+
+// guardar escribe el snapshot en path y retorna el último evento del
+// journal que incluye
+// Quien la llama debe tener tomado b.guardado
+func (b *Biblioteca) guardar(path string) (int, error) {
+	b.mu.RLock()
+	snap := b.snapshot()
+	datos, err := json.MarshalIndent(snap, "", "  ")
+	b.mu.RUnlock()
+	if err != nil {
+		return 0, errArchivo(err, "error.serializar_biblioteca")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, errArchivo(err, "error.archivo_temporal", filepath.Dir(path))
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := tmp.Write(datos); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return 0, errArchivo(err, "error.archivo_escribir", tmpPath)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return 0, errArchivo(err, "error.archivo_escribir", tmpPath)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return 0, errArchivo(err, "error.archivo_escribir", tmpPath)
+	}
+
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return 0, errArchivo(err, "error.archivo_reemplazar", path)
+	}
+	return snap.Journal, nil
+}
+
+// snapshot arma la representación en disco del estado actual
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) snapshot() snapshotBiblioteca {
+	return snapshotBiblioteca{
+		Version:    VersionSnapshot,
+		Nombre:     b.Nombre,
+		Direccion:  b.Direccion,
+		Libros:     b.Libros,
+		Usuarios:   b.Usuarios,
+		Prestamos:  b.Prestamos,
+		Reservas:   b.Reservas,
+		Politica:   &b.Politica,
+		Categorias: b.Categorias,
+		Calendario: &b.Calendario,
+		Secuencias: &b.secuencias,
+		Journal:    b.secuenciaJournal,
+	}
+}
+
+// CargarDesde lee un snapshot generado por GuardarEn y reconstruye la biblioteca
+func CargarDesde(path string) (*Biblioteca, error) {
+	datos, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errArchivo(err, "error.archivo_leer", path)
+	}
+	snap, err := leerSnapshot(datos)
+	if err != nil {
+		return nil, errFormato(err, "error.snapshot_invalido", path)
+	}
+
+	b := NuevaBiblioteca(snap.Nombre, snap.Direccion)
+	b.restaurar(snap)
+	return b, nil
+}
+
+// leerSnapshot decodifica un snapshot de cualquier versión y lo migra a la actual
+func leerSnapshot(datos []byte) (*snapshotBiblioteca, error) {
+	var snap snapshotBiblioteca
+	if err := json.Unmarshal(datos, &snap); err != nil {
+		return nil, err
+	}
+	if snap.Version == 1 {
+		if err := migrarSnapshotV1(&snap, datos); err != nil {
+			return nil, errFormato(err, "error.snapshot_migrar", 1)
+		}
+	}
+	if snap.Version == 2 {
+		migrarSnapshotV2(&snap)
+	}
+	if snap.Version != VersionSnapshot {
+		return nil, errFormato(nil, "error.snapshot_version", snap.Version)
+	}
+	return &snap, nil
+}
+
+// restaurar reemplaza el estado de la biblioteca por el del snapshot
+// Lo que el snapshot no trae (archivos viejos) conserva su valor actual
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) restaurar(snap *snapshotBiblioteca) {
+	b.Nombre = snap.Nombre
+	b.Direccion = snap.Direccion
+	if snap.Libros != nil {
+		b.Libros = snap.Libros
+	}
+	if snap.Usuarios != nil {
+		b.Usuarios = snap.Usuarios
+	}
+	if snap.Prestamos != nil {
+		b.Prestamos = snap.Prestamos
+	}
+	if snap.Reservas != nil {
+		b.Reservas = snap.Reservas
+	}
+	if snap.Politica != nil {
+		b.Politica = *snap.Politica
+	}
+	if snap.Categorias != nil {
+		b.Categorias = snap.Categorias
+	}
+	if snap.Calendario != nil {
+		b.Calendario = *snap.Calendario
+	}
+	if snap.Secuencias != nil {
+		b.secuencias = *snap.Secuencias
+	}
+	b.secuenciaJournal = snap.Journal
+	b.reconstruirIndices()
+}
+
+// migrarSnapshotV1 convierte cada libro de la versión 1 en un título con un
+// único ejemplar que conserva su estado, y enlaza préstamos y reservas a él
+func migrarSnapshotV1(snap *snapshotBiblioteca, datos []byte) error {
+	var v1 struct {
+		Libros []struct {
+			ID            LibroID
+			Prestado      bool
+			ReservadoPara UsuarioID
+		}
+		ProximoID int
+	}
+	if err := json.Unmarshal(datos, &v1); err != nil {
+		return err
+	}
+
+	// Los ejemplares toman IDs del contador compartido, igual que en la versión 2
+	ejemplarDe := make(map[LibroID]EjemplarID)
+	for i, libro := range v1.Libros {
+		ejemplar := Ejemplar{
+			ID:            EjemplarID(v1.ProximoID),
+			CodigoBarras:  fmt.Sprintf("EJ-%06d", v1.ProximoID),
+			Prestado:      libro.Prestado,
+			ReservadoPara: libro.ReservadoPara,
+		}
+		v1.ProximoID++
+		snap.Libros[i].Ejemplares = []Ejemplar{ejemplar}
+		ejemplarDe[libro.ID] = ejemplar.ID
+	}
+	for i := range snap.Prestamos {
+		snap.Prestamos[i].EjemplarID = ejemplarDe[snap.Prestamos[i].LibroID]
+	}
+	for i := range snap.Reservas {
+		if snap.Reservas[i].Estado == ReservaLista {
+			snap.Reservas[i].EjemplarID = ejemplarDe[snap.Reservas[i].LibroID]
+		}
+	}
+
+	snap.Version = 2
+	return nil
+}
+
+// migrarSnapshotV2 reemplaza el contador compartido por una secuencia por
+// entidad. Los IDs existentes se conservan (eran únicos entre todas las
+// entidades) y cada secuencia continúa desde el mayor ID de su tipo
+func migrarSnapshotV2(snap *snapshotBiblioteca) {
+	b := &Biblioteca{
+		Libros:     snap.Libros,
+		Usuarios:   snap.Usuarios,
+		Prestamos:  snap.Prestamos,
+		Reservas:   snap.Reservas,
+		secuencias: nuevasSecuencias(),
+	}
+	b.ajustarSecuencias()
+	snap.Secuencias = &b.secuencias
+	snap.Version = 3
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// ==========================================
+// RELOJ: FECHA ACTUAL INYECTABLE
+// ==========================================
+
+// Reloj entrega la fecha y hora actual de la biblioteca
+// Las operaciones nunca llaman a time.Now() directamente, así se pueden
+// simular semanas de actividad o probar vencimientos sin esperar
+type Reloj interface {
+	Ahora() time.Time
+}
+
+// RelojSistema es el reloj real (el que usa NuevaBiblioteca)
+type RelojSistema struct{}
+
+func (RelojSistema) Ahora() time.Time {
+	return time.Now()
+}
+
+// RelojVirtual es un reloj que solo avanza cuando se le indica
+// Es seguro para uso concurrente
+type RelojVirtual struct {
+	mu    sync.Mutex
+	ahora time.Time
+}
+
+// NuevoRelojVirtual crea un reloj detenido en la fecha indicada
+func NuevoRelojVirtual(inicio time.Time) *RelojVirtual {
+	return &RelojVirtual{ahora: inicio}
+}
+
+func (r *RelojVirtual) Ahora() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ahora
+}
+
+// Avanzar adelanta el reloj la duración indicada
+func (r *RelojVirtual) Avanzar(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ahora = r.ahora.Add(d)
+}
+
+// Fijar pone el reloj en una fecha concreta
+func (r *RelojVirtual) Fijar(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ahora = t
+}
+
+// UsarReloj reemplaza el reloj de la biblioteca
+func (b *Biblioteca) UsarReloj(reloj Reloj) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.reloj = reloj
+}
+
+// Ahora retorna la fecha actual según el reloj de la biblioteca
+// La API y la CLI la usan para consultas como los préstamos vencidos
+func (b *Biblioteca) Ahora() time.Time {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.ahora()
+}
+
+// ahora es Ahora para quien ya tiene tomado b.mu
+func (b *Biblioteca) ahora() time.Time {
+	if b.reloj == nil {
+		return time.Now()
+	}
+	return b.reloj.Ahora()
+}
@@ -0,0 +1,53 @@
+package main
+
+// ==========================================
+// RENOVACIÓN DE PRÉSTAMOS
+// ==========================================
+
+// RenovarPrestamo extiende la fecha de devolución por un período de préstamo más
+// Se rechaza si el préstamo ya fue devuelto, está vencido, alcanzó el máximo
+// de renovaciones, si su usuario está suspendido o si otro usuario tiene una
+// reserva sobre el libro
+func (b *Biblioteca) RenovarPrestamo(prestamoID PrestamoID) (*Prestamo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prestamo := b.buscarPrestamo(prestamoID)
+	if prestamo == nil {
+		return nil, errPrestamoNoEncontrado(prestamoID)
+	}
+	if prestamo.Devuelto {
+		return nil, errNoRenovable(prestamo, txt("error.prestamo_devuelto", prestamoID))
+	}
+	if prestamo.EstaVencido(b.ahora()) {
+		return nil, errNoRenovable(prestamo, txt("error.prestamo_vencido", prestamoID))
+	}
+	// la suspensión se revisa igual que al prestar: el atraso de otros
+	// préstamos del usuario crece con los días
+	if usuario := b.buscarUsuario(prestamo.UsuarioID); usuario != nil {
+		if err := b.revisarSuspension(usuario, b.ahora()); err != nil {
+			return nil, err
+		}
+		if usuario.Suspension != nil {
+			return nil, errUsuarioSuspendido(usuario)
+		}
+	}
+	politica := b.politicaDe(prestamo.UsuarioID)
+	if prestamo.Renovaciones >= politica.MaxRenovaciones {
+		return nil, errNoRenovable(prestamo, txtN("error.max_renovaciones", politica.MaxRenovaciones,
+			prestamoID, politica.MaxRenovaciones))
+	}
+	if b.reservadoPorOtro(prestamo.LibroID, prestamo.UsuarioID) {
+		return nil, &ErrorBiblioteca{Tipo: ErrLibroReservado, LibroID: prestamo.LibroID, PrestamoID: prestamoID,
+			mensaje: txt("error.reservado_por_otros", prestamo.LibroID)}
+	}
+
+	renovado := *prestamo
+	renovado.FechaDevolucion = b.Calendario.Vencimiento(prestamo.FechaDevolucion, politica.DiasPrestamo)
+	renovado.Renovaciones++
+	if err := b.escribirEvento(Evento{Tipo: EventoPrestamoRenovado, Prestamo: &renovado}); err != nil {
+		return nil, errJournal(err, "error.renovacion_no_registrada")
+	}
+	*prestamo = renovado
+	return &renovado, nil
+}
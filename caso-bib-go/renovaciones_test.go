@@ -0,0 +1,103 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// bibliotecaConPrestamo presta Ficciones a Ana (estudiante) el lunes 2 de marzo;
+// Beto queda registrado para reservar
+func bibliotecaConPrestamo(t *testing.T) (*Biblioteca, *RelojVirtual, *Prestamo, *Usuario) {
+	t.Helper()
+	reloj := NuevoRelojVirtual(marzo(2, 10, 0))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 224)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+	prestamo, err := b.PrestarLibro(libro.ID, ana.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, reloj, prestamo, beto
+}
+
+func TestRenovarPrestamoHastaElMaximo(t *testing.T) {
+	b, _, prestamo, _ := bibliotecaConPrestamo(t)
+
+	// cada renovación suma 14 días a la fecha de devolución anterior (el 44
+	// de marzo es el lunes 13 de abril)
+	for i, vence := range []int{30, 44} {
+		renovado, err := b.RenovarPrestamo(prestamo.ID)
+		if err != nil {
+			t.Fatalf("Renovación %d: %v", i+1, err)
+		}
+		if renovado.Renovaciones != i+1 || !renovado.FechaDevolucion.Equal(marzo(vence, 20, 0)) {
+			t.Errorf("Renovación %d: %d renovaciones, vence %s", i+1, renovado.Renovaciones, renovado.FechaDevolucion)
+		}
+	}
+
+	// el estudiante puede renovar 2 veces
+	if _, err := b.RenovarPrestamo(prestamo.ID); !errors.Is(err, ErrPrestamoNoRenovable) {
+		t.Errorf("La tercera renovación debería rechazarse, se obtuvo %v", err)
+	}
+	if actual := b.BuscarPrestamo(prestamo.ID); actual.Renovaciones != 2 {
+		t.Errorf("El rechazo no debería cambiar el préstamo: %+v", actual)
+	}
+}
+
+func TestRenovarPrestamoRechazos(t *testing.T) {
+	b, reloj, prestamo, beto := bibliotecaConPrestamo(t)
+
+	// otro usuario espera el libro
+	reserva, err := b.ReservarLibro(prestamo.LibroID, beto.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.RenovarPrestamo(prestamo.ID); !errors.Is(err, ErrLibroReservado) {
+		t.Errorf("Con una reserva de otro se esperaba ErrLibroReservado, se obtuvo %v", err)
+	}
+	if err := b.CancelarReserva(reserva.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// vencido
+	reloj.Fijar(marzo(17, 10, 0))
+	if _, err := b.RenovarPrestamo(prestamo.ID); !errors.Is(err, ErrPrestamoNoRenovable) {
+		t.Errorf("Un préstamo vencido no se renueva, se obtuvo %v", err)
+	}
+
+	// devuelto
+	if _, err := b.DevolverLibro(prestamo.LibroID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.RenovarPrestamo(prestamo.ID); !errors.Is(err, ErrPrestamoNoRenovable) {
+		t.Errorf("Un préstamo devuelto no se renueva, se obtuvo %v", err)
+	}
+	if _, err := b.RenovarPrestamo(99); !errors.Is(err, ErrPrestamoNoEncontrado) {
+		t.Errorf("Se esperaba ErrPrestamoNoEncontrado, se obtuvo %v", err)
+	}
+}
+
+func TestRenovarPrestamoRechazaAlUsuarioSuspendido(t *testing.T) {
+	b, reloj, prestamo, _ := bibliotecaConPrestamo(t)
+	otro, _ := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+
+	// el 10 de abril Ana lleva 22 días de atención de atraso con Ficciones y
+	// todavía puede llevar Rayuela, que vence el 24
+	reloj.Fijar(time.Date(2026, 4, 10, 10, 0, 0, 0, time.Local))
+	rayuela, err := b.PrestarLibro(otro.ID, prestamo.UsuarioID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// el 22 pasó los 30 días de atraso: Rayuela no venció, pero no se renueva
+	reloj.Fijar(time.Date(2026, 4, 22, 10, 0, 0, 0, time.Local))
+	if _, err := b.RenovarPrestamo(rayuela.ID); !errors.Is(err, ErrUsuarioSuspendido) {
+		t.Errorf("Se esperaba ErrUsuarioSuspendido, se obtuvo %v", err)
+	}
+	if actual := b.BuscarPrestamo(rayuela.ID); actual.Renovaciones != 0 {
+		t.Errorf("El rechazo no debería cambiar el préstamo: %+v", actual)
+	}
+}
@@ -0,0 +1,301 @@
+package main
+
+import "time"
+
+// ==========================================
+// RESERVAS: COLA FIFO POR LIBRO
+// ==========================================
+
+// EstadoReserva indica en qué etapa está una reserva
+type EstadoReserva string
+
+const (
+	ReservaPendiente EstadoReserva = "pendiente" // en la cola esperando el libro
+	ReservaLista     EstadoReserva = "lista"     // libro apartado esperando el retiro
+	ReservaCumplida  EstadoReserva = "cumplida"  // el usuario retiró el libro
+	ReservaCancelada EstadoReserva = "cancelada"
+	ReservaVencida   EstadoReserva = "vencida" // no se retiró antes del plazo
+)
+
+// Reserva representa a un usuario esperando un libro
+// La cola es por título: el primer ejemplar que se libere se aparta
+type Reserva struct {
+	ID                ReservaID
+	LibroID           LibroID
+	UsuarioID         UsuarioID
+	FechaReserva      time.Time
+	Estado            EstadoReserva
+	EjemplarID        EjemplarID // solo para reservas listas
+	FechaLimiteRetiro time.Time  // solo para reservas listas
+}
+
+// EstaActiva indica si la reserva sigue en la cola o apartada
+func (r Reserva) EstaActiva() bool {
+	return r.Estado == ReservaPendiente || r.Estado == ReservaLista
+}
+
+// ReservarLibro pone al usuario al final de la cola de espera del libro
+// Solo se puede reservar un libro sin ejemplares disponibles
+func (b *Biblioteca) ReservarLibro(libroID LibroID, usuarioID UsuarioID) (*Reserva, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	libro := b.buscarLibro(libroID)
+	if libro == nil {
+		return nil, errLibroNoEncontrado(libroID)
+	}
+	usuario := b.buscarUsuario(usuarioID)
+	if usuario == nil {
+		return nil, errUsuarioNoEncontrado(usuarioID)
+	}
+	if err := b.revisarSuspension(usuario, b.ahora()); err != nil {
+		return nil, err
+	}
+	if err := usuario.PuedePrestar(); err != nil {
+		return nil, err
+	}
+	if libro.Disponibles() > 0 {
+		return nil, &ErrorBiblioteca{Tipo: ErrReservaInnecesaria, LibroID: libroID, UsuarioID: usuarioID,
+			mensaje: txt("error.reserva_innecesaria", libro.Titulo, libro.disponibilidad())}
+	}
+	for _, prestamo := range b.prestamosActivosDe(libro) {
+		if prestamo.UsuarioID == usuarioID {
+			return nil, &ErrorBiblioteca{Tipo: ErrReservaInnecesaria, LibroID: libroID, UsuarioID: usuarioID, PrestamoID: prestamo.ID,
+				mensaje: txt("error.ya_prestado_al_usuario", usuario.Nombre, libro.Titulo)}
+		}
+	}
+	for _, reserva := range b.Reservas {
+		if reserva.LibroID == libroID && reserva.UsuarioID == usuarioID && reserva.EstaActiva() {
+			return nil, &ErrorBiblioteca{Tipo: ErrReservaDuplicada, LibroID: libroID, UsuarioID: usuarioID, ReservaID: reserva.ID,
+				mensaje: txt("error.reserva_duplicada", usuario.Nombre, libro.Titulo)}
+		}
+	}
+
+	secuenciasAntes := b.secuencias
+	reserva := Reserva{
+		ID:           b.siguienteReservaID(),
+		LibroID:      libroID,
+		UsuarioID:    usuarioID,
+		FechaReserva: b.ahora(),
+		Estado:       ReservaPendiente,
+	}
+	if err := b.escribirEvento(Evento{Tipo: EventoReservaCreada, Reserva: &reserva}); err != nil {
+		b.secuencias = secuenciasAntes
+		return nil, errJournal(err, "error.reserva_no_registrada")
+	}
+	b.Reservas = append(b.Reservas, reserva)
+	b.indexarReserva(len(b.Reservas) - 1)
+	return &reserva, nil
+}
+
+// CancelarReserva saca una reserva de la cola
+// Si el libro estaba apartado para ese usuario pasa al siguiente de la cola
+// La cancelación y el nuevo apartado van en un solo evento; si el journal
+// falla la reserva queda como estaba
+func (b *Biblioteca) CancelarReserva(reservaID ReservaID) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	reserva := b.buscarReserva(reservaID)
+	if reserva == nil {
+		return errReservaNoEncontrada(reservaID)
+	}
+	if !reserva.EstaActiva() {
+		return &ErrorBiblioteca{Tipo: ErrReservaNoActiva, ReservaID: reservaID, LibroID: reserva.LibroID, UsuarioID: reserva.UsuarioID,
+			mensaje: txt("error.reserva_no_activa", reservaID, reserva.Estado)}
+	}
+
+	reservaAntes := *reserva
+	reserva.Estado = ReservaCancelada
+	evento := Evento{Tipo: EventoReservaActualizada, Reserva: reserva}
+	deshacer := func() {}
+	if reservaAntes.Estado == ReservaLista {
+		deshacer = b.liberarApartado(reserva, &evento, b.ahora())
+	}
+	if err := b.escribirEvento(evento); err != nil {
+		deshacer()
+		*reserva = reservaAntes
+		return errJournal(err, "error.cancelacion_no_registrada", reservaID)
+	}
+	return nil
+}
+
+// ColaReservas retorna las reservas activas de un libro en orden de llegada
+func (b *Biblioteca) ColaReservas(libroID LibroID) []Reserva {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	cola := make([]Reserva, 0)
+	for _, reserva := range b.Reservas {
+		if reserva.LibroID == libroID && reserva.EstaActiva() {
+			cola = append(cola, reserva)
+		}
+	}
+	return cola
+}
+
+// ProcesarReservasVencidas marca como vencidas las reservas listas cuyo plazo
+// de retiro pasó y aparta el libro para el siguiente de la cola
+// Cada vencimiento va en un solo evento con su nuevo apartado
+// Retorna las reservas que vencieron
+func (b *Biblioteca) ProcesarReservasVencidas(ahora time.Time) ([]Reserva, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	vencidas := make([]Reserva, 0)
+	for i := range b.Reservas {
+		reserva := &b.Reservas[i]
+		if reserva.Estado != ReservaLista || !ahora.After(reserva.FechaLimiteRetiro) {
+			continue
+		}
+		reservaAntes := *reserva
+		reserva.Estado = ReservaVencida
+		evento := Evento{Tipo: EventoReservaActualizada, Reserva: reserva}
+		deshacer := b.liberarApartado(reserva, &evento, ahora)
+		if err := b.escribirEvento(evento); err != nil {
+			deshacer()
+			*reserva = reservaAntes
+			return vencidas, errJournal(err, "error.vencimiento_no_registrado", reserva.ID)
+		}
+		vencidas = append(vencidas, *reserva)
+	}
+	return vencidas, nil
+}
+
+// BuscarReserva busca una reserva por ID
+// Retorna una copia, igual que BuscarLibro
+func (b *Biblioteca) BuscarReserva(id ReservaID) *Reserva {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	reserva := b.buscarReserva(id)
+	if reserva == nil {
+		return nil
+	}
+	copia := *reserva
+	return &copia
+}
+
+// buscarReserva retorna un puntero a la reserva original dentro del slice
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) buscarReserva(id ReservaID) *Reserva {
+	pos, ok := b.indices.reservas[id]
+	if !ok {
+		return nil
+	}
+	return &b.Reservas[pos]
+}
+
+// liberarApartado quita el apartado del ejemplar de una reserva que dejó de
+// estar lista y lo aparta para el siguiente de la cola, sin registrarlo:
+// agrega al evento el libro y la reserva que quedó lista, si la hay
+// Retorna la función que deshace los cambios si el evento no se registra
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) liberarApartado(reserva *Reserva, evento *Evento, ahora time.Time) func() {
+	libro, ejemplar := b.buscarEjemplar(reserva.EjemplarID)
+	if ejemplar == nil {
+		return func() {}
+	}
+	reservadoAntes := ejemplar.ReservadoPara
+	ejemplar.ReservadoPara = 0
+	evento.Libro = libro
+
+	siguiente, deshacerApartado := b.apartar(libro, ejemplar, ahora)
+	evento.Apartada = siguiente
+	return func() {
+		if siguiente != nil {
+			deshacerApartado()
+		}
+		ejemplar.ReservadoPara = reservadoAntes
+	}
+}
+
+// apartarParaSiguiente aparta un ejemplar libre para la primera reserva
+// pendiente de la cola de su libro y lo registra en el journal
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) apartarParaSiguiente(libro *Libro, ejemplar *Ejemplar, ahora time.Time) error {
+	reserva, deshacer := b.apartar(libro, ejemplar, ahora)
+	if reserva == nil {
+		return nil
+	}
+	err := b.escribirEvento(Evento{
+		Tipo:    EventoReservaActualizada,
+		Libro:   libro,
+		Reserva: reserva,
+	})
+	if err != nil {
+		deshacer()
+		return errJournal(err, "error.apartado_no_registrado",
+			ejemplar.CodigoBarras, reserva.ID)
+	}
+	return nil
+}
+
+// apartar aparta un ejemplar libre para la primera reserva pendiente de la
+// cola de su libro, con un plazo de retiro, sin registrarlo en el journal
+// Se saltea a quien hoy no podría retirarlo (inactivo o suspendido); su
+// reserva sigue pendiente en la cola
+// Retorna la reserva que quedó lista (nil si no hay a quién apartarlo) y la
+// función que deshace el apartado
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) apartar(libro *Libro, ejemplar *Ejemplar, ahora time.Time) (*Reserva, func()) {
+	if ejemplar == nil || !ejemplar.EstaDisponible() {
+		return nil, nil
+	}
+	for i := range b.Reservas {
+		reserva := &b.Reservas[i]
+		if reserva.LibroID != libro.ID || reserva.Estado != ReservaPendiente {
+			continue
+		}
+		if !b.puedeRetirar(reserva.UsuarioID, ahora) {
+			continue
+		}
+		reservaAntes := *reserva
+		reserva.Estado = ReservaLista
+		reserva.EjemplarID = ejemplar.ID
+		reserva.FechaLimiteRetiro = b.Calendario.Vencimiento(ahora, b.politicaDe(reserva.UsuarioID).DiasRetiroReserva)
+		ejemplar.ReservadoPara = reserva.UsuarioID
+		return reserva, func() {
+			*reserva = reservaAntes
+			ejemplar.ReservadoPara = 0
+		}
+	}
+	return nil, nil
+}
+
+// puedeRetirar indica si el usuario está activo y no le corresponde una
+// suspensión a la fecha, aunque todavía no se haya registrado
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) puedeRetirar(usuarioID UsuarioID, ahora time.Time) bool {
+	usuario := b.buscarUsuario(usuarioID)
+	if usuario == nil || usuario.PuedePrestar() != nil {
+		return false
+	}
+	return b.evaluarSuspension(usuarioID, ahora) == nil
+}
+
+// retirarReserva marca como cumplida la reserva lista del usuario sobre el ejemplar
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) retirarReserva(ejemplar *Ejemplar, usuarioID UsuarioID) *Reserva {
+	ejemplar.ReservadoPara = 0
+	for i := range b.Reservas {
+		reserva := &b.Reservas[i]
+		if reserva.EjemplarID == ejemplar.ID && reserva.UsuarioID == usuarioID &&
+			reserva.Estado == ReservaLista {
+			reserva.Estado = ReservaCumplida
+			return reserva
+		}
+	}
+	return nil
+}
+
+// reservadoPorOtro indica si alguien distinto del usuario espera el libro
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) reservadoPorOtro(libroID LibroID, usuarioID UsuarioID) bool {
+	for _, reserva := range b.Reservas {
+		if reserva.LibroID == libroID && reserva.UsuarioID != usuarioID && reserva.EstaActiva() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// bibliotecaConCola presta el único ejemplar de Ficciones a Ana y pone en
+// la cola a Beto y después a Carla, todos estudiantes
+func bibliotecaConCola(t *testing.T) (*Biblioteca, *RelojVirtual, LibroID, []*Reserva) {
+	t.Helper()
+	reloj := NuevoRelojVirtual(marzo(2, 10, 0))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 224)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+	reservas := make([]*Reserva, 0, 2)
+	for _, nombre := range []string{"Beto", "Carla"} {
+		usuario, _ := b.RegistrarUsuario(nombre, nombre+"@test", "", CategoriaEstudiante)
+		reserva, err := b.ReservarLibro(libro.ID, usuario.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reservas = append(reservas, reserva)
+	}
+	return b, reloj, libro.ID, reservas
+}
+
+func TestReservasSeAtiendenEnOrden(t *testing.T) {
+	b, reloj, libroID, reservas := bibliotecaConCola(t)
+	beto, carla := reservas[0], reservas[1]
+
+	// al devolver, el ejemplar queda apartado para Beto hasta el lunes 9
+	reloj.Fijar(marzo(5, 10, 0))
+	if _, err := b.DevolverLibro(libroID); err != nil {
+		t.Fatal(err)
+	}
+	lista := b.BuscarReserva(beto.ID)
+	if lista.Estado != ReservaLista || !lista.FechaLimiteRetiro.Equal(marzo(9, 20, 0)) {
+		t.Fatalf("La reserva de Beto debería estar lista hasta el lunes: %+v", lista)
+	}
+	if _, err := b.PrestarEjemplar(lista.EjemplarID, carla.UsuarioID); !errors.Is(err, ErrLibroReservado) {
+		t.Errorf("El ejemplar apartado es de Beto, se obtuvo %v", err)
+	}
+
+	// Beto no lo retira y el apartado pasa a Carla
+	reloj.Fijar(marzo(10, 10, 0))
+	vencidas, err := b.ProcesarReservasVencidas(reloj.Ahora())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vencidas) != 1 || vencidas[0].ID != beto.ID {
+		t.Fatalf("Solo debería vencer la reserva de Beto: %+v", vencidas)
+	}
+	if estado := b.BuscarReserva(carla.ID).Estado; estado != ReservaLista {
+		t.Fatalf("La reserva de Carla debería estar lista: %s", estado)
+	}
+	if _, err := b.PrestarLibro(libroID, carla.UsuarioID); err != nil {
+		t.Fatal(err)
+	}
+	if estado := b.BuscarReserva(carla.ID).Estado; estado != ReservaCumplida {
+		t.Errorf("Al retirar el libro la reserva se cumple: %s", estado)
+	}
+	if cola := b.ColaReservas(libroID); len(cola) != 0 {
+		t.Errorf("La cola debería quedar vacía: %+v", cola)
+	}
+}
+
+func TestCancelarReservaListaPasaAlSiguiente(t *testing.T) {
+	b, _, libroID, reservas := bibliotecaConCola(t)
+	beto, carla := reservas[0], reservas[1]
+
+	if _, err := b.DevolverLibro(libroID); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.CancelarReserva(beto.ID); err != nil {
+		t.Fatal(err)
+	}
+	if estado := b.BuscarReserva(beto.ID).Estado; estado != ReservaCancelada {
+		t.Errorf("La reserva de Beto debería estar cancelada: %s", estado)
+	}
+	if estado := b.BuscarReserva(carla.ID).Estado; estado != ReservaLista {
+		t.Errorf("El ejemplar debería quedar apartado para Carla: %s", estado)
+	}
+	if err := b.CancelarReserva(beto.ID); !errors.Is(err, ErrReservaNoActiva) {
+		t.Errorf("Una reserva cancelada no se cancela otra vez, se obtuvo %v", err)
+	}
+}
+
+func TestReservarLibroRechazos(t *testing.T) {
+	b, _, libroID, reservas := bibliotecaConCola(t)
+	otro, _ := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+
+	casos := []struct {
+		libroID   LibroID
+		usuarioID UsuarioID
+		esperado  error
+	}{
+		{otro.ID, reservas[0].UsuarioID, ErrReservaInnecesaria}, // hay ejemplares libres
+		{libroID, 1, ErrReservaInnecesaria},                     // Ana ya lo tiene prestado
+		{libroID, reservas[0].UsuarioID, ErrReservaDuplicada},   // Beto ya está en la cola
+		{libroID, 99, ErrUsuarioNoEncontrado},
+		{99, reservas[0].UsuarioID, ErrLibroNoEncontrado},
+	}
+	for _, c := range casos {
+		if _, err := b.ReservarLibro(c.libroID, c.usuarioID); !errors.Is(err, c.esperado) {
+			t.Errorf("ReservarLibro(%d, %d): se esperaba %v, se obtuvo %v", c.libroID, c.usuarioID, c.esperado, err)
+		}
+	}
+	if cola := b.ColaReservas(libroID); len(cola) != 2 {
+		t.Errorf("Los rechazos no deberían cambiar la cola: %+v", cola)
+	}
+}
+
+func TestApartadoSalteaAlUsuarioSuspendido(t *testing.T) {
+	reloj := NuevoRelojVirtual(marzo(2, 10, 0))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+	ficciones, _ := b.AgregarLibro("Ficciones", "Borges", "", 224)
+	rayuela, _ := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+	carla, _ := b.RegistrarUsuario("Carla", "carla@test", "", CategoriaEstudiante)
+	if _, err := b.PrestarLibro(ficciones.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.PrestarLibro(rayuela.ID, beto.ID); err != nil {
+		t.Fatal(err)
+	}
+	reservaBeto, err := b.ReservarLibro(ficciones.ID, beto.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reservaCarla, err := b.ReservarLibro(ficciones.ID, carla.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Beto pasa los 30 días de atraso con Rayuela: no puede retirar Ficciones
+	reloj.Avanzar(70 * 24 * time.Hour)
+	if _, err := b.DevolverLibro(ficciones.ID); err != nil {
+		t.Fatal(err)
+	}
+	if estado := b.BuscarReserva(reservaCarla.ID).Estado; estado != ReservaLista {
+		t.Errorf("El ejemplar debería quedar apartado para Carla: %s", estado)
+	}
+	if estado := b.BuscarReserva(reservaBeto.ID).Estado; estado != ReservaPendiente {
+		t.Errorf("Beto debería seguir esperando en la cola: %s", estado)
+	}
+}
+
+func TestCancelarReservaListaEsUnSoloEvento(t *testing.T) {
+	b, _, libroID, reservas := bibliotecaConCola(t)
+	beto, carla := reservas[0], reservas[1]
+	j, archivo, path := abrirJournalConFallas(t)
+	if err := b.UsarJournal(j); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.DevolverLibro(libroID); err != nil {
+		t.Fatal(err)
+	}
+	antes, eventos := estadoJSON(t, b), j.secuencia
+
+	// si el journal falla no queda nada a medias: ni cancelada ni liberada
+	archivo.fallarSync = true
+	if err := b.CancelarReserva(beto.ID); !errors.Is(err, ErrJournal) {
+		t.Fatalf("Se esperaba un error del journal, se obtuvo %v", err)
+	}
+	if despues := estadoJSON(t, b); despues != antes {
+		t.Errorf("El estado cambió aunque el evento no se registró\nantes:   %s\ndespués: %s", antes, despues)
+	}
+
+	archivo.fallarSync = false
+	if err := b.CancelarReserva(beto.ID); err != nil {
+		t.Fatal(err)
+	}
+	if j.secuencia != eventos+1 {
+		t.Errorf("La cancelación debería ser un evento, se registraron %d", j.secuencia-eventos)
+	}
+	if estado := b.BuscarReserva(carla.ID).Estado; estado != ReservaLista {
+		t.Errorf("El ejemplar debería quedar apartado para Carla: %s", estado)
+	}
+	reconstruida, err := ReconstruirDesdeJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if esperado, obtenido := estadoJSON(t, b), estadoJSON(t, reconstruida); esperado != obtenido {
+		t.Errorf("El journal no reproduce el estado\nesperado: %s\nobtenido: %s", esperado, obtenido)
+	}
+}
+
+func TestApartadoSalteaAlUsuarioInactivo(t *testing.T) {
+	b, _, libroID, reservas := bibliotecaConCola(t)
+	beto, carla := reservas[0], reservas[1]
+
+	if _, err := b.DesactivarUsuario(beto.UsuarioID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.DevolverLibro(libroID); err != nil {
+		t.Fatal(err)
+	}
+	if estado := b.BuscarReserva(carla.ID).Estado; estado != ReservaLista {
+		t.Errorf("El ejemplar debería quedar apartado para Carla: %s", estado)
+	}
+	if estado := b.BuscarReserva(beto.ID).Estado; estado != ReservaPendiente {
+		t.Errorf("La reserva de Beto debería seguir pendiente: %s", estado)
+	}
+	if _, err := b.ReservarLibro(libroID, beto.UsuarioID); !errors.Is(err, ErrUsuarioInactivo) {
+		t.Errorf("Un usuario inactivo no puede reservar, se obtuvo %v", err)
+	}
+	if _, err := b.DesactivarUsuario(99); !errors.Is(err, ErrUsuarioNoEncontrado) {
+		t.Errorf("Se esperaba ErrUsuarioNoEncontrado, se obtuvo %v", err)
+	}
+}
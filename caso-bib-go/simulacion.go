@@ -0,0 +1,270 @@
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+// ==========================================
+// SIMULACIÓN: ACTIVIDAD SINTÉTICA EN TIEMPO VIRTUAL
+// ==========================================
+
+// ConfigSimulacion define el catálogo sintético y el comportamiento de los usuarios
+// Con la misma configuración (incluida la semilla) el resultado es siempre igual
+type ConfigSimulacion struct {
+	Semilla        uint64
+	Inicio         time.Time
+	Dias           int
+	Libros         int     // títulos del catálogo sintético
+	Usuarios       int     // usuarios sintéticos
+	ProbPrestamo   float64 // probabilidad diaria de que un usuario pida un libro
+	ProbDevolucion float64 // probabilidad diaria de devolver un préstamo no vencido
+	ProbReserva    float64 // probabilidad de reservar un libro que no está disponible
+	ProbRenovacion float64 // probabilidad de renovar un préstamo el día que vence
+}
+
+// ConfigSimulacionPorDefecto simula un cuatrimestre de una biblioteca pequeña
+func ConfigSimulacionPorDefecto() ConfigSimulacion {
+	return ConfigSimulacion{
+		Semilla:        1,
+		Inicio:         time.Date(2026, time.March, 2, 0, 0, 0, 0, time.Local),
+		Dias:           120,
+		Libros:         40,
+		Usuarios:       25,
+		ProbPrestamo:   0.15,
+		ProbDevolucion: 0.12,
+		ProbReserva:    0.5,
+		ProbRenovacion: 0.4,
+	}
+}
+
+// ReporteSimulacion resume la actividad del período simulado
+type ReporteSimulacion struct {
+	Semilla               uint64
+	Desde                 time.Time
+	Hasta                 time.Time
+	DiasAtencion          int
+	Prestamos             int
+	Devoluciones          int
+	DevolucionesConAtraso int
+	Renovaciones          int
+	Reservas              int
+	ReservasVencidas      int
+	Rechazos              int // pedidos que no se pudieron atender ni reservar
+	MultasCobradas        float64
+	PrestamosActivos      int
+	PrestamosVencidos     int
+	MasPrestados          []ConteoLibro
+}
+
+// simulador guarda el estado de una simulación en curso
+type simulador struct {
+	config   ConfigSimulacion
+	b        *Biblioteca
+	reloj    *RelojVirtual
+	rnd      *rand.Rand
+	libros   []LibroID
+	usuarios []UsuarioID
+	reservas []Reserva // reservas creadas que todavía pueden retirarse
+	reporte  ReporteSimulacion
+}
+
+// Simular crea una biblioteca sintética y la hace funcionar día por día con
+// un reloj virtual. Retorna la biblioteca resultante y el reporte del período
+func Simular(config ConfigSimulacion) (*Biblioteca, *ReporteSimulacion, error) {
+	if config.Dias <= 0 || config.Libros <= 0 || config.Usuarios <= 0 {
+		return nil, nil, errDatosInvalidos("error.simulacion_sin_datos")
+	}
+
+	s := &simulador{
+		config: config,
+		b:      NuevaBiblioteca("Biblioteca simulada", "Calle Virtual 1"),
+		reloj:  NuevoRelojVirtual(inicioDelDia(config.Inicio)),
+		rnd:    rand.New(rand.NewPCG(config.Semilla, config.Semilla^0x9e3779b97f4a7c15)),
+	}
+	s.b.UsarReloj(s.reloj)
+	s.reporte.Semilla = config.Semilla
+
+	if err := s.poblar(); err != nil {
+		return nil, nil, err
+	}
+
+	for d := 0; d < config.Dias; d++ {
+		dia := inicioDelDia(config.Inicio).AddDate(0, 0, d)
+		if !s.b.Calendario.AbreEl(dia) {
+			continue
+		}
+		// la actividad del día ocurre una hora después de abrir
+		abre, _ := minutosDelDia(s.b.Calendario.Semana[dia.Weekday()].Abre)
+		s.reloj.Fijar(dia.Add(time.Duration(abre+60) * time.Minute))
+		if s.reporte.DiasAtencion == 0 {
+			s.reporte.Desde = dia
+		}
+		s.reporte.DiasAtencion++
+		s.reporte.Hasta = dia
+
+		if err := s.simularDia(); err != nil {
+			return nil, nil, fmt.Errorf("Día %s: %w", dia.Format("2006-01-02"), err)
+		}
+	}
+
+	s.cerrarReporte()
+	return s.b, &s.reporte, nil
+}
+
+// poblar crea el catálogo y los usuarios sintéticos
+func (s *simulador) poblar() error {
+	for i := 1; i <= s.config.Libros; i++ {
+		libro, err := s.b.AgregarLibro(fmt.Sprintf("Título sintético %03d", i),
+			fmt.Sprintf("Autor %02d", 1+s.rnd.IntN(max(1, s.config.Libros/3))), "", 80+s.rnd.IntN(720))
+		if err != nil {
+			return err
+		}
+		for c := s.rnd.IntN(3); c > 0; c-- {
+			if _, err := s.b.AgregarEjemplar(libro.ID, ""); err != nil {
+				return err
+			}
+		}
+		s.libros = append(s.libros, libro.ID)
+	}
+
+	categorias := []CategoriaUsuario{CategoriaEstudiante, CategoriaEstudiante, CategoriaDocente, CategoriaPublico}
+	for i := 1; i <= s.config.Usuarios; i++ {
+		categoria := categorias[s.rnd.IntN(len(categorias))]
+		usuario, err := s.b.RegistrarUsuario(fmt.Sprintf("Usuario %03d", i),
+			fmt.Sprintf("usuario%03d@simulacion.test", i), "", categoria)
+		if err != nil {
+			return err
+		}
+		s.usuarios = append(s.usuarios, usuario.ID)
+	}
+	return nil
+}
+
+// simularDia procesa vencimientos, devoluciones, retiros de reservas y pedidos
+func (s *simulador) simularDia() error {
+	ahora := s.reloj.Ahora()
+
+	vencidas, err := s.b.ProcesarReservasVencidas(ahora)
+	if err != nil {
+		return err
+	}
+	s.reporte.ReservasVencidas += len(vencidas)
+	if _, err := s.b.RevisarSuspensiones(ahora); err != nil {
+		return err
+	}
+
+	// devoluciones y renovaciones
+	for _, prestamo := range s.b.ListarPrestamos() {
+		if prestamo.Devuelto {
+			continue
+		}
+		venceHoy := inicioDelDia(prestamo.FechaDevolucion).Equal(inicioDelDia(ahora))
+		if venceHoy && s.rnd.Float64() < s.config.ProbRenovacion {
+			if _, err := s.b.RenovarPrestamo(prestamo.ID); err == nil {
+				s.reporte.Renovaciones++
+				continue
+			}
+		}
+
+		prob := s.config.ProbDevolucion
+		if prestamo.EstaVencido(ahora) {
+			prob = 0.5 // quien se atrasó devuelve pronto
+		}
+		if s.rnd.Float64() >= prob {
+			continue
+		}
+		devuelto, err := s.b.DevolverEjemplar(prestamo.EjemplarID)
+		if err != nil {
+			return err
+		}
+		s.reporte.Devoluciones++
+		// la multa se cobra en el momento, así no suspende al usuario
+		if devuelto.Multa > 0 {
+			if _, err := s.b.PagarMulta(devuelto.ID); err != nil {
+				return err
+			}
+			s.reporte.DevolucionesConAtraso++
+			s.reporte.MultasCobradas += devuelto.Multa
+		}
+	}
+
+	// retiro de libros apartados
+	pendientes := s.reservas[:0]
+	for _, reserva := range s.reservas {
+		actual := s.b.BuscarReserva(reserva.ID)
+		if actual == nil || !actual.EstaActiva() {
+			continue
+		}
+		if actual.Estado == ReservaLista && s.rnd.Float64() < 0.7 {
+			if _, err := s.b.PrestarLibro(actual.LibroID, actual.UsuarioID); err == nil {
+				s.reporte.Prestamos++
+				continue
+			}
+		}
+		pendientes = append(pendientes, reserva)
+	}
+	s.reservas = pendientes
+
+	// nuevos pedidos
+	for _, usuarioID := range s.usuarios {
+		if s.rnd.Float64() >= s.config.ProbPrestamo {
+			continue
+		}
+		libroID := s.libros[s.rnd.IntN(len(s.libros))]
+		if _, err := s.b.PrestarLibro(libroID, usuarioID); err == nil {
+			s.reporte.Prestamos++
+			continue
+		}
+		if s.rnd.Float64() < s.config.ProbReserva {
+			if reserva, err := s.b.ReservarLibro(libroID, usuarioID); err == nil {
+				s.reservas = append(s.reservas, *reserva)
+				s.reporte.Reservas++
+				continue
+			}
+		}
+		s.reporte.Rechazos++
+	}
+	return nil
+}
+
+// cerrarReporte calcula el estado al final del último día simulado
+func (s *simulador) cerrarReporte() {
+	s.reloj.Fijar(inicioDelDia(s.config.Inicio).AddDate(0, 0, s.config.Dias))
+
+	est := s.b.ObtenerEstadisticas()
+	s.reporte.PrestamosActivos = est.PrestamosActivos
+	s.reporte.PrestamosVencidos = est.PrestamosVencidos
+	s.reporte.MasPrestados = est.MasPrestados
+}
+
+// Texto formatea el reporte para mostrarlo en la terminal
+func (r ReporteSimulacion) Texto() string {
+	return r.TextoEn(IdiomaPorDefecto)
+}
+
+// TextoEn es Texto en el idioma indicado
+func (r ReporteSimulacion) TextoEn(idioma Idioma) string {
+	var sb strings.Builder
+	linea := func(mensaje string) {
+		sb.WriteString(mensaje)
+		sb.WriteByte('\n')
+	}
+	linea(idioma.Texto("simulacion.periodo", idioma.Fecha(r.Desde), idioma.Fecha(r.Hasta), r.DiasAtencion, r.Semilla))
+	linea(idioma.Texto("simulacion.prestamos", r.Prestamos))
+	linea(idioma.Texto("simulacion.devoluciones", r.Devoluciones, r.DevolucionesConAtraso))
+	linea(idioma.Texto("simulacion.renovaciones", r.Renovaciones))
+	linea(idioma.Texto("simulacion.reservas", r.Reservas, r.ReservasVencidas))
+	linea(idioma.Texto("simulacion.rechazos", r.Rechazos))
+	linea(idioma.Texto("simulacion.multas", idioma.Decimal(r.MultasCobradas, 2)))
+	linea(idioma.Texto("simulacion.cierre", r.PrestamosActivos, r.PrestamosVencidos))
+	if len(r.MasPrestados) > 0 {
+		linea(idioma.Texto("simulacion.mas_prestados"))
+		for _, c := range r.MasPrestados {
+			fmt.Fprintf(&sb, "   [%d] %s: %d\n", c.LibroID, c.Titulo, c.Prestamos)
+		}
+	}
+	return sb.String()
+}
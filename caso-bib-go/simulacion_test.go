@@ -0,0 +1,47 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSimulacionConLaMismaSemillaEsReproducible(t *testing.T) {
+	config := ConfigSimulacionPorDefecto()
+	config.Semilla = 42
+
+	b, reporte, err := Simular(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reporte.Prestamos == 0 || reporte.Devoluciones == 0 {
+		t.Fatalf("La simulación no tuvo actividad: %+v", reporte)
+	}
+	estado := estadoJSON(t, b)
+
+	// varias corridas, para que un orden de mapa no quede oculto por azar
+	for i := 0; i < 3; i++ {
+		otra, otroReporte, err := Simular(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(reporte, otroReporte) {
+			t.Fatalf("La misma semilla dio otro reporte:\n%+v\n%+v", reporte, otroReporte)
+		}
+		if reporte.Texto() != otroReporte.Texto() {
+			t.Fatalf("La misma semilla dio otro texto:\n%s\n%s", reporte.Texto(), otroReporte.Texto())
+		}
+		if estadoJSON(t, otra) != estado {
+			t.Fatal("La misma semilla dejó otra biblioteca")
+		}
+	}
+
+	config.Semilla = 43
+	_, distinto, err := Simular(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	distinto.Semilla = reporte.Semilla
+	if reflect.DeepEqual(reporte, distinto) {
+		t.Error("Otra semilla debería dar otra actividad")
+	}
+}
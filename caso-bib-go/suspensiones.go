@@ -0,0 +1,155 @@
+package main
+
+import "time"
+
+// ==========================================
+// SUSPENSIÓN AUTOMÁTICA POR MULTAS Y ATRASOS
+// ==========================================
+
+// MotivoSuspension indica qué regla de la política suspendió al usuario
+type MotivoSuspension string
+
+const (
+	SuspensionMultas MotivoSuspension = "multas_impagas" // deuda mayor que DeudaMaxima
+	SuspensionAtraso MotivoSuspension = "atraso"         // un préstamo atrasado más de DiasAtrasoSuspension
+)
+
+// Suspension registra por qué se bloquearon los préstamos de un usuario
+// Se levanta sola cuando el usuario paga o devuelve lo que la causó
+// Deuda y DiasAtraso son los del momento en que se suspendió: no se
+// actualizan mientras siga el mismo motivo
+type Suspension struct {
+	Motivo     MotivoSuspension
+	Desde      time.Time
+	Deuda      float64    // multas impagas al suspenderlo
+	PrestamoID PrestamoID // el préstamo más atrasado, si el motivo es el atraso
+	DiasAtraso int
+}
+
+// evaluarSuspension decide si el usuario debe estar suspendido a la fecha
+// El atraso tiene prioridad porque se resuelve devolviendo, no pagando
+// Retorna nil si no corresponde suspenderlo; Desde queda sin completar
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) evaluarSuspension(usuarioID UsuarioID, ahora time.Time) *Suspension {
+	politica := b.politicaDe(usuarioID)
+	deuda := 0.0
+	var masAtrasado PrestamoID
+	diasAtraso := 0
+	for _, pos := range b.indices.prestamosUsuario[usuarioID] {
+		prestamo := b.Prestamos[pos]
+		if prestamo.Devuelto {
+			if !prestamo.MultaPagada {
+				deuda += prestamo.Multa
+			}
+			continue
+		}
+		if dias := b.diasAtraso(prestamo, ahora); dias > diasAtraso {
+			masAtrasado, diasAtraso = prestamo.ID, dias
+		}
+	}
+
+	if politica.DiasAtrasoSuspension > 0 && diasAtraso > politica.DiasAtrasoSuspension {
+		return &Suspension{Motivo: SuspensionAtraso, Deuda: deuda, PrestamoID: masAtrasado, DiasAtraso: diasAtraso}
+	}
+	if politica.DeudaMaxima > 0 && deuda > politica.DeudaMaxima {
+		return &Suspension{Motivo: SuspensionMultas, Deuda: deuda}
+	}
+	return nil
+}
+
+// actualizarSuspension reevalúa la suspensión del usuario y retorna si cambió
+// Solo cambia si cambia el motivo o el préstamo que la causa: los días de
+// atraso crecen solos y no justifican un evento por día
+// Si seguía suspendido conserva la fecha en que empezó la suspensión
+// Quien la llama debe tener tomado b.mu y registrar el cambio en el journal
+func (b *Biblioteca) actualizarSuspension(usuario *Usuario, ahora time.Time) bool {
+	nueva := b.evaluarSuspension(usuario.ID, ahora)
+	anterior := usuario.Suspension
+	switch {
+	case nueva == nil && anterior == nil:
+		return false
+	case nueva != nil && anterior != nil:
+		if nueva.Motivo == anterior.Motivo && nueva.PrestamoID == anterior.PrestamoID {
+			return false
+		}
+		nueva.Desde = anterior.Desde
+	case nueva != nil:
+		nueva.Desde = ahora
+	}
+	usuario.Suspension = nueva
+	return true
+}
+
+// revisarSuspension es actualizarSuspension registrando el cambio, si lo hubo
+// Si el journal falla la suspensión queda como estaba
+// Quien la llama debe tener tomado b.mu
+func (b *Biblioteca) revisarSuspension(usuario *Usuario, ahora time.Time) error {
+	antes := usuario.Suspension
+	if !b.actualizarSuspension(usuario, ahora) {
+		return nil
+	}
+	if err := b.escribirEvento(Evento{Tipo: EventoUsuarioActualizado, Usuario: usuario}); err != nil {
+		usuario.Suspension = antes
+		return errJournal(err, "error.suspension_no_registrada", usuario.Nombre)
+	}
+	return nil
+}
+
+// RevisarSuspensiones reevalúa a todos los usuarios a la fecha indicada
+// Retorna los usuarios cuya suspensión cambió (suspendidos o rehabilitados)
+// Los préstamos, devoluciones y pagos ya revisan al usuario involucrado; esto
+// sirve para detectar los atrasos de quien no vuelve a la biblioteca
+func (b *Biblioteca) RevisarSuspensiones(ahora time.Time) ([]Usuario, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	cambiados := make([]Usuario, 0)
+	for i := range b.Usuarios {
+		usuario := &b.Usuarios[i]
+		antes := usuario.Suspension
+		if err := b.revisarSuspension(usuario, ahora); err != nil {
+			return cambiados, err
+		}
+		if usuario.Suspension != antes {
+			cambiados = append(cambiados, *usuario)
+		}
+	}
+	return cambiados, nil
+}
+
+// PagarMulta registra el pago de la multa de un préstamo devuelto y levanta
+// la suspensión del usuario si la deuda restante ya no la justifica
+func (b *Biblioteca) PagarMulta(prestamoID PrestamoID) (*Prestamo, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	prestamo := b.buscarPrestamo(prestamoID)
+	if prestamo == nil {
+		return nil, errPrestamoNoEncontrado(prestamoID)
+	}
+	if !prestamo.Devuelto || prestamo.Multa <= 0 || prestamo.MultaPagada {
+		return nil, &ErrorBiblioteca{Tipo: ErrMultaNoPendiente, PrestamoID: prestamo.ID, UsuarioID: prestamo.UsuarioID,
+			mensaje: txt("error.multa_no_pendiente", prestamo.ID)}
+	}
+	prestamo.MultaPagada = true
+
+	evento := Evento{Tipo: EventoMultaPagada, Prestamo: prestamo}
+	usuario := b.buscarUsuario(prestamo.UsuarioID)
+	var suspensionAntes *Suspension
+	if usuario != nil {
+		suspensionAntes = usuario.Suspension
+		if b.actualizarSuspension(usuario, b.ahora()) {
+			evento.Usuario = usuario
+		}
+	}
+
+	if err := b.escribirEvento(evento); err != nil {
+		prestamo.MultaPagada = false
+		if usuario != nil {
+			usuario.Suspension = suspensionAntes
+		}
+		return nil, errJournal(err, "error.pago_no_registrado")
+	}
+	pagado := *prestamo
+	return &pagado, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSuspensionPorAtrasoSoloAfectaAlUsuario(t *testing.T) {
+	reloj := NuevoRelojVirtual(time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 200)
+	otro, _ := b.AgregarLibro("Rayuela", "Cortázar", "", 600)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	beto, _ := b.RegistrarUsuario("Beto", "beto@test", "", CategoriaEstudiante)
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// el plazo de estudiante es 14 días y la suspensión llega a los 30 de atraso
+	reloj.Avanzar(70 * 24 * time.Hour)
+	cambiados, err := b.RevisarSuspensiones(reloj.Ahora())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cambiados) != 1 || cambiados[0].ID != ana.ID {
+		t.Fatalf("Solo Ana debería quedar suspendida: %+v", cambiados)
+	}
+	if _, err := b.PrestarLibro(otro.ID, ana.ID); !errors.Is(err, ErrUsuarioSuspendido) {
+		t.Errorf("Se esperaba ErrUsuarioSuspendido, se obtuvo %v", err)
+	}
+	if _, err := b.PrestarLibro(otro.ID, beto.ID); err != nil {
+		t.Errorf("Beto no debería estar suspendido: %v", err)
+	}
+
+	// al devolver se levanta el atraso, pero queda la multa impaga (tope 10 > 5)
+	prestamo, err := b.DevolverLibro(libro.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := b.BuscarUsuario(ana.ID).Suspension; s == nil || s.Motivo != SuspensionMultas {
+		t.Fatalf("Se esperaba la suspensión por multas, se obtuvo %+v", s)
+	}
+	if _, err := b.PagarMulta(prestamo.ID); err != nil {
+		t.Fatal(err)
+	}
+	if s := b.BuscarUsuario(ana.ID).Suspension; s != nil {
+		t.Errorf("Pagar la multa debería levantar la suspensión: %+v", s)
+	}
+}
+
+func TestSuspensionNoCambiaCadaDiaDeAtraso(t *testing.T) {
+	reloj := NuevoRelojVirtual(time.Date(2026, 3, 2, 10, 0, 0, 0, time.Local))
+	b := NuevaBiblioteca("Central", "")
+	b.UsarReloj(reloj)
+	libro, _ := b.AgregarLibro("Ficciones", "Borges", "", 200)
+	ana, _ := b.RegistrarUsuario("Ana", "ana@test", "", CategoriaEstudiante)
+	if _, err := b.PrestarLibro(libro.ID, ana.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	reloj.Avanzar(70 * 24 * time.Hour)
+	if cambiados, err := b.RevisarSuspensiones(reloj.Ahora()); err != nil || len(cambiados) != 1 {
+		t.Fatalf("Ana debería quedar suspendida: %+v %v", cambiados, err)
+	}
+	suspension := *b.BuscarUsuario(ana.ID).Suspension
+
+	// el atraso sigue creciendo, pero la suspensión es la misma
+	for dia := 0; dia < 5; dia++ {
+		reloj.Avanzar(24 * time.Hour)
+		if cambiados, err := b.RevisarSuspensiones(reloj.Ahora()); err != nil || len(cambiados) != 0 {
+			t.Fatalf("Día %d: la suspensión no debería cambiar: %+v %v", dia, cambiados, err)
+		}
+	}
+	if actual := *b.BuscarUsuario(ana.ID).Suspension; actual != suspension {
+		t.Errorf("La suspensión cambió de %+v a %+v", suspension, actual)
+	}
+}